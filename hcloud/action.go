@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"time"
 
@@ -103,7 +104,8 @@ func (a *Action) Error() error {
 
 // ActionClient is a client for the actions API.
 type ActionClient struct {
-	action *ResourceActionClient[noopResource]
+	action  *ResourceActionClient[noopResource]
+	watcher *ActionWatcher
 }
 
 // GetByID retrieves an action by its ID. If the action does not exist, nil is returned.
@@ -159,6 +161,20 @@ func (c *ActionClient) AllWithOpts(ctx context.Context, opts ActionListOpts) ([]
 	return c.action.All(ctx, opts)
 }
 
+// Iter returns an iterator over all actions for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+//
+// It is required to set [ActionListOpts.ID]. Any other fields set in the opts are ignored.
+func (c *ActionClient) Iter(ctx context.Context, opts ActionListOpts) iter.Seq2[*Action, error] {
+	if opts.ListOpts.PerPage == 0 {
+		// Do not send unused per page param
+		opts.ListOpts.PerPage = -1
+	}
+	return c.action.Iter(ctx, opts)
+}
+
 // ResourceActionClient is a client for the actions API exposed by the resource.
 type ResourceActionClient[R actionSupporter] struct {
 	resource string
@@ -219,6 +235,20 @@ func (c *ResourceActionClient[R]) All(ctx context.Context, opts ActionListOpts)
 	})
 }
 
+// Iter returns an iterator over all actions for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *ResourceActionClient[R]) Iter(ctx context.Context, opts ActionListOpts) iter.Seq2[*Action, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*Action, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 type actionSupporter interface {
 	pathID() (string, error)
 }
@@ -259,3 +289,14 @@ func (c *ResourceActionClient[R]) AllFor(ctx context.Context, resource R, opts A
 		return c.ListFor(ctx, resource, opts)
 	})
 }
+
+// IterFor returns an iterator over all actions for the given Resource.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *ResourceActionClient[R]) IterFor(ctx context.Context, resource R, opts ActionListOpts) iter.Seq2[*Action, error] {
+	return seqPages(func(page int) ([]*Action, *Response, error) {
+		opts.Page = page
+		return c.ListFor(ctx, resource, opts)
+	})
+}
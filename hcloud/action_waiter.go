@@ -6,6 +6,9 @@ import (
 	"maps"
 	"slices"
 	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ActionWaiter interface {
@@ -20,10 +23,27 @@ var _ ActionWaiter = (*ActionClient)(nil)
 // either [ActionStatusSuccess] or [ActionStatusError].
 //
 // The handleUpdate callback is called every time an action is updated.
+//
+// Every call runs its own polling loop, see [ActionClient.Watcher] to share a single
+// polling loop between concurrent callers.
 func (c *ActionClient) WaitForFunc(ctx context.Context, handleUpdate func(update *Action) error, actions ...*Action) error {
 	// Filter out nil actions
 	actions = slices.DeleteFunc(actions, func(a *Action) bool { return a == nil })
 
+	ctx, span := c.action.client.telemetry.tracer.Start(ctx, "WaitForFunc",
+		trace.WithAttributes(attrActionCount.Int(len(actions))),
+	)
+	defer span.End()
+
+	err := c.waitForFunc(ctx, handleUpdate, actions)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func (c *ActionClient) waitForFunc(ctx context.Context, handleUpdate func(update *Action) error, actions []*Action) error {
 	running := make(map[int64]struct{}, len(actions))
 	for _, action := range actions {
 		if action.Status == ActionStatusRunning {
@@ -0,0 +1,375 @@
+package hcloud
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ActionWatcher multiplexes the actions watched by multiple subscribers into a single
+// polling loop. All the running actions are polled together, using batched requests to
+// the actions API, at the interval defined by [WithPollOpts].
+//
+// The polling loop is started when the first subscriber arrives, and stopped when
+// the last subscriber leaves. Subscribers may join while the polling loop is running,
+// their actions are added to the next poll.
+//
+// An ActionWatcher is shared by all users of a [Client], see [ActionClient.Watcher].
+// The [ActionClient.WaitFor] and [ActionClient.WaitForFunc] functions keep their
+// dedicated polling loop, callers opt in to the shared polling loop by using the
+// ActionWatcher instead.
+type ActionWatcher struct {
+	client *Client
+
+	mu     sync.Mutex
+	subs   map[*actionSubscription]struct{}
+	cancel context.CancelFunc
+	// joined is signaled when a subscriber joins a running polling loop.
+	joined chan struct{}
+}
+
+var _ ActionWaiter = (*ActionWatcher)(nil)
+
+func newActionWatcher(client *Client) *ActionWatcher {
+	return &ActionWatcher{
+		client: client,
+		subs:   make(map[*actionSubscription]struct{}),
+		joined: make(chan struct{}, 1),
+	}
+}
+
+// Watcher returns the [ActionWatcher] shared by all users of the [Client].
+func (c *ActionClient) Watcher() *ActionWatcher {
+	return c.watcher
+}
+
+// Watch returns an iterator over the updates of the given actions. An update is
+// yielded every time an action is polled, until all actions are completed. An action
+// is considered as complete when its status is either [ActionStatusSuccess] or
+// [ActionStatusError].
+//
+// Actions that are already completed are yielded once without polling the API.
+//
+// If the context is done, or the API could not be queried, the error is yielded and
+// the iteration stops. Stopping the iteration early unsubscribes from the watcher.
+func (w *ActionWatcher) Watch(ctx context.Context, actions ...*Action) iter.Seq2[*Action, error] {
+	return func(yield func(*Action, error) bool) {
+		sub := w.subscribe(actions)
+		defer w.unsubscribe(sub)
+
+		for {
+			updates, done, err := sub.next()
+			for _, update := range updates {
+				if !yield(update, nil) {
+					return
+				}
+			}
+			if done {
+				if err != nil {
+					yield(nil, err)
+				}
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				remaining := w.unsubscribe(sub)
+				yield(nil, fmt.Errorf("%w: remaining running actions: %v", ctx.Err(), remaining))
+				return
+			case <-sub.notify:
+			}
+		}
+	}
+}
+
+// WaitForFunc waits until all actions are completed. An action is considered as
+// complete when its status is either [ActionStatusSuccess] or [ActionStatusError].
+//
+// The handleUpdate callback is called every time an action is updated.
+//
+// See [ActionClient.WaitForFunc] for the equivalent using a dedicated polling loop.
+func (w *ActionWatcher) WaitForFunc(ctx context.Context, handleUpdate func(update *Action) error, actions ...*Action) error {
+	for update, err := range w.Watch(ctx, actions...) {
+		if err != nil {
+			return err
+		}
+		if handleUpdate != nil {
+			if err := handleUpdate(update); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WaitFor waits until all actions succeed. An action is considered as succeeded when
+// its status is either [ActionStatusSuccess].
+//
+// If a single action fails, the function will stop waiting and the error set in the
+// action will be returned as an [ActionError].
+//
+// See [ActionClient.WaitFor] for the equivalent using a dedicated polling loop.
+func (w *ActionWatcher) WaitFor(ctx context.Context, actions ...*Action) error {
+	return w.WaitForFunc(
+		ctx,
+		func(update *Action) error {
+			if update.Status == ActionStatusError {
+				return update.Error()
+			}
+			return nil
+		},
+		actions...,
+	)
+}
+
+// subscribe registers a new subscription for the given actions, and starts the
+// polling loop if needed.
+func (w *ActionWatcher) subscribe(actions []*Action) *actionSubscription {
+	sub := &actionSubscription{
+		pending: make(map[int64]struct{}, len(actions)),
+		notify:  make(chan struct{}, 1),
+	}
+
+	for _, action := range actions {
+		if action == nil {
+			continue
+		}
+		if action.Status == ActionStatusRunning {
+			sub.pending[action.ID] = struct{}{}
+		} else {
+			// We filter out already completed actions from the API polling loop; while
+			// this isn't a real update, the subscriber should be notified about the
+			// new state.
+			sub.push(action)
+		}
+	}
+
+	if len(sub.pending) == 0 {
+		sub.finish(nil)
+		return sub
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subs[sub] = struct{}{}
+	if w.cancel == nil {
+		var ctx context.Context
+		ctx, w.cancel = context.WithCancel(context.Background())
+		go w.run(ctx)
+	} else {
+		select {
+		case w.joined <- struct{}{}:
+		default:
+		}
+	}
+
+	return sub
+}
+
+// unsubscribe removes the subscription from the watcher, and returns the IDs of its
+// actions that are still running. The polling loop is stopped when no subscriptions
+// are left.
+func (w *ActionWatcher) unsubscribe(sub *actionSubscription) []int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	remaining := slices.Sorted(maps.Keys(sub.pending))
+	w.remove(sub)
+	return remaining
+}
+
+// remove must be called with the lock held.
+func (w *ActionWatcher) remove(sub *actionSubscription) {
+	delete(w.subs, sub)
+	if len(w.subs) == 0 && w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+}
+
+// run polls the running actions of all subscriptions until the context is canceled.
+//
+// The backoff is reset when a subscriber joins, and the current wait is shortened to
+// the initial interval, so the actions of late subscribers are polled at the initial
+// interval instead of the interval reached so far. The current wait is never
+// restarted, so subscribers joining continuously do not delay the polls.
+func (w *ActionWatcher) run(ctx context.Context) {
+	retries := 0
+	for {
+		start := time.Now()
+		deadline := start.Add(w.client.pollBackoffFunc(retries))
+		timer := time.NewTimer(time.Until(deadline))
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-w.joined:
+				retries = 0
+				if initial := start.Add(w.client.pollBackoffFunc(0)); initial.Before(deadline) {
+					deadline = initial
+					timer.Reset(time.Until(deadline))
+				}
+			case <-timer.C:
+				break wait
+			}
+		}
+		retries++
+
+		w.mu.Lock()
+		if ctx.Err() != nil {
+			w.mu.Unlock()
+			return
+		}
+		running := make(map[int64]struct{})
+		for sub := range w.subs {
+			maps.Copy(running, sub.pending)
+		}
+		w.mu.Unlock()
+
+		updates, err := w.poll(ctx, running)
+
+		w.mu.Lock()
+		if ctx.Err() != nil {
+			w.mu.Unlock()
+			return
+		}
+		w.dispatch(running, updates, err)
+		w.mu.Unlock()
+	}
+}
+
+// poll fetches the given actions in batches.
+func (w *ActionWatcher) poll(ctx context.Context, running map[int64]struct{}) ([]*Action, error) {
+	updates := make([]*Action, 0, len(running))
+	for runningIDsChunk := range slices.Chunk(slices.Sorted(maps.Keys(running)), 25) {
+		opts := ActionListOpts{
+			Sort: []string{"status", "id"},
+			ID:   runningIDsChunk,
+		}
+
+		updatesChunk, err := w.client.Action.AllWithOpts(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		updates = append(updates, updatesChunk...)
+	}
+	return updates, nil
+}
+
+// dispatch delivers the result of a poll to the subscriptions. It must be called with
+// the lock held.
+func (w *ActionWatcher) dispatch(running map[int64]struct{}, updates []*Action, err error) {
+	notFound := maps.Clone(running)
+	for _, update := range updates {
+		delete(notFound, update.ID)
+	}
+
+	for sub := range w.subs {
+		if !sub.polled(running) {
+			// Subscribed after the poll started, the actions are part of the next poll.
+			continue
+		}
+
+		if err != nil {
+			sub.finish(err)
+			w.remove(sub)
+			continue
+		}
+
+		var subNotFound []int64
+		for id := range sub.pending {
+			if _, ok := notFound[id]; ok {
+				subNotFound = append(subNotFound, id)
+			}
+		}
+		if len(subNotFound) > 0 {
+			// Some actions may not exist in the API, also fail early to prevent an
+			// infinite loop.
+			slices.Sort(subNotFound)
+			sub.finish(fmt.Errorf("actions not found: %v", subNotFound))
+			w.remove(sub)
+			continue
+		}
+
+		for _, update := range updates {
+			if _, ok := sub.pending[update.ID]; !ok {
+				continue
+			}
+			if update.Status != ActionStatusRunning {
+				delete(sub.pending, update.ID)
+			}
+			sub.push(update)
+		}
+
+		if len(sub.pending) == 0 {
+			sub.finish(nil)
+			w.remove(sub)
+		}
+	}
+}
+
+// actionSubscription holds the state of a single subscriber of an [ActionWatcher].
+//
+// The pending actions are guarded by the lock of the [ActionWatcher], while the
+// queued updates are guarded by the lock of the subscription, so that slow subscribers
+// never block the polling loop.
+type actionSubscription struct {
+	pending map[int64]struct{}
+
+	mu     sync.Mutex
+	queue  []*Action
+	done   bool
+	err    error
+	notify chan struct{}
+}
+
+// polled reports whether all pending actions of the subscription were part of a poll.
+func (s *actionSubscription) polled(running map[int64]struct{}) bool {
+	for id := range s.pending {
+		if _, ok := running[id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *actionSubscription) push(update *Action) {
+	s.mu.Lock()
+	s.queue = append(s.queue, update)
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *actionSubscription) finish(err error) {
+	s.mu.Lock()
+	s.done = true
+	s.err = err
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *actionSubscription) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the queued updates, and whether the subscription is finished.
+func (s *actionSubscription) next() ([]*Action, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updates := s.queue
+	s.queue = nil
+	return updates, s.done, s.err
+}
@@ -0,0 +1,274 @@
+package hcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// actionWatcherTestServer serves the actions API, every action is running until it
+// was polled the configured number of times.
+type actionWatcherTestServer struct {
+	mu       sync.Mutex
+	polls    map[int64]int
+	requests [][]int64
+}
+
+func (s *actionWatcherTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []int64
+	resp := schema.ActionListResponse{Actions: []schema.Action{}}
+	for _, value := range r.URL.Query()["id"] {
+		id, _ := strconv.ParseInt(value, 10, 64)
+		ids = append(ids, id)
+
+		remaining, ok := s.polls[id]
+		if !ok {
+			continue
+		}
+		action := schema.Action{ID: id, Status: string(ActionStatusRunning)}
+		if remaining <= 1 {
+			action.Status = string(ActionStatusSuccess)
+			action.Progress = 100
+		} else {
+			s.polls[id] = remaining - 1
+		}
+		resp.Actions = append(resp.Actions, action)
+	}
+	s.requests = append(s.requests, ids)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newActionWatcherTestEnv(t *testing.T, polls map[int64]int, backoff BackoffFunc) (*actionWatcherTestServer, *Client) {
+	t.Helper()
+
+	env := newTestEnv()
+	t.Cleanup(env.Teardown)
+
+	server := &actionWatcherTestServer{polls: polls}
+	env.Mux.Handle("/actions", server)
+
+	client := NewClient(
+		WithEndpoint(env.Server.URL),
+		WithToken("token"),
+		WithPollOpts(PollOpts{BackoffFunc: backoff}),
+	)
+	return server, client
+}
+
+func TestActionWatcherBatchesSubscribers(t *testing.T) {
+	ids := make([]int64, 0, 30)
+	polls := make(map[int64]int)
+	for id := range int64(30) {
+		ids = append(ids, id+1)
+		polls[id+1] = 2
+	}
+	server, client := newActionWatcherTestEnv(t, polls, func(retries int) time.Duration {
+		// Give all subscribers time to join before the first poll.
+		if retries == 0 {
+			return 100 * time.Millisecond
+		}
+		return time.Millisecond
+	})
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, client.Action.Watcher().WaitFor(context.Background(), &Action{ID: id, Status: ActionStatusRunning}))
+		}()
+	}
+	wg.Wait()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	// Two polls of 30 actions, in chunks of 25.
+	require.Len(t, server.requests, 4)
+	assert.Len(t, server.requests[0], 25)
+	assert.Len(t, server.requests[1], 5)
+	assert.Len(t, server.requests[2], 25)
+	assert.Len(t, server.requests[3], 5)
+
+	client.Action.Watcher().mu.Lock()
+	defer client.Action.Watcher().mu.Unlock()
+	assert.Empty(t, client.Action.Watcher().subs)
+	assert.Nil(t, client.Action.Watcher().cancel)
+}
+
+func TestActionWatcherWatch(t *testing.T) {
+	_, client := newActionWatcherTestEnv(t, map[int64]int{1: 3}, ConstantBackoff(time.Millisecond))
+
+	statuses := []ActionStatus{}
+	for update, err := range client.Action.Watcher().Watch(context.Background(),
+		&Action{ID: 1, Status: ActionStatusRunning},
+		&Action{ID: 2, Status: ActionStatusSuccess},
+		nil,
+	) {
+		require.NoError(t, err)
+		statuses = append(statuses, update.Status)
+	}
+	assert.Equal(t, []ActionStatus{ActionStatusSuccess, ActionStatusRunning, ActionStatusRunning, ActionStatusSuccess}, statuses)
+}
+
+func TestActionWatcherLateSubscriber(t *testing.T) {
+	_, client := newActionWatcherTestEnv(t, map[int64]int{1: 5, 2: 2}, ConstantBackoff(time.Millisecond))
+	watcher := client.Action.Watcher()
+
+	done := make(chan error)
+	for update, err := range watcher.Watch(context.Background(), &Action{ID: 1, Status: ActionStatusRunning}) {
+		require.NoError(t, err)
+		if update.Status == ActionStatusRunning && done != nil {
+			go func() {
+				done <- watcher.WaitFor(context.Background(), &Action{ID: 2, Status: ActionStatusRunning})
+			}()
+			require.NoError(t, <-done)
+			done = nil
+		}
+	}
+	assert.Nil(t, done)
+}
+
+func TestActionWatcherLateSubscriberResetsBackoff(t *testing.T) {
+	server, client := newActionWatcherTestEnv(t, map[int64]int{1: 1000, 2: 1}, func(retries int) time.Duration {
+		if retries == 0 {
+			return time.Millisecond
+		}
+		return time.Hour
+	})
+	watcher := client.Action.Watcher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- watcher.WaitFor(ctx, &Action{ID: 1, Status: ActionStatusRunning})
+	}()
+
+	// Wait for the first poll, the next poll is an hour later.
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.requests) > 0
+	}, time.Second, time.Millisecond)
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	require.NoError(t, watcher.WaitFor(waitCtx, &Action{ID: 2, Status: ActionStatusRunning}))
+
+	cancel()
+	require.ErrorIs(t, <-errCh, context.Canceled)
+}
+
+func TestActionWatcherSteadySubscribers(t *testing.T) {
+	polls := map[int64]int{1: 2}
+	for id := range int64(100) {
+		polls[id+2] = 1000
+	}
+	_, client := newActionWatcherTestEnv(t, polls, ConstantBackoff(20*time.Millisecond))
+	watcher := client.Action.Watcher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	// Subscribers join more often than the poll interval.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := int64(0); ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(2 * time.Millisecond):
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = watcher.WaitFor(ctx, &Action{ID: i%100 + 2, Status: ActionStatusRunning})
+			}()
+		}
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	require.NoError(t, watcher.WaitFor(waitCtx, &Action{ID: 1, Status: ActionStatusRunning}))
+}
+
+func TestActionWatcherCancel(t *testing.T) {
+	_, client := newActionWatcherTestEnv(t, map[int64]int{1: 1000, 2: 5}, ConstantBackoff(time.Millisecond))
+	watcher := client.Action.Watcher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- watcher.WaitFor(ctx, &Action{ID: 1, Status: ActionStatusRunning})
+	}()
+
+	require.NoError(t, watcher.WaitFor(context.Background(), &Action{ID: 2, Status: ActionStatusRunning}))
+
+	cancel()
+	err := <-errCh
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "context canceled: remaining running actions: [1]", err.Error())
+}
+
+func TestActionWatcherBreak(t *testing.T) {
+	_, client := newActionWatcherTestEnv(t, map[int64]int{1: 1000}, ConstantBackoff(time.Millisecond))
+	watcher := client.Action.Watcher()
+
+	for update, err := range watcher.Watch(context.Background(), &Action{ID: 1, Status: ActionStatusRunning}) {
+		require.NoError(t, err)
+		assert.Equal(t, ActionStatusRunning, update.Status)
+		break
+	}
+
+	watcher.mu.Lock()
+	defer watcher.mu.Unlock()
+	assert.Empty(t, watcher.subs)
+	assert.Nil(t, watcher.cancel)
+}
+
+func TestActionWatcherNotFound(t *testing.T) {
+	_, client := newActionWatcherTestEnv(t, map[int64]int{1: 3}, ConstantBackoff(time.Millisecond))
+	watcher := client.Action.Watcher()
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- watcher.WaitFor(context.Background(), &Action{ID: 1, Status: ActionStatusRunning})
+	}()
+
+	err := watcher.WaitFor(context.Background(), &Action{ID: 2, Status: ActionStatusRunning})
+	require.EqualError(t, err, "actions not found: [2]")
+
+	require.NoError(t, <-errCh)
+}
+
+func TestActionWatcherError(t *testing.T) {
+	env := newTestEnv()
+	defer env.Teardown()
+
+	env.Mux.HandleFunc("/actions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(schema.ErrorResponse{Error: schema.Error{Code: string(ErrorCodeInvalidInput), Message: "invalid input"}})
+	})
+
+	err := env.Client.Action.Watcher().WaitFor(context.Background(), &Action{ID: 1, Status: ActionStatusRunning})
+	require.Error(t, err)
+	assert.True(t, IsError(err, ErrorCodeInvalidInput))
+}
@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 	"time"
@@ -179,6 +180,20 @@ func (c *CertificateClient) AllWithOpts(ctx context.Context, opts CertificateLis
 	})
 }
 
+// Iter returns an iterator over all Certificates for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *CertificateClient) Iter(ctx context.Context, opts CertificateListOpts) iter.Seq2[*Certificate, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*Certificate, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // CertificateCreateOpts specifies options for creating a new Certificate.
 type CertificateCreateOpts struct {
 	Name        string
@@ -6,15 +6,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 	"golang.org/x/net/http/httpguts"
 
 	"github.com/hetznercloud/hcloud-go/v2/hcloud/internal/instrumentation"
@@ -88,17 +94,24 @@ func ExponentialBackoffWithOpts(opts ExponentialBackoffOpts) BackoffFunc {
 type Client struct {
 	endpoint                string
 	hetznerEndpoint         string
-	token                   string
-	tokenValid              bool
+	token                   *clientToken
 	retryBackoffFunc        BackoffFunc
 	retryMaxRetries         int
+	retryPolicy             RetryPolicy
+	lockedRetryOpts         LockedRetryOpts
 	pollBackoffFunc         BackoffFunc
 	httpClient              *http.Client
 	applicationName         string
 	applicationVersion      string
 	userAgent               string
 	debugWriter             io.Writer
+	logger                  *slog.Logger
+	loggerOpts              LoggerOpts
 	instrumentationRegistry prometheus.Registerer
+	rateLimiter             *RateLimiter
+	tracerProvider          trace.TracerProvider
+	meterProvider           metric.MeterProvider
+	telemetry               *telemetry
 	handler                 handler
 
 	Action           ActionClient
@@ -128,6 +141,29 @@ type Client struct {
 	Datacenter DatacenterClient
 }
 
+// clientToken holds the token of a [Client]. It is shared with the shallow copies of
+// the [Client], and may be rotated while requests are made.
+type clientToken struct {
+	mu    sync.RWMutex
+	value string
+	valid bool
+}
+
+func (t *clientToken) set(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.value = token
+	t.valid = httpguts.ValidHeaderFieldValue(token)
+}
+
+func (t *clientToken) get() (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.value, t.valid
+}
+
 // A ClientOption is used to configure a Client.
 type ClientOption func(*Client)
 
@@ -151,8 +187,7 @@ func WithHetznerEndpoint(endpoint string) ClientOption {
 // WithToken configures a Client to use the specified token for authentication.
 func WithToken(token string) ClientOption {
 	return func(client *Client) {
-		client.token = token
-		client.tokenValid = httpguts.ValidHeaderFieldValue(token)
+		client.token.set(token)
 	}
 }
 
@@ -221,18 +256,25 @@ func WithBackoffFunc(f BackoffFunc) ClientOption {
 type RetryOpts struct {
 	BackoffFunc BackoffFunc
 	MaxRetries  int
+	// Policy decides which failed requests are retried, defaults to
+	// [DefaultRetryPolicy].
+	Policy RetryPolicy
 }
 
 // WithRetryOpts configures a Client to use the specified options when retrying API
 // requests.
 //
-// If [RetryOpts.BackoffFunc] is nil, the existing backoff function will be preserved.
+// If [RetryOpts.BackoffFunc] or [RetryOpts.Policy] are nil, the existing backoff
+// function or policy will be preserved.
 func WithRetryOpts(opts RetryOpts) ClientOption {
 	return func(client *Client) {
 		if opts.BackoffFunc != nil {
 			client.retryBackoffFunc = opts.BackoffFunc
 		}
 		client.retryMaxRetries = opts.MaxRetries
+		if opts.Policy != nil {
+			client.retryPolicy = opts.Policy
+		}
 	}
 }
 
@@ -248,12 +290,50 @@ func WithApplication(name, version string) ClientOption {
 
 // WithDebugWriter configures a Client to print debug information to the given
 // writer. To, for example, print debug information on stderr, set it to os.Stderr.
+//
+// Deprecated: WithDebugWriter is deprecated, use [WithLogger] instead.
 func WithDebugWriter(debugWriter io.Writer) ClientOption {
 	return func(client *Client) {
 		client.debugWriter = debugWriter
 	}
 }
 
+// WithLogger configures a Client to log every API call to the given logger, using
+// structured attributes. See [LoggerOpts] to configure the log levels, the body
+// logging and the sampling.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(client *Client) {
+		client.logger = logger
+	}
+}
+
+// LoggerOpts defines the options used by the logger configured with [WithLogger].
+type LoggerOpts struct {
+	// Level is the log level of the successful API calls, defaults to
+	// [slog.LevelDebug].
+	Level slog.Leveler
+	// ErrorLevel is the log level of the failed API calls, defaults to
+	// [slog.LevelWarn].
+	ErrorLevel slog.Leveler
+	// Body enables the logging of the request and response bodies. Secrets (e.g.
+	// passwords, TSIG keys, certificate private keys) are redacted from the bodies.
+	Body bool
+	// RedactedFields are the JSON fields redacted from the logged bodies, in addition
+	// to the default ones.
+	RedactedFields []string
+	// SampleEvery only logs one of every n successful API calls. Failed API calls are
+	// always logged. Values lower than 2 log every API call.
+	SampleEvery int
+}
+
+// WithLoggerOpts configures a Client to use the specified options when logging the
+// API calls.
+func WithLoggerOpts(opts LoggerOpts) ClientOption {
+	return func(client *Client) {
+		client.loggerOpts = opts
+	}
+}
+
 // WithHTTPClient configures a Client to perform HTTP requests with httpClient.
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(client *Client) {
@@ -268,12 +348,42 @@ func WithInstrumentation(registry prometheus.Registerer) ClientOption {
 	}
 }
 
+// WithTracerProvider configures a Client to emit a span for every API call, using the
+// given OpenTelemetry [trace.TracerProvider].
+//
+// The spans are named after the operation path of the API call (e.g.
+// /servers/-/actions/poweron), and each attempt of a retried call is covered by a
+// child span. Waiting for actions with [ActionClient.WaitForFunc] is covered by a
+// span including the polling calls.
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(client *Client) {
+		client.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider configures a Client to record metrics about the API calls, using
+// the given OpenTelemetry [metric.MeterProvider].
+func WithMeterProvider(provider metric.MeterProvider) ClientOption {
+	return func(client *Client) {
+		client.meterProvider = provider
+	}
+}
+
+// WithRateLimiter configures a Client to throttle its requests using the given
+// [RateLimiter]. The same [RateLimiter] may be passed to multiple clients using the
+// same token.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(client *Client) {
+		client.rateLimiter = limiter
+	}
+}
+
 // NewClient creates a new client.
 func NewClient(options ...ClientOption) *Client {
 	client := &Client{
 		endpoint:        Endpoint,
 		hetznerEndpoint: HetznerEndpoint,
-		tokenValid:      true,
+		token:           &clientToken{valid: true},
 		httpClient:      &http.Client{},
 
 		retryBackoffFunc: ExponentialBackoffWithOpts(ExponentialBackoffOpts{
@@ -283,6 +393,7 @@ func NewClient(options ...ClientOption) *Client {
 			Jitter:     true,
 		}),
 		retryMaxRetries: 5,
+		retryPolicy:     DefaultRetryPolicy,
 
 		pollBackoffFunc: ConstantBackoff(500 * time.Millisecond),
 	}
@@ -297,10 +408,19 @@ func NewClient(options ...ClientOption) *Client {
 		client.httpClient.Transport = i.InstrumentedRoundTripper(client.httpClient.Transport)
 	}
 
+	tracerProvider, meterProvider := client.tracerProvider, client.meterProvider
+	if tracerProvider == nil {
+		tracerProvider = tracenoop.NewTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = metricnoop.NewMeterProvider()
+	}
+	client.telemetry = newTelemetry(tracerProvider, meterProvider)
+
 	client.handler = assembleHandlerChain(client)
 
 	// Cloud API
-	client.Action = ActionClient{action: &ResourceActionClient[noopResource]{client: client}}
+	client.Action = ActionClient{action: &ResourceActionClient[noopResource]{client: client}, watcher: newActionWatcher(client)}
 	client.Datacenter = DatacenterClient{client: client}
 	client.FloatingIP = FloatingIPClient{client: client, Action: &ResourceActionClient[*FloatingIP]{client: client, resource: "floating_ips"}}
 	client.Image = ImageClient{client: client, Action: &ResourceActionClient[*Image]{client: client, resource: "images"}}
@@ -347,10 +467,10 @@ func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Re
 	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Accept", "application/json")
 
-	if !c.tokenValid {
+	if token, valid := c.token.get(); !valid {
 		return nil, errors.New("authorization token contains invalid characters")
-	} else if c.token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	} else if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
 
 	if body != nil {
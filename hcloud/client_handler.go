@@ -26,21 +26,55 @@ func assembleHandlerChain(client *Client) handler {
 		h = wrapDebugHandler(h, client.debugWriter)
 	}
 
-	// Read rate limit headers
-	h = wrapRateLimitHandler(h)
+	// Read rate limit headers, and throttle requests if a rate limiter is configured
+	h = wrapRateLimitHandler(h, client.rateLimiter)
 
 	// Build error from response
 	h = wrapErrorHandler(h)
 
+	telemetryEnabled := client.tracerProvider != nil || client.meterProvider != nil
+
+	// Trace each attempt of the request if enabled
+	if telemetryEnabled {
+		h = wrapTelemetryAttemptHandler(h, client.telemetry)
+	}
+
 	// Retry request if condition are met
-	h = wrapRetryHandler(h, client.retryBackoffFunc, client.retryMaxRetries)
+	h = wrapRetryHandler(h, client.retryBackoffFunc, client.retryMaxRetries, client.retryPolicy)
+
+	// Retry action operations failing on locked resources if enabled
+	h = wrapLockedRetryHandler(h, client)
 
 	// Finally parse the response body into the provided schema
 	h = wrapParseHandler(h)
 
+	// Log the whole request if enabled
+	if client.logger != nil {
+		h = wrapLogHandler(h, client.logger, client.loggerOpts)
+	}
+
+	// Trace and measure the whole request if enabled
+	if telemetryEnabled {
+		h = wrapTelemetryHandler(h, client.telemetry)
+	}
+
 	return h
 }
 
+// attemptsKey is the context key holding the number of attempts of an API call. The
+// counter is updated by the [retryHandler].
+type attemptsKey struct{}
+
+// withAttempts returns a context holding an attempts counter, reusing the counter of
+// the parent context if any.
+func withAttempts(ctx context.Context) (context.Context, *int) {
+	if attempts, ok := ctx.Value(attemptsKey{}).(*int); ok {
+		return ctx, attempts
+	}
+	attempts := new(int)
+	return context.WithValue(ctx, attemptsKey{}, attempts), attempts
+}
+
 // cloneRequest clones both the request and the request body.
 func cloneRequest(req *http.Request, ctx context.Context) (cloned *http.Request, err error) { //revive:disable:context-as-argument
 	cloned = req.Clone(ctx)
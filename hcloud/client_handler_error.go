@@ -11,6 +11,20 @@ import (
 
 var ErrStatusCode = errors.New("server responded with status code")
 
+// statusCodeError is returned when the server responded with an error status code,
+// without an API error in the body.
+type statusCodeError struct {
+	statusCode int
+}
+
+func (e statusCodeError) Error() string {
+	return fmt.Sprintf("%s %d", ErrStatusCode, e.statusCode)
+}
+
+func (e statusCodeError) Unwrap() error {
+	return ErrStatusCode
+}
+
 func wrapErrorHandler(wrapped handler) handler {
 	return &errorHandler{wrapped}
 }
@@ -28,7 +42,7 @@ func (h *errorHandler) Do(req *http.Request, v any) (resp *Response, err error)
 	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
 		err = errorFromBody(resp)
 		if err == nil {
-			err = fmt.Errorf("hcloud: %w %d", ErrStatusCode, resp.StatusCode)
+			err = fmt.Errorf("hcloud: %w", statusCodeError{resp.StatusCode})
 		}
 	}
 	return resp, err
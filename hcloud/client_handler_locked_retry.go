@@ -0,0 +1,222 @@
+package hcloud
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/ctxutil"
+)
+
+// replayableOperations are the action operations that are safe to replay after they
+// failed with the locked or conflict API errors. The API rejected the request before
+// starting the action, and replaying the operation converges the resource to the
+// same state, without creating resources or returning new secrets.
+//
+// The operations are written as operation paths, see [ctxutil.OpPath].
+var replayableOperations = []string{
+	"/certificates/-/actions/retry",
+	"/firewalls/-/actions/apply_to_resources",
+	"/firewalls/-/actions/remove_from_resources",
+	"/firewalls/-/actions/set_rules",
+	"/floating_ips/-/actions/assign",
+	"/floating_ips/-/actions/change_dns_ptr",
+	"/floating_ips/-/actions/change_protection",
+	"/floating_ips/-/actions/unassign",
+	"/images/-/actions/change_protection",
+	"/load_balancers/-/actions/add_target",
+	"/load_balancers/-/actions/attach_to_network",
+	"/load_balancers/-/actions/change_algorithm",
+	"/load_balancers/-/actions/change_dns_ptr",
+	"/load_balancers/-/actions/change_protection",
+	"/load_balancers/-/actions/detach_from_network",
+	"/load_balancers/-/actions/disable_public_interface",
+	"/load_balancers/-/actions/enable_public_interface",
+	"/load_balancers/-/actions/remove_target",
+	"/load_balancers/-/actions/update_service",
+	"/networks/-/actions/change_protection",
+	"/primary_ips/-/actions/assign",
+	"/primary_ips/-/actions/change_dns_ptr",
+	"/primary_ips/-/actions/change_protection",
+	"/primary_ips/-/actions/unassign",
+	"/servers/-/actions/add_to_placement_group",
+	"/servers/-/actions/attach_iso",
+	"/servers/-/actions/attach_to_network",
+	"/servers/-/actions/change_alias_ips",
+	"/servers/-/actions/change_dns_ptr",
+	"/servers/-/actions/change_protection",
+	"/servers/-/actions/detach_from_network",
+	"/servers/-/actions/detach_iso",
+	"/servers/-/actions/disable_backup",
+	"/servers/-/actions/disable_rescue",
+	"/servers/-/actions/enable_backup",
+	"/servers/-/actions/poweroff",
+	"/servers/-/actions/poweron",
+	"/servers/-/actions/reboot",
+	"/servers/-/actions/remove_from_placement_group",
+	"/servers/-/actions/reset",
+	"/servers/-/actions/shutdown",
+	"/storage_boxes/-/actions/change_protection",
+	"/storage_boxes/-/actions/disable_snapshot_plan",
+	"/storage_boxes/-/actions/enable_snapshot_plan",
+	"/storage_boxes/-/actions/update_access_settings",
+	"/volumes/-/actions/attach",
+	"/volumes/-/actions/change_protection",
+	"/volumes/-/actions/detach",
+	"/zones/-/actions/change_primary_nameservers",
+	"/zones/-/actions/change_protection",
+	"/zones/-/actions/change_ttl",
+}
+
+// LockedRetryOpts defines the options used to retry the action operations that
+// failed with the locked or conflict API errors.
+//
+// Before retrying, the running actions of the resource are awaited. If the resource
+// has no running action, the BackoffFunc is used instead.
+type LockedRetryOpts struct {
+	// MaxRetries is the maximum number of retries of an operation, 0 disables the
+	// retries.
+	MaxRetries int
+	// BackoffFunc is used when no running action of the resource was found, defaults
+	// to a constant backoff of one second.
+	BackoffFunc BackoffFunc
+	// Operations are the retried operations, written as operation paths (e.g.
+	// "/servers/-/actions/poweron"). Defaults to the action operations that are safe to
+	// replay, which converge the resource to the same state without creating resources
+	// or returning new secrets (e.g. power on, attach volume, apply firewall).
+	Operations []string
+}
+
+func (o LockedRetryOpts) retries(operation string) bool {
+	operations := o.Operations
+	if operations == nil {
+		operations = replayableOperations
+	}
+	return o.MaxRetries > 0 && slices.Contains(operations, operation)
+}
+
+func (o LockedRetryOpts) backoff(retries int) time.Duration {
+	if o.BackoffFunc == nil {
+		return time.Second
+	}
+	return o.BackoffFunc(retries)
+}
+
+// WithLockedRetryOpts configures a Client to retry the action operations that failed
+// with the locked or conflict API errors, see [LockedRetryOpts].
+func WithLockedRetryOpts(opts LockedRetryOpts) ClientOption {
+	return func(client *Client) {
+		client.lockedRetryOpts = opts
+	}
+}
+
+// lockedRetryKey is the context key holding the [LockedRetryOpts] of an API call.
+type lockedRetryKey struct{}
+
+// ContextWithLockedRetry returns a context configuring the API calls made with it to
+// retry the action operations that failed with the locked or conflict API errors.
+// The options override the ones configured with [WithLockedRetryOpts].
+func ContextWithLockedRetry(ctx context.Context, opts LockedRetryOpts) context.Context {
+	return context.WithValue(ctx, lockedRetryKey{}, opts)
+}
+
+// withoutRetryState returns a context for the API calls nested in a retried API call,
+// without the attempts counter and the locked retry options of the retried call.
+func withoutRetryState(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, attemptsKey{}, nil)
+	return context.WithValue(ctx, lockedRetryKey{}, nil)
+}
+
+func wrapLockedRetryHandler(wrapped handler, client *Client) handler {
+	return &lockedRetryHandler{wrapped, client}
+}
+
+// lockedRetryHandler retries the action operations that failed with the locked or
+// conflict API errors, after waiting for the running actions of the resource.
+type lockedRetryHandler struct {
+	handler handler
+	client  *Client
+}
+
+func (h *lockedRetryHandler) Do(req *http.Request, v any) (resp *Response, err error) {
+	ctx := req.Context()
+
+	opts, ok := ctx.Value(lockedRetryKey{}).(LockedRetryOpts)
+	if !ok {
+		opts = h.client.lockedRetryOpts
+	}
+	operation := ctxutil.OpPath(ctx)
+	if req.Method != http.MethodPost || !opts.retries(operation) {
+		return h.handler.Do(req, v)
+	}
+
+	retries := 0
+	for {
+		// Clone the request using the original context
+		cloned, err := cloneRequest(req, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = h.handler.Do(cloned, v)
+		if err == nil || retries >= opts.MaxRetries || !IsError(err, ErrorCodeLocked, ErrorCodeConflict) {
+			return resp, err
+		}
+
+		waited, waitErr := h.waitForRunningActions(ctx, operation, req)
+		if waitErr != nil {
+			return resp, err
+		}
+		if !waited {
+			select {
+			case <-ctx.Done():
+				return resp, err
+			case <-time.After(opts.backoff(retries)):
+			}
+		}
+		retries++
+	}
+}
+
+// waitForRunningActions waits for the running actions of the resource targeted by the
+// request, and reports whether running actions were found.
+func (h *lockedRetryHandler) waitForRunningActions(ctx context.Context, operation string, req *http.Request) (bool, error) {
+	resource, _, _ := strings.Cut(strings.TrimPrefix(operation, "/"), "/")
+	id := resourceID(operation, req.URL.Path)
+	if resource == "" || id == "" {
+		return false, nil
+	}
+
+	// The nested API calls must not update the attempts of the retried call.
+	ctx = withoutRetryState(ctx)
+
+	// The Hetzner API resources (e.g. storage boxes) are served by another endpoint.
+	client := h.client
+	if !strings.HasPrefix(req.URL.String(), client.endpoint) && strings.HasPrefix(req.URL.String(), client.hetznerEndpoint) {
+		hetznerClient := *client
+		hetznerClient.endpoint = hetznerClient.hetznerEndpoint
+		client = &hetznerClient
+	}
+
+	actionClient := &ResourceActionClient[pathIDResource]{client: client, resource: resource}
+	actions, err := actionClient.AllFor(ctx, pathIDResource(id), ActionListOpts{Status: []ActionStatus{ActionStatusRunning}})
+	if err != nil {
+		return false, err
+	}
+	if len(actions) == 0 {
+		return false, nil
+	}
+
+	// The outcome of the blocking actions is not relevant, only their completion. The
+	// actions are polled on the endpoint of the resource.
+	waiter := &ActionClient{action: &ResourceActionClient[noopResource]{client: client}}
+	err = waiter.WaitForFunc(ctx, nil, actions...)
+	return true, err
+}
+
+// pathIDResource references a resource using its raw path ID.
+type pathIDResource string
+
+func (r pathIDResource) pathID() (string, error) { return string(r), nil }
@@ -0,0 +1,196 @@
+package hcloud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/mockutil"
+)
+
+func TestLockedRetryHandler(t *testing.T) {
+	locked := mockutil.Request{
+		Method: "POST", Path: "/servers/1/actions/poweron",
+		Status:  423,
+		JSONRaw: `{"error":{"code":"locked","message":"server is locked"}}`,
+	}
+	poweron := mockutil.Request{
+		Method: "POST", Path: "/servers/1/actions/poweron",
+		Status:  201,
+		JSONRaw: `{"action":{"id":13,"status":"running"}}`,
+	}
+
+	newClient := func(t *testing.T, requests []mockutil.Request, options ...ClientOption) *Client {
+		t.Helper()
+
+		server := mockutil.NewServer(t, requests)
+		return NewClient(append([]ClientOption{
+			WithEndpoint(server.URL),
+			WithRetryOpts(RetryOpts{BackoffFunc: ConstantBackoff(0), MaxRetries: 3}),
+			WithPollOpts(PollOpts{BackoffFunc: ConstantBackoff(time.Millisecond)}),
+		}, options...)...)
+	}
+
+	t.Run("waits for running action", func(t *testing.T) {
+		client := newClient(t, []mockutil.Request{
+			locked,
+			{
+				Method: "GET", Path: "/servers/1/actions?page=1&status=running",
+				Status: 200,
+				JSONRaw: `{
+					"actions": [{ "id": 10, "status": "running" }],
+					"meta": { "pagination": { "page": 1 }}
+				}`,
+			},
+			{
+				Method: "GET", Path: "/actions?id=10&page=1&sort=status&sort=id",
+				Status: 200,
+				JSONRaw: `{
+					"actions": [{ "id": 10, "status": "error", "error": { "code": "failed", "message": "failed" } }],
+					"meta": { "pagination": { "page": 1 }}
+				}`,
+			},
+			poweron,
+		}, WithLockedRetryOpts(LockedRetryOpts{MaxRetries: 2}))
+
+		action, _, err := client.Server.Poweron(context.Background(), &Server{ID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), action.ID)
+	})
+
+	t.Run("backoff without running action", func(t *testing.T) {
+		backoffs := 0
+		client := newClient(t, []mockutil.Request{
+			locked,
+			{
+				Method: "GET", Path: "/servers/1/actions?page=1&status=running",
+				Status: 200,
+				JSONRaw: `{
+					"actions": [],
+					"meta": { "pagination": { "page": 1 }}
+				}`,
+			},
+			poweron,
+		}, WithLockedRetryOpts(LockedRetryOpts{
+			MaxRetries: 2,
+			BackoffFunc: func(int) time.Duration {
+				backoffs++
+				return 0
+			},
+		}))
+
+		_, _, err := client.Server.Poweron(context.Background(), &Server{ID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 1, backoffs)
+	})
+
+	t.Run("max retries", func(t *testing.T) {
+		noRunningActions := mockutil.Request{
+			Method: "GET", Path: "/servers/1/actions?page=1&status=running",
+			Status:  200,
+			JSONRaw: `{"actions":[],"meta":{"pagination":{"page":1}}}`,
+		}
+		client := newClient(t, []mockutil.Request{
+			locked, noRunningActions, locked,
+		}, WithLockedRetryOpts(LockedRetryOpts{MaxRetries: 1, BackoffFunc: ConstantBackoff(0)}))
+
+		_, _, err := client.Server.Poweron(context.Background(), &Server{ID: 1})
+		require.EqualError(t, err, "server is locked (locked)")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := newClient(t, []mockutil.Request{locked})
+
+		_, _, err := client.Server.Poweron(context.Background(), &Server{ID: 1})
+		require.EqualError(t, err, "server is locked (locked)")
+	})
+
+	t.Run("not replayable operation", func(t *testing.T) {
+		client := newClient(t, []mockutil.Request{
+			{
+				Method: "POST", Path: "/servers/1/actions/rebuild",
+				Status:  423,
+				JSONRaw: `{"error":{"code":"locked","message":"server is locked"}}`,
+			},
+		}, WithLockedRetryOpts(LockedRetryOpts{MaxRetries: 2}))
+
+		_, _, err := client.Server.RebuildWithResult(context.Background(), &Server{ID: 1}, ServerRebuildOpts{Image: &Image{ID: 1}})
+		require.EqualError(t, err, "server is locked (locked)")
+	})
+
+	t.Run("storage box", func(t *testing.T) {
+		cloudServer := mockutil.NewServer(t, nil)
+		hetznerServer := mockutil.NewServer(t, []mockutil.Request{
+			{
+				Method: "POST", Path: "/storage_boxes/1/actions/change_protection",
+				Status:  423,
+				JSONRaw: `{"error":{"code":"locked","message":"storage box is locked"}}`,
+			},
+			{
+				Method: "GET", Path: "/storage_boxes/1/actions?page=1&status=running",
+				Status: 200,
+				JSONRaw: `{
+					"actions": [{ "id": 10, "status": "running" }],
+					"meta": { "pagination": { "page": 1 }}
+				}`,
+			},
+			{
+				Method: "GET", Path: "/actions?id=10&page=1&sort=status&sort=id",
+				Status: 200,
+				JSONRaw: `{
+					"actions": [{ "id": 10, "status": "success" }],
+					"meta": { "pagination": { "page": 1 }}
+				}`,
+			},
+			{
+				Method: "POST", Path: "/storage_boxes/1/actions/change_protection",
+				Status:  201,
+				JSONRaw: `{"action":{"id":13,"status":"running"}}`,
+			},
+		})
+		client := NewClient(
+			WithEndpoint(cloudServer.URL),
+			WithHetznerEndpoint(hetznerServer.URL),
+			WithRetryOpts(RetryOpts{BackoffFunc: ConstantBackoff(0), MaxRetries: 3}),
+			WithPollOpts(PollOpts{BackoffFunc: ConstantBackoff(time.Millisecond)}),
+			WithLockedRetryOpts(LockedRetryOpts{MaxRetries: 2}),
+		)
+
+		action, _, err := client.StorageBox.ChangeProtection(context.Background(), &StorageBox{ID: 1}, StorageBoxChangeProtectionOpts{Delete: Ptr(true)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), action.ID)
+	})
+
+	t.Run("per call", func(t *testing.T) {
+		client := newClient(t, []mockutil.Request{
+			locked,
+			{
+				Method: "GET", Path: "/servers/1/actions?page=1&status=running",
+				Status:  200,
+				JSONRaw: `{"actions":[],"meta":{"pagination":{"page":1}}}`,
+			},
+			poweron,
+		})
+
+		ctx := ContextWithLockedRetry(context.Background(), LockedRetryOpts{MaxRetries: 1, BackoffFunc: ConstantBackoff(0)})
+		_, _, err := client.Server.Poweron(ctx, &Server{ID: 1})
+		require.NoError(t, err)
+	})
+}
+
+func TestWithoutRetryState(t *testing.T) {
+	ctx, attempts := withAttempts(context.Background())
+	*attempts = 2
+	ctx = ContextWithLockedRetry(ctx, LockedRetryOpts{MaxRetries: 1})
+
+	nested := withoutRetryState(ctx)
+	assert.Nil(t, nested.Value(attemptsKey{}))
+	assert.Nil(t, nested.Value(lockedRetryKey{}))
+
+	_, nestedAttempts := withAttempts(nested)
+	assert.NotSame(t, attempts, nestedAttempts)
+	assert.Equal(t, 2, *attempts)
+}
@@ -0,0 +1,109 @@
+package hcloud
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/ctxutil"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/internal/redact"
+)
+
+func wrapLogHandler(wrapped handler, logger *slog.Logger, opts LoggerOpts) handler {
+	h := &logHandler{
+		handler:        wrapped,
+		logger:         logger,
+		level:          slog.LevelDebug,
+		errorLevel:     slog.LevelWarn,
+		body:           opts.Body,
+		redactedFields: slices.Concat(redact.SensitiveFields, opts.RedactedFields),
+		sampleEvery:    int64(max(opts.SampleEvery, 1)),
+	}
+	if opts.Level != nil {
+		h.level = opts.Level
+	}
+	if opts.ErrorLevel != nil {
+		h.errorLevel = opts.ErrorLevel
+	}
+	return h
+}
+
+// logHandler logs one record per API call, including the retries.
+type logHandler struct {
+	handler        handler
+	logger         *slog.Logger
+	level          slog.Leveler
+	errorLevel     slog.Leveler
+	body           bool
+	redactedFields []string
+	sampleEvery    int64
+
+	calls atomic.Int64
+}
+
+func (h *logHandler) Do(req *http.Request, v any) (resp *Response, err error) {
+	ctx, attempts := withAttempts(req.Context())
+	start := time.Now()
+
+	resp, err = h.handler.Do(req.WithContext(ctx), v)
+
+	level := h.level.Level()
+	if err != nil {
+		level = h.errorLevel.Level()
+	} else if (h.calls.Add(1)-1)%h.sampleEvery != 0 {
+		return resp, err
+	}
+
+	if !h.logger.Enabled(ctx, level) {
+		return resp, err
+	}
+
+	operation := ctxutil.OpPath(ctx)
+	if operation == "" {
+		operation = req.URL.Path
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("operation", operation),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("retries", max(*attempts-1, 0)),
+	}
+
+	if resp != nil && resp.Response != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if id := resp.internalCorrelationID(); id != "" {
+			attrs = append(attrs, slog.String("correlation-id", id))
+		}
+	}
+
+	if err != nil {
+		var apiErr Error
+		if errors.As(err, &apiErr) {
+			attrs = append(attrs, slog.Any("error", apiErr.LogValue()))
+		} else {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+	}
+
+	if h.body {
+		if req.GetBody != nil && req.ContentLength > 0 {
+			if body, bodyErr := req.GetBody(); bodyErr == nil {
+				data, _ := io.ReadAll(body)
+				_ = body.Close()
+				attrs = append(attrs, slog.String("request-body", string(redact.JSON(data, h.redactedFields))))
+			}
+		}
+		if resp != nil && len(resp.body) > 0 {
+			attrs = append(attrs, slog.String("response-body", string(redact.JSON(resp.body, h.redactedFields))))
+		}
+	}
+
+	h.logger.LogAttrs(ctx, level, "api request", attrs...)
+
+	return resp, err
+}
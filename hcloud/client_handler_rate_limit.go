@@ -6,15 +6,22 @@ import (
 	"time"
 )
 
-func wrapRateLimitHandler(wrapped handler) handler {
-	return &rateLimitHandler{wrapped}
+func wrapRateLimitHandler(wrapped handler, limiter *RateLimiter) handler {
+	return &rateLimitHandler{wrapped, limiter}
 }
 
 type rateLimitHandler struct {
 	handler handler
+	limiter *RateLimiter
 }
 
 func (h *rateLimitHandler) Do(req *http.Request, v any) (resp *Response, err error) {
+	if h.limiter != nil {
+		if err := h.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+
 	resp, err = h.handler.Do(req, v)
 
 	// Ensure the embedded [*http.Response] is not nil, e.g. on canceled context
@@ -30,6 +37,10 @@ func (h *rateLimitHandler) Do(req *http.Request, v any) (resp *Response, err err
 				resp.Meta.Ratelimit.Reset = time.Unix(ts, 0)
 			}
 		}
+
+		if h.limiter != nil && resp.Meta.Ratelimit.Limit > 0 {
+			h.limiter.update(resp.Meta.Ratelimit)
+		}
 	}
 
 	return resp, err
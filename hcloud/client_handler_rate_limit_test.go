@@ -1,12 +1,15 @@
 package hcloud
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRateLimitHandler(t *testing.T) {
@@ -45,7 +48,7 @@ func TestRateLimitHandler(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			m := &mockHandler{testCase.wrapped}
-			h := wrapRateLimitHandler(m)
+			h := wrapRateLimitHandler(m, nil)
 
 			resp, err := h.Do(nil, nil)
 
@@ -53,3 +56,37 @@ func TestRateLimitHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestRateLimitHandlerWithLimiter(t *testing.T) {
+	limiter := NewRateLimiter()
+
+	calls := 0
+	m := &mockHandler{func(_ *http.Request, _ any) (*Response, error) {
+		calls++
+		resp := fakeResponse(t, 200, "", false)
+		resp.Header.Set("RateLimit-Limit", "3600")
+		resp.Header.Set("RateLimit-Remaining", "0")
+		resp.Header.Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		return resp, nil
+	}}
+	h := wrapRateLimitHandler(m, limiter)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	_, err = h.Do(req, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 3600, limiter.Ratelimit().Limit)
+
+	// The budget is exhausted, the next request is blocked until the context is done.
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	_, err = h.Do(req, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, calls)
+}
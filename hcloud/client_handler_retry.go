@@ -7,21 +7,27 @@ import (
 	"time"
 )
 
-func wrapRetryHandler(wrapped handler, backoffFunc BackoffFunc, maxRetries int) handler {
-	return &retryHandler{wrapped, backoffFunc, maxRetries}
+func wrapRetryHandler(wrapped handler, backoffFunc BackoffFunc, maxRetries int, policy RetryPolicy) handler {
+	return &retryHandler{wrapped, backoffFunc, maxRetries, policy}
 }
 
 type retryHandler struct {
 	handler     handler
 	backoffFunc BackoffFunc
 	maxRetries  int
+	policy      RetryPolicy
 }
 
 func (h *retryHandler) Do(req *http.Request, v any) (resp *Response, err error) {
 	retries := 0
 	ctx := req.Context()
+	attempts, _ := ctx.Value(attemptsKey{}).(*int)
 
 	for {
+		if attempts != nil {
+			*attempts = retries + 1
+		}
+
 		// Clone the request using the original context
 		cloned, err := cloneRequest(req, ctx)
 		if err != nil {
@@ -39,7 +45,7 @@ func (h *retryHandler) Do(req *http.Request, v any) (resp *Response, err error)
 				return resp, err
 			}
 
-			if retries < h.maxRetries && retryPolicy(resp, err) {
+			if retries < h.maxRetries && h.policy(resp, err) {
 				select {
 				case <-ctx.Done():
 					return resp, err
@@ -54,7 +60,18 @@ func (h *retryHandler) Do(req *http.Request, v any) (resp *Response, err error)
 	}
 }
 
-func retryPolicy(resp *Response, err error) bool {
+// RetryPolicy reports whether a failed request is retried by the [Client], see
+// [RetryOpts].
+type RetryPolicy func(resp *Response, err error) bool
+
+// DefaultRetryPolicy retries the requests that failed with the conflict,
+// rate_limit_exceeded, bad_gateway or timeout API errors, the HTTP 502 and 504 status
+// codes, or a network timeout.
+//
+// The locked API error is not retried, as it is used in many unexpected situations,
+// and may only be retried in specific contexts where the error is known not to be
+// misused.
+func DefaultRetryPolicy(resp *Response, err error) bool {
 	if err != nil {
 		var apiErr Error
 		var netErr net.Error
@@ -72,7 +89,7 @@ func retryPolicy(resp *Response, err error) bool {
 				return true
 			}
 		case errors.Is(err, ErrStatusCode):
-			switch resp.Response.StatusCode {
+			switch statusCodeOf(resp, err) {
 			// 5xx errors
 			case http.StatusBadGateway, http.StatusGatewayTimeout:
 				return true
@@ -86,3 +103,11 @@ func retryPolicy(resp *Response, err error) bool {
 
 	return false
 }
+
+// TransientRetryPolicy retries the requests that failed with an error of the
+// [ErrorCategoryTransient] category, see [IsRetryable]. Like the
+// [DefaultRetryPolicy], the locked API error is not retried, as it is used in many
+// unexpected situations, see [LockedRetryOpts] to retry it for specific operations.
+func TransientRetryPolicy(resp *Response, err error) bool {
+	return errorCategory(resp, err) == ErrorCategoryTransient && !IsError(err, ErrorCodeLocked)
+}
@@ -96,7 +96,7 @@ func TestRetryHandler(t *testing.T) {
 
 				retryCount++
 				return 0
-			}, 5)
+			}, 5, DefaultRetryPolicy)
 
 			client := NewClient(WithToken("dummy"))
 			req, err := client.NewRequest(context.Background(), "GET", "/", nil)
@@ -188,7 +188,7 @@ func TestRetryPolicy(t *testing.T) {
 			}}
 			h := wrapErrorHandler(m)
 
-			result := retryPolicy(h.Do(req, nil))
+			result := DefaultRetryPolicy(h.Do(req, nil))
 			assert.Equal(t, testCase.want, result)
 		})
 	}
@@ -0,0 +1,191 @@
+package hcloud
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/ctxutil"
+)
+
+const telemetryScope = "github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+// Attributes set on the spans and metrics.
+const (
+	attrOperation          = attribute.Key("hcloud.operation")
+	attrResourceID         = attribute.Key("hcloud.resource.id")
+	attrErrorCode          = attribute.Key("hcloud.error.code")
+	attrRetryAttempt       = attribute.Key("hcloud.retry.attempt")
+	attrRateLimitRemaining = attribute.Key("hcloud.ratelimit.remaining")
+	attrCorrelationID      = attribute.Key("hcloud.correlation_id")
+	attrActionCount        = attribute.Key("hcloud.action.count")
+	attrHTTPMethod         = attribute.Key("http.request.method")
+	attrHTTPStatusCode     = attribute.Key("http.response.status_code")
+)
+
+// telemetry holds the tracer and instruments of a [Client]. Without providers, the
+// tracer and instruments are no-op.
+type telemetry struct {
+	tracer   trace.Tracer
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) *telemetry {
+	t := &telemetry{}
+
+	t.tracer = tracerProvider.Tracer(telemetryScope, trace.WithInstrumentationVersion(Version))
+
+	meter := meterProvider.Meter(telemetryScope, metric.WithInstrumentationVersion(Version))
+	// Errors are only returned for invalid instrument names, the instruments are then
+	// still usable.
+	t.requests, _ = meter.Int64Counter("hcloud.api.requests",
+		metric.WithDescription("Number of requests to the API."),
+		metric.WithUnit("{request}"),
+	)
+	t.duration, _ = meter.Float64Histogram("hcloud.api.request.duration",
+		metric.WithDescription("Duration of the requests to the API, including retries."),
+		metric.WithUnit("s"),
+	)
+
+	return t
+}
+
+func wrapTelemetryHandler(wrapped handler, telemetry *telemetry) handler {
+	return &telemetryHandler{wrapped, telemetry}
+}
+
+// telemetryHandler emits one span per API call, and records the call metrics.
+type telemetryHandler struct {
+	handler   handler
+	telemetry *telemetry
+}
+
+func (h *telemetryHandler) Do(req *http.Request, v any) (resp *Response, err error) {
+	ctx := req.Context()
+	start := time.Now()
+
+	operation := ctxutil.OpPath(ctx)
+	if operation == "" {
+		operation = req.URL.Path
+	}
+
+	attrs := []attribute.KeyValue{
+		attrOperation.String(operation),
+		attrHTTPMethod.String(req.Method),
+	}
+	if id := resourceID(operation, req.URL.Path); id != "" {
+		attrs = append(attrs, attrResourceID.String(id))
+	}
+
+	ctx, span := h.telemetry.tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	ctx, attempts := withAttempts(ctx)
+
+	resp, err = h.handler.Do(req.WithContext(ctx), v)
+
+	span.SetAttributes(attrRetryAttempt.Int(max(*attempts-1, 0)))
+	spanAttrs := responseAttributes(resp, err)
+	span.SetAttributes(spanAttrs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	metricAttrs := metric.WithAttributes(append(attrs[:2:2], metricAttributes(resp, err)...)...)
+	h.telemetry.requests.Add(ctx, 1, metricAttrs)
+	h.telemetry.duration.Record(ctx, time.Since(start).Seconds(), metricAttrs)
+
+	return resp, err
+}
+
+func wrapTelemetryAttemptHandler(wrapped handler, telemetry *telemetry) handler {
+	return &telemetryAttemptHandler{wrapped, telemetry}
+}
+
+// telemetryAttemptHandler emits one child span per attempt of an API call.
+type telemetryAttemptHandler struct {
+	handler   handler
+	telemetry *telemetry
+}
+
+func (h *telemetryAttemptHandler) Do(req *http.Request, v any) (resp *Response, err error) {
+	ctx := req.Context()
+
+	attempt := 0
+	if attempts, ok := ctx.Value(attemptsKey{}).(*int); ok {
+		attempt = max(*attempts-1, 0)
+	}
+
+	ctx, span := h.telemetry.tracer.Start(ctx, "attempt",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attrRetryAttempt.Int(attempt)),
+	)
+	defer span.End()
+
+	resp, err = h.handler.Do(req.WithContext(ctx), v)
+
+	span.SetAttributes(responseAttributes(resp, err)...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return resp, err
+}
+
+// responseAttributes returns the span attributes describing the response.
+func responseAttributes(resp *Response, err error) []attribute.KeyValue {
+	attrs := metricAttributes(resp, err)
+	if resp != nil && resp.Response != nil {
+		if resp.Meta.Ratelimit.Limit > 0 {
+			attrs = append(attrs, attrRateLimitRemaining.Int(resp.Meta.Ratelimit.Remaining))
+		}
+		if id := resp.internalCorrelationID(); id != "" {
+			attrs = append(attrs, attrCorrelationID.String(id))
+		}
+	}
+	return attrs
+}
+
+// metricAttributes returns the low cardinality attributes describing the response.
+func metricAttributes(resp *Response, err error) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if resp != nil && resp.Response != nil {
+		attrs = append(attrs, attrHTTPStatusCode.Int(resp.StatusCode))
+	}
+	var apiErr Error
+	if errors.As(err, &apiErr) {
+		attrs = append(attrs, attrErrorCode.String(string(apiErr.Code)))
+	}
+	return attrs
+}
+
+// resourceID returns the first ID of the request path, using the placeholders of the
+// operation path (e.g. /servers/-/actions/poweron) to locate it. The request path may
+// contain a prefix (e.g. /v1).
+func resourceID(operation, path string) string {
+	opSegments := strings.Split(strings.Trim(operation, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	offset := len(pathSegments) - len(opSegments)
+	if offset < 0 {
+		return ""
+	}
+	for i, segment := range opSegments {
+		if segment == "-" {
+			return pathSegments[offset+i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package hcloud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/mockutil"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	result := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		result[attr.Key] = attr.Value
+	}
+	return result
+}
+
+func TestTelemetryHandler(t *testing.T) {
+	server := mockutil.NewServer(t, []mockutil.Request{
+		{
+			Method: "POST", Path: "/servers/1/actions/poweron",
+			Status: 409,
+			JSONRaw: `{
+				"error": { "code": "conflict", "message": "resource is locked" }
+			}`,
+		},
+		{
+			Method: "POST", Path: "/servers/1/actions/poweron",
+			Status: 201,
+			JSONRaw: `{
+				"action": { "id": 13, "status": "running" }
+			}`,
+		},
+		{
+			Method: "GET", Path: "/actions?id=13&page=1&sort=status&sort=id",
+			Status: 200,
+			JSONRaw: `{
+				"actions": [{ "id": 13, "status": "success" }],
+				"meta": { "pagination": { "page": 1 }}
+			}`,
+		},
+	})
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	client := NewClient(
+		WithEndpoint(server.URL),
+		WithToken("token"),
+		WithRetryOpts(RetryOpts{BackoffFunc: ConstantBackoff(0), MaxRetries: 3}),
+		WithPollOpts(PollOpts{BackoffFunc: ConstantBackoff(time.Millisecond)}),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	ctx := context.Background()
+	action, _, err := client.Server.Poweron(ctx, &Server{ID: 1})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	ended := spans.Ended()
+	require.Len(t, ended, 6)
+
+	// Poweron: two attempts and the API call
+	attempt0, attempt1, poweron := ended[0], ended[1], ended[2]
+	assert.Equal(t, "/servers/-/actions/poweron", poweron.Name())
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		attrOperation:      attribute.StringValue("/servers/-/actions/poweron"),
+		attrHTTPMethod:     attribute.StringValue("POST"),
+		attrResourceID:     attribute.StringValue("1"),
+		attrRetryAttempt:   attribute.IntValue(1),
+		attrHTTPStatusCode: attribute.IntValue(201),
+	}, spanAttributes(poweron))
+
+	assert.Equal(t, "attempt", attempt0.Name())
+	assert.Equal(t, poweron.SpanContext().SpanID(), attempt0.Parent().SpanID())
+	assert.Equal(t, codes.Error, attempt0.Status().Code)
+	assert.Equal(t, attribute.IntValue(0), spanAttributes(attempt0)[attrRetryAttempt])
+	assert.Equal(t, attribute.StringValue("conflict"), spanAttributes(attempt0)[attrErrorCode])
+	assert.Equal(t, attribute.IntValue(409), spanAttributes(attempt0)[attrHTTPStatusCode])
+
+	assert.Equal(t, "attempt", attempt1.Name())
+	assert.Equal(t, poweron.SpanContext().SpanID(), attempt1.Parent().SpanID())
+	assert.Equal(t, attribute.IntValue(1), spanAttributes(attempt1)[attrRetryAttempt])
+
+	// WaitFor: the polling API call is a child of the wait span
+	polling, waitFor := ended[4], ended[5]
+	assert.Equal(t, "/actions", polling.Name())
+	assert.Equal(t, "WaitForFunc", waitFor.Name())
+	assert.Equal(t, waitFor.SpanContext().SpanID(), polling.Parent().SpanID())
+	assert.Equal(t, attribute.IntValue(1), spanAttributes(waitFor)[attrActionCount])
+
+	var metrics metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &metrics))
+	require.Len(t, metrics.ScopeMetrics, 1)
+
+	names := []string{}
+	for _, m := range metrics.ScopeMetrics[0].Metrics {
+		names = append(names, m.Name)
+		if m.Name == "hcloud.api.requests" {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			require.True(t, ok)
+			total := int64(0)
+			for _, point := range sum.DataPoints {
+				total += point.Value
+			}
+			assert.Equal(t, int64(2), total)
+		}
+	}
+	assert.ElementsMatch(t, []string{"hcloud.api.requests", "hcloud.api.request.duration"}, names)
+}
+
+func TestTelemetryResourceID(t *testing.T) {
+	for _, tt := range []struct {
+		operation string
+		path      string
+		want      string
+	}{
+		{operation: "/servers/-/actions/poweron", path: "/v1/servers/42/actions/poweron", want: "42"},
+		{operation: "/zones/-/rrsets/-/-", path: "/v1/zones/example.com/rrsets/www/A", want: "example.com"},
+		{operation: "/servers", path: "/v1/servers", want: ""},
+		{operation: "/servers/-", path: "/", want: ""},
+	} {
+		t.Run(tt.operation, func(t *testing.T) {
+			assert.Equal(t, tt.want, resourceID(tt.operation, tt.path))
+		})
+	}
+}
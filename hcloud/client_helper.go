@@ -2,6 +2,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 	"strconv"
 )
 
@@ -18,21 +19,43 @@ func allFromSchemaFunc[T, V any](all []T, fn func(T) V) []V {
 
 // iterPages fetches each pages using the list function, and returns the result.
 func iterPages[T any](listFn func(int) ([]*T, *Response, error)) ([]*T, error) {
-	page := 1
 	result := []*T{}
 
-	for {
-		pageResult, resp, err := listFn(page)
+	for item, err := range seqPages(listFn) {
 		if err != nil {
 			return nil, err
 		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// seqPages returns an iterator over the items of each pages, the pages are fetched
+// using the list function when the iteration reaches them. The iteration stops after
+// yielding an error.
+func seqPages[T any](listFn func(int) ([]*T, *Response, error)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		page := 1
+
+		for {
+			pageResult, resp, err := listFn(page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
 
-		result = append(result, pageResult...)
+			for _, item := range pageResult {
+				if !yield(item, nil) {
+					return
+				}
+			}
 
-		if resp.Meta.Pagination == nil || resp.Meta.Pagination.NextPage == 0 {
-			return result, nil
+			if resp.Meta.Pagination == nil || resp.Meta.Pagination.NextPage == 0 {
+				return
+			}
+			page = resp.Meta.Pagination.NextPage
 		}
-		page = resp.Meta.Pagination.NextPage
 	}
 }
 
@@ -30,3 +30,60 @@ func TestIterPages(t *testing.T) {
 		require.Nil(t, result)
 	})
 }
+
+func TestSeqPages(t *testing.T) {
+	listFn := func(calls *[]int) func(int) ([]*int, *Response, error) {
+		return func(page int) ([]*int, *Response, error) {
+			*calls = append(*calls, page)
+			if page < 4 {
+				return []*int{Ptr(page * 10), Ptr(page*10 + 1)}, &Response{Meta: Meta{Pagination: &Pagination{NextPage: page + 1}}}, nil
+			}
+			return []*int{Ptr(page * 10)}, &Response{}, nil
+		}
+	}
+
+	t.Run("succeed", func(t *testing.T) {
+		calls := []int{}
+		result := []int{}
+		for item, err := range seqPages(listFn(&calls)) {
+			require.NoError(t, err)
+			result = append(result, *item)
+		}
+		require.Equal(t, []int{10, 11, 20, 21, 30, 31, 40}, result)
+		require.Equal(t, []int{1, 2, 3, 4}, calls)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		calls := []int{}
+		result := []int{}
+		for item, err := range seqPages(listFn(&calls)) {
+			require.NoError(t, err)
+			result = append(result, *item)
+			if *item == 20 {
+				break
+			}
+		}
+		require.Equal(t, []int{10, 11, 20}, result)
+		require.Equal(t, []int{1, 2}, calls)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		result := []int{}
+		var errs []error
+		for item, err := range seqPages(func(page int) ([]*int, *Response, error) {
+			if page < 2 {
+				return []*int{Ptr(page)}, &Response{Meta: Meta{Pagination: &Pagination{NextPage: page + 1}}}, nil
+			}
+			return nil, &Response{}, fmt.Errorf("failure")
+		}) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			result = append(result, *item)
+		}
+		require.Equal(t, []int{1}, result)
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], "failure")
+	})
+}
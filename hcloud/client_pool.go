@@ -0,0 +1,199 @@
+package hcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClientPool creates and caches one [Client] per project, each project using its own
+// token. The clients share the [ClientOption]s given to [NewClientPool] (e.g. poll
+// options, instrumentation, HTTP transport).
+//
+// When instrumentation ([WithInstrumentation]) or logging ([WithLogger]) is
+// configured, the metrics and the log records of each client are labeled with the
+// project key. When a [RateLimiter] is configured ([WithRateLimiter]), each client
+// gets its own [RateLimiter], as the rate limit of the API is per project.
+//
+// A ClientPool must be created using the [NewClientPool] function.
+type ClientPool struct {
+	options []ClientOption
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewClientPool returns a new [ClientPool], creating its clients with the given
+// options.
+func NewClientPool(options ...ClientOption) *ClientPool {
+	return &ClientPool{
+		options: options,
+		clients: make(map[string]*Client),
+	}
+}
+
+// Client returns the client of the project, creating it with the token if needed.
+//
+// The token is only used to create the client, the token of an existing client is
+// left unchanged, see [ClientPool.SetToken] to rotate it.
+func (p *ClientPool) Client(project, token string) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[project]; ok {
+		return client
+	}
+
+	options := slices.Concat(p.options, []ClientOption{WithToken(token), withProject(project)})
+	client := NewClient(options...)
+	p.clients[project] = client
+	return client
+}
+
+// Get returns the client of the project, or nil if the project is not in the pool.
+func (p *ClientPool) Get(project string) *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.clients[project]
+}
+
+// SetToken rotates the token of the project client. In-flight requests finish with
+// the previous token. Returns false if the project is not in the pool.
+func (p *ClientPool) SetToken(project, token string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, ok := p.clients[project]
+	if ok {
+		client.token.set(token)
+	}
+	return ok
+}
+
+// Remove removes the client of the project from the pool.
+func (p *ClientPool) Remove(project string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.clients, project)
+}
+
+// Projects returns the sorted keys of the projects in the pool.
+func (p *ClientPool) Projects() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	projects := make([]string, 0, len(p.clients))
+	for project := range p.clients {
+		projects = append(projects, project)
+	}
+	slices.Sort(projects)
+	return projects
+}
+
+// ForEach calls fn concurrently for every project in the pool, with at most
+// parallelism calls in flight. A parallelism lower than 1 runs every call
+// concurrently.
+//
+// The errors returned by fn are joined, and wrapped with the project key. A failing
+// project does not cancel the others.
+func (p *ClientPool) ForEach(ctx context.Context, parallelism int, fn func(ctx context.Context, project string, client *Client) error) error {
+	p.mu.Lock()
+	clients := make(map[string]*Client, len(p.clients))
+	for project, client := range p.clients {
+		clients[project] = client
+	}
+	p.mu.Unlock()
+
+	if parallelism < 1 {
+		parallelism = max(len(clients), 1)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, parallelism)
+
+	for project, client := range clients {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("project %s: %w", project, ctx.Err()))
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			if err := fn(ctx, project, client); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// CollectPool calls fn concurrently for every project in the pool, with at most
+// parallelism calls in flight, and returns the results per project.
+//
+// Results of the failing projects are missing from the returned map, the errors are
+// joined and wrapped with the project key.
+//
+//	servers, err := hcloud.CollectPool(ctx, pool, 4, func(ctx context.Context, client *hcloud.Client) ([]*hcloud.Server, error) {
+//		return client.Server.All(ctx)
+//	})
+func CollectPool[T any](ctx context.Context, pool *ClientPool, parallelism int, fn func(ctx context.Context, client *Client) ([]T, error)) (map[string][]T, error) {
+	var mu sync.Mutex
+	result := make(map[string][]T)
+
+	err := pool.ForEach(ctx, parallelism, func(ctx context.Context, project string, client *Client) error {
+		items, err := fn(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		result[project] = items
+		mu.Unlock()
+		return nil
+	})
+
+	return result, err
+}
+
+// withProject configures a [Client] of a [ClientPool]. The client gets its own
+// [http.Client], sharing the configured transport, and its own [RateLimiter]. Its
+// metrics and log records are labeled with the project key.
+func withProject(project string) ClientOption {
+	return func(client *Client) {
+		httpClient := *client.httpClient
+		client.httpClient = &httpClient
+
+		if client.rateLimiter != nil {
+			client.rateLimiter = NewRateLimiter()
+		}
+
+		if client.instrumentationRegistry != nil {
+			client.instrumentationRegistry = prometheus.WrapRegistererWith(
+				prometheus.Labels{"project": project},
+				client.instrumentationRegistry,
+			)
+		}
+		if client.logger != nil {
+			client.logger = client.logger.With("project", project)
+		}
+	}
+}
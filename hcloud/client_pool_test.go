@@ -0,0 +1,149 @@
+package hcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientPool(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		tokens []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		tokens = append(tokens, token)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if token == "invalid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"code":"unauthorized","message":"unable to authenticate"}}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"servers":[{"id":1,"name":"%s"}],"meta":{"pagination":{"page":1}}}`, token)
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	pool := NewClientPool(
+		WithEndpoint(server.URL),
+		WithInstrumentation(registry),
+		WithRetryOpts(RetryOpts{MaxRetries: 0}),
+	)
+	ctx := context.Background()
+
+	a := pool.Client("a", "token-a")
+	b := pool.Client("b", "token-b")
+	assert.NotSame(t, a, b)
+	assert.Same(t, a, pool.Client("a", "token-a"))
+	assert.Same(t, b, pool.Get("b"))
+	assert.Nil(t, pool.Get("c"))
+	assert.Equal(t, []string{"a", "b"}, pool.Projects())
+
+	t.Run("collect", func(t *testing.T) {
+		servers, err := CollectPool(ctx, pool, 1, func(ctx context.Context, client *Client) ([]*Server, error) {
+			return client.Server.All(ctx)
+		})
+		require.NoError(t, err)
+		require.Len(t, servers, 2)
+		assert.Equal(t, "token-a", servers["a"][0].Name)
+		assert.Equal(t, "token-b", servers["b"][0].Name)
+	})
+
+	t.Run("rotate token", func(t *testing.T) {
+		assert.True(t, pool.SetToken("a", "token-a2"))
+		assert.False(t, pool.SetToken("c", "token-c"))
+
+		servers, err := a.Server.All(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-a2", servers[0].Name)
+
+		// The token is only used to create the client.
+		assert.Same(t, a, pool.Client("a", "token-a3"))
+		servers, err = a.Server.All(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-a2", servers[0].Name)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pool.SetToken("b", "invalid")
+
+		servers, err := CollectPool(ctx, pool, 0, func(ctx context.Context, client *Client) ([]*Server, error) {
+			return client.Server.All(ctx)
+		})
+		require.EqualError(t, err, "project b: unable to authenticate (unauthorized)")
+		assert.Len(t, servers, 1)
+		assert.Contains(t, servers, "a")
+	})
+
+	t.Run("parallelism", func(t *testing.T) {
+		for i := range 10 {
+			pool.Client(fmt.Sprintf("p%d", i), "token")
+		}
+
+		var inFlight, maxInFlight atomic.Int64
+		err := pool.ForEach(ctx, 3, func(_ context.Context, _ string, _ *Client) error {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				previous := maxInFlight.Load()
+				if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, maxInFlight.Load(), int64(3))
+
+		pool.Remove("p0")
+		assert.Len(t, pool.Projects(), 11)
+	})
+
+	t.Run("metrics per project", func(t *testing.T) {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		projects := map[string]bool{}
+		for _, family := range families {
+			if family.GetName() != "hcloud_api_requests_total" {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				for _, label := range m.GetLabel() {
+					if label.GetName() == "project" {
+						projects[label.GetValue()] = true
+					}
+				}
+			}
+		}
+		assert.Equal(t, map[string]bool{"a": true, "b": true}, projects)
+	})
+}
+
+func TestClientPoolRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter()
+	pool := NewClientPool(WithRateLimiter(limiter))
+
+	a := pool.Client("a", "token-a")
+	b := pool.Client("b", "token-b")
+	require.NotNil(t, a.rateLimiter)
+	require.NotNil(t, b.rateLimiter)
+	assert.NotSame(t, limiter, a.rateLimiter)
+	assert.NotSame(t, a.rateLimiter, b.rateLimiter)
+
+	pool = NewClientPool()
+	assert.Nil(t, pool.Client("a", "token-a").rateLimiter)
+}
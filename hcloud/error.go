@@ -138,6 +138,43 @@ func (e Error) Response() *Response {
 	return e.response
 }
 
+// InvalidInputDetails returns the [Error.Details] of an 'invalid_input' error, and
+// whether the error has such details.
+func (e Error) InvalidInputDetails() (ErrorDetailsInvalidInput, bool) {
+	details, ok := e.Details.(ErrorDetailsInvalidInput)
+	return details, ok
+}
+
+// DeprecatedAPIEndpointDetails returns the [Error.Details] of a
+// 'deprecated_api_endpoint' error, and whether the error has such details.
+func (e Error) DeprecatedAPIEndpointDetails() (ErrorDetailsDeprecatedAPIEndpoint, bool) {
+	details, ok := e.Details.(ErrorDetailsDeprecatedAPIEndpoint)
+	return details, ok
+}
+
+// As fills the target with the typed [Error.Details], so they can be extracted using
+// [errors.As]:
+//
+//	var details hcloud.ErrorDetailsInvalidInput
+//	if errors.As(err, &details) { ... }
+func (e Error) As(target any) bool {
+	switch target := target.(type) {
+	case *ErrorDetailsInvalidInput:
+		details, ok := e.InvalidInputDetails()
+		if ok {
+			*target = details
+		}
+		return ok
+	case *ErrorDetailsDeprecatedAPIEndpoint:
+		details, ok := e.DeprecatedAPIEndpointDetails()
+		if ok {
+			*target = details
+		}
+		return ok
+	}
+	return false
+}
+
 // LogValue implements [slog.LogValuer] for a [Error].
 func (e Error) LogValue() slog.Value {
 	attrs := []slog.Attr{
@@ -179,6 +216,14 @@ type ErrorDetailsInvalidInput struct {
 	Fields []ErrorDetailsInvalidInputField
 }
 
+func (d ErrorDetailsInvalidInput) Error() string {
+	fields := make([]string, 0, len(d.Fields))
+	for _, field := range d.Fields {
+		fields = append(fields, fmt.Sprintf("%s: %s", field.Name, strings.Join(field.Messages, ", ")))
+	}
+	return "invalid input: " + strings.Join(fields, "; ")
+}
+
 // ErrorDetailsInvalidInputField contains the validation errors reported on a field.
 type ErrorDetailsInvalidInputField struct {
 	Name     string
@@ -190,6 +235,10 @@ type ErrorDetailsDeprecatedAPIEndpoint struct {
 	Announcement string
 }
 
+func (d ErrorDetailsDeprecatedAPIEndpoint) Error() string {
+	return "deprecated API endpoint: " + d.Announcement
+}
+
 // IsError returns whether err is an API error with one of the given error codes.
 func IsError(err error, code ...ErrorCode) bool {
 	var apiErr Error
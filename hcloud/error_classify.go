@@ -0,0 +1,216 @@
+package hcloud
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ErrorCategory classifies the errors returned from the API.
+type ErrorCategory string
+
+// Error categories.
+const (
+	ErrorCategoryUnknown      ErrorCategory = "unknown"       // The error is not classified
+	ErrorCategoryTransient    ErrorCategory = "transient"     // The operation may succeed when retried after a short delay
+	ErrorCategoryInvalidInput ErrorCategory = "invalid_input" // The request is invalid, it must be changed before being retried
+	ErrorCategoryAuth         ErrorCategory = "auth"          // The token is invalid or lacks permissions
+	ErrorCategoryCapacity     ErrorCategory = "capacity"      // A limit of the project or a capacity of the platform is reached
+	ErrorCategoryProtection   ErrorCategory = "protection"    // The resource is protected against the operation
+	ErrorCategoryNotFound     ErrorCategory = "not_found"     // The resource does not exist
+	ErrorCategoryPrecondition ErrorCategory = "precondition"  // The resource is not in a state allowing the operation
+)
+
+// Category returns the [ErrorCategory] of the error code.
+func (c ErrorCode) Category() ErrorCategory {
+	switch c {
+	case ErrorCodeConflict,
+		ErrorCodeLocked,
+		ErrorCodeRobotUnavailable,
+		ErrorCodeTimeout,
+		ErrorCodeBadGateway,
+		ErrorCodeRateLimitExceeded,
+		ErrorCodeMaintenance:
+		return ErrorCategoryTransient
+
+	case ErrorCodeInvalidInput,
+		ErrorCodeJSONError,
+		ErrorCodeUniquenessError,
+		ErrorUnsupportedError,
+		ErrorDeprecatedAPIEndpoint,
+		ErrorCodeInvalidServerType,
+		ErrorCodeNetworksOverlap,
+		ErrorCodePrimaryIPDatacenterMismatch,
+		ErrorCodePrimaryIPVersionMismatch,
+		ErrorCodeIPNotOwned,
+		ErrorCodeSourcePortAlreadyUsed,
+		ErrorCodeCloudResourceIPNotAllowed,
+		ErrorCodeTargetAlreadyDefined,
+		ErrorCodeInvalidLoadBalancerType,
+		ErrorCodeIncompatibleNetworkType,
+		ErrorCodeServerAlreadyAdded,
+		ErrorCodeVSwitchAlreadyUsed:
+		return ErrorCategoryInvalidInput
+
+	case ErrorCodeUnauthorized,
+		ErrorCodeForbidden,
+		ErrorCodeTokenReadonly:
+		return ErrorCategoryAuth
+
+	case ErrorCodeResourceLimitExceeded,
+		ErrorCodeResourceUnavailable,
+		ErrorCodePlacementError,
+		ErrorCodeIPNotAvailable,
+		ErrorCodeNoSubnetAvailable,
+		ErrorCodeNoSpaceLeftInLocation,
+		ErrorCodeCATooManyAuthorizationsFailedRecently,
+		ErrorCodeCATooManyCertificatedIssuedForRegisteredDomain,
+		ErrorCodeCATooManyDuplicateCertificates:
+		return ErrorCategoryCapacity
+
+	case ErrorCodeProtected,
+		ErrorCodeResourceLocked,
+		ErrorCodeFirewallManagedByLabelSelector:
+		return ErrorCategoryProtection
+
+	case ErrorCodeNotFound,
+		ErrorCodeFirewallResourceNotFound,
+		ErrorCodeDNSZoneNotFound:
+		return ErrorCategoryNotFound
+
+	case ErrorCodeServerNotStopped,
+		ErrorCodeServerAlreadyAttached,
+		ErrorCodePrimaryIPAssigned,
+		ErrorCodePrimaryIPAlreadyAssigned,
+		ErrorCodeServerHasIPv4,
+		ErrorCodeServerHasIPv6,
+		ErrorCodeServerIsLoadBalancerTarget,
+		ErrorCodeServerNotAttachedToNetwork,
+		ErrorCodeLoadBalancerAlreadyAttached,
+		ErrorCodeTargetsWithoutUsePrivateIP,
+		ErrorCodeLoadBalancerNotAttachedToNetwork,
+		ErrorCodeMissingIPv4,
+		ErrorCodeVolumeAlreadyAttached,
+		ErrorCodeFirewallAlreadyApplied,
+		ErrorCodeResourceInUse,
+		ErrorCodePrivateNetOnlyServer,
+		ErrorCodeCAARecordDoesNotAllowCA,
+		ErrorCodeCADNSValidationFailed,
+		ErrorCodeCloudNotVerifyDomainDelegatedToZone,
+		ErrorCodeDNSZoneIsSecondaryZone:
+		return ErrorCategoryPrecondition
+
+	default:
+		return ErrorCategoryUnknown
+	}
+}
+
+// ErrorCategoryOf returns the [ErrorCategory] of an API error. Network timeouts and
+// the HTTP 502 and 504 status codes are [ErrorCategoryTransient], other errors are
+// [ErrorCategoryUnknown].
+func ErrorCategoryOf(err error) ErrorCategory {
+	return errorCategory(nil, err)
+}
+
+func errorCategory(resp *Response, err error) ErrorCategory {
+	var apiErr Error
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.Code.Category()
+	case errors.Is(err, ErrStatusCode):
+		switch statusCodeOf(resp, err) {
+		case http.StatusBadGateway, http.StatusGatewayTimeout:
+			return ErrorCategoryTransient
+		}
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrorCategoryTransient
+		}
+	}
+	return ErrorCategoryUnknown
+}
+
+// statusCodeOf returns the status code of a response without an API error.
+func statusCodeOf(resp *Response, err error) int {
+	var statusErr statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode
+	}
+	if resp != nil && resp.Response != nil {
+		return resp.StatusCode
+	}
+	return 0
+}
+
+// IsRetryable returns whether the failed operation may succeed when retried after a
+// short delay, see [ErrorCategoryTransient].
+//
+// The locked API error is retryable, but it is not retried automatically by the
+// client, see [TransientRetryPolicy] and [LockedRetryOpts].
+func IsRetryable(err error) bool {
+	return ErrorCategoryOf(err) == ErrorCategoryTransient
+}
+
+// IsConflict returns whether the operation failed because the resource was modified
+// concurrently, or is busy with another action.
+func IsConflict(err error) bool {
+	return IsError(err, ErrorCodeConflict, ErrorCodeLocked)
+}
+
+// IsQuotaError returns whether the operation failed because a limit of the project
+// (e.g. resource limit, rate limit) or of the certificate authority is reached.
+func IsQuotaError(err error) bool {
+	return IsError(err,
+		ErrorCodeResourceLimitExceeded,
+		ErrorCodeRateLimitExceeded,
+		ErrorCodeCATooManyAuthorizationsFailedRecently,
+		ErrorCodeCATooManyCertificatedIssuedForRegisteredDomain,
+		ErrorCodeCATooManyDuplicateCertificates,
+	)
+}
+
+// RetryAdvice describes whether and when a failed operation may be retried.
+type RetryAdvice struct {
+	// Retryable is true when the operation may succeed when retried.
+	Retryable bool
+	// Backoff is the suggested delay before retrying the operation.
+	Backoff time.Duration
+}
+
+// AdviseRetry returns whether and when the operation that failed with err may be
+// retried. For rate limited requests, the suggested backoff lasts until the rate
+// limit allows a new request.
+func AdviseRetry(err error) RetryAdvice {
+	if !IsRetryable(err) {
+		return RetryAdvice{}
+	}
+
+	var apiErr Error
+	if !errors.As(err, &apiErr) {
+		return RetryAdvice{Retryable: true, Backoff: 2 * time.Second}
+	}
+
+	switch apiErr.Code {
+	case ErrorCodeRateLimitExceeded:
+		backoff := time.Second
+		if resp := apiErr.Response(); resp != nil && !resp.Meta.Ratelimit.Reset.IsZero() && resp.Meta.Ratelimit.Limit > 0 {
+			// The rate limit refills linearly, one request is available after a
+			// fraction of the reset duration.
+			if wait := time.Until(resp.Meta.Ratelimit.Reset) / time.Duration(resp.Meta.Ratelimit.Limit); wait > backoff {
+				backoff = wait
+			}
+		}
+		return RetryAdvice{Retryable: true, Backoff: backoff}
+	case ErrorCodeConflict:
+		return RetryAdvice{Retryable: true, Backoff: time.Second}
+	case ErrorCodeRobotUnavailable:
+		return RetryAdvice{Retryable: true, Backoff: 10 * time.Second}
+	case ErrorCodeMaintenance:
+		return RetryAdvice{Retryable: true, Backoff: time.Minute}
+	default:
+		return RetryAdvice{Retryable: true, Backoff: 2 * time.Second}
+	}
+}
@@ -0,0 +1,148 @@
+package hcloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/mockutil"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestErrorCategoryOf(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want ErrorCategory
+	}{
+		{name: "conflict", err: Error{Code: ErrorCodeConflict}, want: ErrorCategoryTransient},
+		{name: "locked", err: Error{Code: ErrorCodeLocked}, want: ErrorCategoryTransient},
+		{name: "robot unavailable", err: Error{Code: ErrorCodeRobotUnavailable}, want: ErrorCategoryTransient},
+		{name: "invalid input", err: Error{Code: ErrorCodeInvalidInput}, want: ErrorCategoryInvalidInput},
+		{name: "uniqueness error", err: Error{Code: ErrorCodeUniquenessError}, want: ErrorCategoryInvalidInput},
+		{name: "unauthorized", err: Error{Code: ErrorCodeUnauthorized}, want: ErrorCategoryAuth},
+		{name: "token readonly", err: Error{Code: ErrorCodeTokenReadonly}, want: ErrorCategoryAuth},
+		{name: "resource limit exceeded", err: Error{Code: ErrorCodeResourceLimitExceeded}, want: ErrorCategoryCapacity},
+		{name: "protected", err: Error{Code: ErrorCodeProtected}, want: ErrorCategoryProtection},
+		{name: "not found", err: Error{Code: ErrorCodeNotFound}, want: ErrorCategoryNotFound},
+		{name: "server not stopped", err: Error{Code: ErrorCodeServerNotStopped}, want: ErrorCategoryPrecondition},
+		{name: "unknown code", err: Error{Code: "something_new"}, want: ErrorCategoryUnknown},
+		{name: "wrapped", err: fmt.Errorf("deleting server: %w", Error{Code: ErrorCodeConflict}), want: ErrorCategoryTransient},
+		{name: "http 502", err: fmt.Errorf("hcloud: %w", statusCodeError{502}), want: ErrorCategoryTransient},
+		{name: "http 503", err: fmt.Errorf("hcloud: %w", statusCodeError{503}), want: ErrorCategoryUnknown},
+		{name: "network timeout", err: timeoutError{}, want: ErrorCategoryTransient},
+		{name: "random error", err: errors.New("random error"), want: ErrorCategoryUnknown},
+		{name: "nil", err: nil, want: ErrorCategoryUnknown},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, ErrorCategoryOf(testCase.err))
+			assert.Equal(t, testCase.want == ErrorCategoryTransient, IsRetryable(testCase.err))
+		})
+	}
+}
+
+func TestIsConflict(t *testing.T) {
+	assert.True(t, IsConflict(Error{Code: ErrorCodeConflict}))
+	assert.True(t, IsConflict(Error{Code: ErrorCodeLocked}))
+	assert.False(t, IsConflict(Error{Code: ErrorCodeUniquenessError}))
+	assert.False(t, IsConflict(errors.New("random error")))
+}
+
+func TestIsQuotaError(t *testing.T) {
+	assert.True(t, IsQuotaError(Error{Code: ErrorCodeResourceLimitExceeded}))
+	assert.True(t, IsQuotaError(Error{Code: ErrorCodeRateLimitExceeded}))
+	assert.False(t, IsQuotaError(Error{Code: ErrorCodeNoSpaceLeftInLocation}))
+}
+
+func TestAdviseRetry(t *testing.T) {
+	assert.Equal(t, RetryAdvice{}, AdviseRetry(Error{Code: ErrorCodeInvalidInput}))
+	assert.Equal(t, RetryAdvice{Retryable: true, Backoff: time.Second}, AdviseRetry(Error{Code: ErrorCodeConflict}))
+	assert.Equal(t, RetryAdvice{Retryable: true, Backoff: 2 * time.Second}, AdviseRetry(Error{Code: ErrorCodeLocked}))
+	assert.Equal(t, RetryAdvice{Retryable: true, Backoff: 2 * time.Second}, AdviseRetry(timeoutError{}))
+
+	resp := fakeResponse(t, 429, `{"error":{"code":"rate_limit_exceeded","message":"limit reached"}}`, true)
+	resp.Meta.Ratelimit = Ratelimit{Limit: 10, Remaining: 0, Reset: time.Now().Add(time.Minute)}
+	err := errorFromBody(resp)
+
+	advice := AdviseRetry(err)
+	assert.True(t, advice.Retryable)
+	assert.InDelta(t, 6*time.Second, advice.Backoff, float64(time.Second))
+}
+
+func TestErrorDetails(t *testing.T) {
+	resp := fakeResponse(t, 422, `{
+		"error": {
+			"code": "invalid_input",
+			"message": "invalid input",
+			"details": { "fields": [{ "name": "name", "messages": ["is too long"] }] }
+		}
+	}`, true)
+	err := fmt.Errorf("creating server: %w", errorFromBody(resp))
+
+	var apiErr Error
+	require.ErrorAs(t, err, &apiErr)
+
+	invalidInput, ok := apiErr.InvalidInputDetails()
+	require.True(t, ok)
+	assert.Equal(t, "name", invalidInput.Fields[0].Name)
+	assert.Equal(t, []string{"is too long"}, invalidInput.Fields[0].Messages)
+
+	_, ok = apiErr.DeprecatedAPIEndpointDetails()
+	assert.False(t, ok)
+
+	var details ErrorDetailsInvalidInput
+	require.ErrorAs(t, err, &details)
+	assert.Equal(t, invalidInput, details)
+	assert.EqualError(t, details, "invalid input: name: is too long")
+
+	var deprecated ErrorDetailsDeprecatedAPIEndpoint
+	assert.NotErrorAs(t, err, &deprecated)
+
+	// The details are not matched as errors
+	assert.True(t, IsError(err, ErrorCodeInvalidInput))
+	assert.NoError(t, errors.Unwrap(apiErr))
+}
+
+func TestRetryPolicyOption(t *testing.T) {
+	server := mockutil.NewServer(t, []mockutil.Request{
+		{
+			Method: "POST", Path: "/servers/1/actions/poweron",
+			Status:  503,
+			JSONRaw: `{"error":{"code":"robot_unavailable","message":"robot is unavailable"}}`,
+		},
+		{
+			Method: "POST", Path: "/servers/1/actions/poweron",
+			Status:  201,
+			JSONRaw: `{"action":{"id":13,"status":"running"}}`,
+		},
+	})
+
+	client := NewClient(
+		WithEndpoint(server.URL),
+		WithRetryOpts(RetryOpts{BackoffFunc: ConstantBackoff(0), MaxRetries: 3, Policy: TransientRetryPolicy}),
+	)
+
+	action, _, err := client.Server.Poweron(context.Background(), &Server{ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), action.ID)
+}
+
+func TestTransientRetryPolicy(t *testing.T) {
+	assert.True(t, TransientRetryPolicy(nil, Error{Code: ErrorCodeRobotUnavailable}))
+	assert.False(t, TransientRetryPolicy(nil, Error{Code: ErrorCodeLocked}))
+	assert.True(t, TransientRetryPolicy(fakeResponse(t, http.StatusGatewayTimeout, "", false), fmt.Errorf("%w %d", ErrStatusCode, 504)))
+	assert.False(t, TransientRetryPolicy(nil, Error{Code: ErrorCodeProtected}))
+	assert.False(t, TransientRetryPolicy(nil, nil))
+}
@@ -0,0 +1,387 @@
+// Package cassette records the HTTP exchanges with the API to a file, and replays them
+// offline.
+//
+// The [Transport] is used through a custom [http.Client]:
+//
+//	transport, err := cassette.New("testdata/cassette.json", cassette.ModeRecord)
+//	client := hcloud.NewClient(hcloud.WithHTTPClient(&http.Client{Transport: transport}))
+//	// ...
+//	err = transport.Save()
+//
+// The Authorization header and sensitive JSON fields (e.g. root passwords) are
+// scrubbed before the exchanges are written to the cassette file.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/internal/redact"
+)
+
+// Redacted replaces the scrubbed values.
+const Redacted = redact.Redacted
+
+const headerCorrelationID = "X-Correlation-Id"
+
+// DefaultScrubbedFields are the JSON fields scrubbed from the request and response
+// bodies by default, the same fields are redacted by the debug logger of the client.
+var DefaultScrubbedFields = slices.Clone(redact.SensitiveFields)
+
+// Mode defines whether a [Transport] records or replays the exchanges.
+type Mode int
+
+const (
+	// ModeRecord sends the requests to the API, and records the exchanges.
+	ModeRecord Mode = iota
+	// ModeReplay serves the exchanges from the cassette, without network access.
+	ModeReplay
+)
+
+// Cassette holds the recorded exchanges.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	// CorrelationID is the X-Correlation-Id of the response, it identifies the
+	// request in the API.
+	CorrelationID string   `json:"correlation_id,omitempty"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// MatchOpts defines the parts of the requests compared to find the recorded
+// interaction to replay.
+type MatchOpts struct {
+	Method bool
+	Path   bool
+	Query  bool
+	// JSONBody compares the request bodies semantically, the order of the fields and
+	// the formatting are not significant.
+	JSONBody bool
+}
+
+// DefaultMatchOpts compares every part of the requests.
+var DefaultMatchOpts = MatchOpts{Method: true, Path: true, Query: true, JSONBody: true}
+
+// Option configures a [Transport].
+type Option func(*Transport)
+
+// WithMatchOpts configures how requests are matched in [ModeReplay].
+func WithMatchOpts(opts MatchOpts) Option {
+	return func(t *Transport) {
+		t.match = opts
+	}
+}
+
+// WithScrubbedFields configures the JSON fields scrubbed from the bodies, replacing
+// the [DefaultScrubbedFields].
+func WithScrubbedFields(fields ...string) Option {
+	return func(t *Transport) {
+		t.scrubbedFields = fields
+	}
+}
+
+// WithTransport configures the [http.RoundTripper] used in [ModeRecord], defaults to
+// [http.DefaultTransport].
+func WithTransport(next http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.next = next
+	}
+}
+
+// Transport is a [http.RoundTripper] that records or replays the exchanges with the
+// API, depending on its [Mode].
+//
+// In [ModeRecord], the exchanges are kept in memory until the cassette file is written
+// using [Transport.Save]. In [ModeReplay], each recorded interaction is replayed at most once, in the recorded
+// order, so repeated requests (e.g. action polling) receive the successive recorded
+// responses.
+//
+// A Transport must be created using the [New] function.
+type Transport struct {
+	path           string
+	mode           Mode
+	next           http.RoundTripper
+	match          MatchOpts
+	scrubbedFields []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a new [Transport] using the cassette file at path. In [ModeReplay],
+// the cassette file must exist. In [ModeRecord], any existing cassette file is
+// overwritten by [Transport.Save].
+func New(path string, mode Mode, opts ...Option) (*Transport, error) {
+	t := &Transport{
+		path:           path,
+		mode:           mode,
+		next:           http.DefaultTransport,
+		match:          DefaultMatchOpts,
+		scrubbedFields: DefaultScrubbedFields,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &t.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: invalid file %s: %w", path, err)
+		}
+		t.used = make([]bool, len(t.cassette.Interactions))
+	}
+
+	return t, nil
+}
+
+// Cassette returns a copy of the interactions recorded or loaded by the [Transport].
+func (t *Transport) Cassette() Cassette {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return Cassette{Interactions: slices.Clone(t.cassette.Interactions)}
+}
+
+// Unused returns the interactions that were not replayed.
+func (t *Transport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var result []Interaction
+	for i, used := range t.used {
+		if !used {
+			result = append(result, t.cassette.Interactions[i])
+		}
+	}
+	return result
+}
+
+// Save writes the recorded interactions to the cassette file. The file is replaced
+// atomically, an existing cassette file is kept if the write fails. Save does nothing
+// in [ModeReplay].
+func (t *Transport) Save() error {
+	if t.mode == ModeReplay {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	if err := writeFile(t.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == ModeReplay {
+		return t.replay(req, reqBody)
+	}
+	return t.record(req, reqBody)
+}
+
+func (t *Transport) record(req *http.Request, reqBody []byte) (*http.Response, error) {
+	if reqBody != nil {
+		// The request must not be modified, the consumed body is replaced on a clone.
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		CorrelationID: resp.Header.Get(headerCorrelationID),
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: scrubHeader(req.Header),
+			Body:   string(redact.JSON(reqBody, t.scrubbedFields)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(redact.JSON(respBody, t.scrubbedFields)),
+		},
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.Interactions = append(t.cassette.Interactions, interaction)
+	t.used = append(t.used, true)
+
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, reqBody []byte) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, interaction := range t.cassette.Interactions {
+		if t.used[i] || !t.matches(interaction.Request, req, reqBody) {
+			continue
+		}
+		t.used[i] = true
+
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		if header.Get(headerCorrelationID) == "" && interaction.CorrelationID != "" {
+			header.Set(headerCorrelationID, interaction.CorrelationID)
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("cassette: no recorded interaction for %s %s", req.Method, req.URL.String())
+}
+
+func (t *Transport) matches(recorded Request, req *http.Request, reqBody []byte) bool {
+	if t.match.Method && recorded.Method != req.Method {
+		return false
+	}
+
+	if t.match.Path || t.match.Query {
+		recordedURL, err := req.URL.Parse(recorded.URL)
+		if err != nil {
+			return false
+		}
+		if t.match.Path && recordedURL.Path != req.URL.Path {
+			return false
+		}
+		if t.match.Query && !reflect.DeepEqual(recordedURL.Query(), req.URL.Query()) {
+			return false
+		}
+	}
+
+	if t.match.JSONBody {
+		// The recorded body is scrubbed, the incoming body must be scrubbed the same way.
+		if !equalJSON([]byte(recorded.Body), redact.JSON(reqBody, t.scrubbedFields)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readBody reads and closes the body, it returns nil if there is no body.
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil || body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// writeFile writes the data to a temporary file renamed to path, so path is never
+// left partially written.
+func writeFile(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func scrubHeader(header http.Header) http.Header {
+	header = header.Clone()
+	if header.Get("Authorization") != "" {
+		header.Set("Authorization", Redacted)
+	}
+	return header
+}
+
+// equalJSON compares two JSON documents semantically, falling back to a byte
+// comparison for invalid JSON.
+func equalJSON(a, b []byte) bool {
+	if len(bytes.TrimSpace(a)) == 0 || len(bytes.TrimSpace(b)) == 0 {
+		return len(bytes.TrimSpace(a)) == len(bytes.TrimSpace(b))
+	}
+
+	var va, vb any
+	errA := json.Unmarshal(a, &va)
+	errB := json.Unmarshal(b, &vb)
+	if err := errors.Join(errA, errB); err != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
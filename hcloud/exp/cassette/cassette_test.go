@@ -0,0 +1,175 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func withTransport(transport http.RoundTripper) hcloud.ClientOption {
+	return hcloud.WithHTTPClient(&http.Client{Transport: transport})
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	run := func(client *hcloud.Client) (*hcloud.Server, string) {
+		result, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+			Name:       "server",
+			ServerType: &hcloud.ServerType{Name: "cpx22"},
+			Image:      &hcloud.Image{Name: "debian-13"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+		server, _, err := client.Server.GetByID(ctx, result.Server.ID)
+		require.NoError(t, err)
+		return server, result.RootPassword
+	}
+
+	// Record
+	api := fakeapi.NewServer(t)
+	recorder, err := New(path, ModeRecord)
+	require.NoError(t, err)
+
+	recorded, rootPassword := run(api.Client(hcloud.WithToken("secret-token"), withTransport(recorder)))
+	assert.NotEmpty(t, rootPassword)
+	assert.NotEqual(t, Redacted, rootPassword)
+
+	// The cassette file is only written on save.
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, recorder.Save())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.NotContains(t, string(data), rootPassword)
+	assert.Contains(t, string(data), Redacted)
+
+	// Replay, the endpoint is never reached.
+	api.Close()
+	replayer, err := New(path, ModeReplay)
+	require.NoError(t, err)
+
+	replayed, rootPassword := run(api.Client(withTransport(replayer)))
+	assert.Equal(t, Redacted, rootPassword)
+	assert.Equal(t, recorded.ID, replayed.ID)
+	assert.Equal(t, recorded.Status, replayed.Status)
+	assert.Empty(t, replayer.Unused())
+
+	// All interactions were replayed.
+	_, _, err = api.Client(withTransport(replayer)).Server.GetByID(ctx, recorded.ID)
+	require.ErrorContains(t, err, "cassette: no recorded interaction for GET")
+}
+
+func TestReplayMatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"interactions": [
+			{
+				"request": { "method": "POST", "url": "http://example.com/v1/ssh_keys", "body": "{\"name\":\"a\",\"public_key\":\"key\"}" },
+				"response": { "status_code": 201, "header": { "Content-Type": ["application/json"] }, "body": "{\"ssh_key\":{\"id\":1,\"name\":\"a\"}}" }
+			},
+			{
+				"request": { "method": "POST", "url": "http://example.com/v1/ssh_keys", "body": "{\"name\":\"b\",\"public_key\":\"key\"}" },
+				"response": { "status_code": 201, "header": { "Content-Type": ["application/json"] }, "body": "{\"ssh_key\":{\"id\":2,\"name\":\"b\"}}" }
+			}
+		]
+	}`), 0o600))
+
+	t.Run("json body", func(t *testing.T) {
+		replayer, err := New(path, ModeReplay)
+		require.NoError(t, err)
+		client := hcloud.NewClient(hcloud.WithEndpoint("http://example.com/v1"), withTransport(replayer))
+
+		sshKey, _, err := client.SSHKey.Create(context.Background(), hcloud.SSHKeyCreateOpts{Name: "b", PublicKey: "key"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), sshKey.ID)
+		assert.Len(t, replayer.Unused(), 1)
+	})
+
+	t.Run("without json body", func(t *testing.T) {
+		replayer, err := New(path, ModeReplay, WithMatchOpts(MatchOpts{Method: true, Path: true}))
+		require.NoError(t, err)
+		client := hcloud.NewClient(hcloud.WithEndpoint("http://example.com/v1"), withTransport(replayer))
+
+		sshKey, _, err := client.SSHKey.Create(context.Background(), hcloud.SSHKeyCreateOpts{Name: "b", PublicKey: "key"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), sshKey.ID)
+	})
+}
+
+func TestReplayCorrelationID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Correlation-Id", "d4d2e4b5cc4f6c35")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"not_found","message":"server not found"}}`))
+	}))
+	defer api.Close()
+
+	recorder, err := New(path, ModeRecord)
+	require.NoError(t, err)
+	_, _, err = hcloud.NewClient(hcloud.WithEndpoint(api.URL), hcloud.WithToken("secret-token"), withTransport(recorder)).Server.DeleteWithResult(context.Background(), &hcloud.Server{ID: 1})
+	require.EqualError(t, err, "server not found (not_found, d4d2e4b5cc4f6c35)")
+
+	cassette := recorder.Cassette()
+	require.Len(t, cassette.Interactions, 1)
+	assert.Equal(t, "d4d2e4b5cc4f6c35", cassette.Interactions[0].CorrelationID)
+	assert.Equal(t, []string{Redacted}, cassette.Interactions[0].Request.Header["Authorization"])
+	require.NoError(t, recorder.Save())
+
+	replayer, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	_, _, err = hcloud.NewClient(hcloud.WithEndpoint(api.URL), withTransport(replayer)).Server.DeleteWithResult(context.Background(), &hcloud.Server{ID: 1})
+	require.EqualError(t, err, "server not found (not_found, d4d2e4b5cc4f6c35)")
+}
+
+func TestDefaultScrubbedFields(t *testing.T) {
+	assert.Subset(t, DefaultScrubbedFields, []string{"token", "password", "root_password", "private_key", "tsig_key"})
+}
+
+func TestNewMissingFile(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+	require.Error(t, err)
+}
+
+func TestRecordDoesNotModifyRequest(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer api.Close()
+
+	recorder, err := New(filepath.Join(t.TempDir(), "cassette.json"), ModeRecord)
+	require.NoError(t, err)
+
+	body := io.NopCloser(strings.NewReader(`{"name":"server"}`))
+	req, err := http.NewRequest(http.MethodPost, api.URL, body)
+	require.NoError(t, err)
+
+	resp, err := recorder.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.True(t, req.Body == body, "the request body was replaced")
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"server"}`, string(respBody))
+	assert.JSONEq(t, `{"name":"server"}`, recorder.Cassette().Interactions[0].Request.Body)
+}
@@ -0,0 +1,70 @@
+package certificateutil
+
+import (
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Diagnostic explains the error of a managed certificate, and how to resolve it.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Diagnostic struct {
+	Code    hcloud.ErrorCode
+	Message string
+	// Hint is the action required to resolve the error.
+	Hint string
+	// Transient reports whether retrying the issuance may succeed without any change,
+	// for example once a rate limit of the certificate authority is lifted.
+	Transient bool
+}
+
+func (d Diagnostic) String() string {
+	return string(d.Code) + ": " + d.Hint
+}
+
+var diagnostics = map[hcloud.ErrorCode]Diagnostic{
+	hcloud.ErrorCodeCAARecordDoesNotAllowCA: {
+		Hint: "add a CAA record allowing letsencrypt.org to issue certificates for the domains, or remove the CAA records",
+	},
+	hcloud.ErrorCodeCADNSValidationFailed: {
+		Hint:      "make sure the domains are delegated to the Hetzner DNS name servers and the DNS records are propagated",
+		Transient: true,
+	},
+	hcloud.ErrorCodeCATooManyAuthorizationsFailedRecently: {
+		Hint:      "fix the DNS validation of the domains, and wait for the rate limit of the certificate authority to be lifted, usually an hour",
+		Transient: true,
+	},
+	hcloud.ErrorCodeCATooManyCertificatedIssuedForRegisteredDomain: {
+		Hint:      "wait for the rate limit of the certificate authority to be lifted, or reduce the number of certificates issued for the registered domain",
+		Transient: true,
+	},
+	hcloud.ErrorCodeCATooManyDuplicateCertificates: {
+		Hint:      "wait for the rate limit of the certificate authority to be lifted, or change the domains of the certificate",
+		Transient: true,
+	},
+	hcloud.ErrorCodeCloudNotVerifyDomainDelegatedToZone: {
+		Hint: "delegate the domains to the Hetzner DNS name servers of the zone",
+	},
+	hcloud.ErrorCodeDNSZoneNotFound: {
+		Hint: "create a DNS zone for the domains in the project",
+	},
+	hcloud.ErrorCodeDNSZoneIsSecondaryZone: {
+		Hint: "use a primary DNS zone for the domains, secondary zones can not be used to validate the domains",
+	},
+}
+
+// Diagnose returns the [Diagnostic] of an error reported in the
+// [hcloud.CertificateStatus] of a managed certificate.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Diagnose(err *hcloud.Error) Diagnostic {
+	diagnostic, ok := diagnostics[err.Code]
+	if !ok {
+		diagnostic = Diagnostic{
+			Hint:      "retry the issuance, and contact the support if the error persists",
+			Transient: true,
+		}
+	}
+	diagnostic.Code = err.Code
+	diagnostic.Message = err.Message
+	return diagnostic
+}
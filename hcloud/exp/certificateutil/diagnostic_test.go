@@ -0,0 +1,29 @@
+package certificateutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestDiagnose(t *testing.T) {
+	diagnostic := Diagnose(&hcloud.Error{
+		Code:    hcloud.ErrorCodeCAARecordDoesNotAllowCA,
+		Message: "CAA record does not allow certificate authority",
+	})
+	assert.Equal(t, hcloud.ErrorCodeCAARecordDoesNotAllowCA, diagnostic.Code)
+	assert.Equal(t, "CAA record does not allow certificate authority", diagnostic.Message)
+	assert.Contains(t, diagnostic.Hint, "CAA record")
+	assert.False(t, diagnostic.Transient)
+
+	diagnostic = Diagnose(&hcloud.Error{Code: hcloud.ErrorCodeCADNSValidationFailed})
+	assert.True(t, diagnostic.Transient)
+	assert.Contains(t, diagnostic.String(), "ca_dns_validation_failed: ")
+
+	diagnostic = Diagnose(&hcloud.Error{Code: "unknown_error", Message: "Unknown error"})
+	assert.Equal(t, hcloud.ErrorCode("unknown_error"), diagnostic.Code)
+	assert.Contains(t, diagnostic.Hint, "retry the issuance")
+	assert.True(t, diagnostic.Transient)
+}
@@ -0,0 +1,337 @@
+// Package certificateutil monitors the certificates of a project: certificates
+// expiring soon, and managed certificates whose issuance or renewal failed.
+//
+//	monitor := certificateutil.NewMonitor(client, certificateutil.MonitorOpts{})
+//	report, err := monitor.Scan(ctx)
+//	for _, certificate := range report.Certificates {
+//		fmt.Println(certificate)
+//	}
+//
+// The issuance of the failed managed certificates is retried automatically, with a
+// backoff between the retries of a certificate.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package certificateutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// DefaultExpiryWindow is the default duration before the expiry of a certificate
+// from which it is reported.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const DefaultExpiryWindow = 30 * 24 * time.Hour
+
+// DefaultMaxNonTransientRetries is the default maximum number of retries of the
+// issuance of a certificate whose [Diagnostic] is not transient.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const DefaultMaxNonTransientRetries = 3
+
+// MonitorOpts defines the options of a [Monitor].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type MonitorOpts struct {
+	// LabelSelector selects the certificates to scan, all certificates are scanned if
+	// empty.
+	LabelSelector string
+	// ExpiryWindow is the duration before the expiry of a certificate from which it is
+	// reported, defaults to [DefaultExpiryWindow].
+	ExpiryWindow time.Duration
+	// RetryBackoffFunc returns the delay before the next retry of the issuance of a
+	// failed certificate, given the number of retries already done. Defaults to an
+	// exponential backoff from 10 minutes up to 24 hours.
+	RetryBackoffFunc hcloud.BackoffFunc
+	// MaxNonTransientRetries is the maximum number of retries of the issuance of a
+	// failed certificate whose [Diagnostic] is not transient, defaults to
+	// [DefaultMaxNonTransientRetries]. Certificates with a transient error are retried
+	// until they are issued.
+	MaxNonTransientRetries int
+	// DisableRetry disables the automatic retry of the issuance of failed certificates.
+	DisableRetry bool
+	// Interval is the duration between two scans of [Monitor.Run], defaults to 1 hour.
+	Interval time.Duration
+}
+
+// Retry is a retry of the issuance of a failed managed certificate.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Retry struct {
+	// Attempt is the number of retries of the certificate, starting at 1.
+	Attempt int
+	// Action is the action of the retry, nil if the retry failed.
+	Action *hcloud.Action
+	Err    error
+}
+
+// CertificateReport describes a certificate requiring attention.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type CertificateReport struct {
+	Certificate *hcloud.Certificate
+
+	// Expiring reports whether the certificate expires within the expiry window, or
+	// is already expired.
+	Expiring bool
+	// ExpiresIn is the duration until the certificate expires, negative if the
+	// certificate is expired. It is zero if the certificate was not issued yet.
+	ExpiresIn time.Duration
+
+	// Failed reports whether the issuance or the renewal of the certificate failed.
+	Failed bool
+	// Diagnostic explains the error of the certificate, nil if the certificate has no
+	// error.
+	Diagnostic *Diagnostic
+	// Retry is the retry done during the scan, nil if the issuance was not retried.
+	Retry *Retry
+	// NextRetry is the earliest time of the next retry, zero if no retry is planned.
+	NextRetry time.Time
+
+	// LoadBalancers are the Load Balancers using the certificate.
+	LoadBalancers []*hcloud.LoadBalancer
+}
+
+func (r CertificateReport) String() string {
+	var problems []string
+	switch {
+	case r.Expiring && r.ExpiresIn < 0:
+		problems = append(problems, "expired")
+	case r.Expiring:
+		problems = append(problems, fmt.Sprintf("expires in %s", r.ExpiresIn.Round(time.Minute)))
+	}
+	if r.Failed {
+		problems = append(problems, "failed")
+	}
+	if r.Diagnostic != nil {
+		problems = append(problems, r.Diagnostic.String())
+	}
+
+	result := fmt.Sprintf("certificate %d (%s): %s", r.Certificate.ID, r.Certificate.Name, strings.Join(problems, ", "))
+	if len(r.LoadBalancers) > 0 {
+		names := make([]string, 0, len(r.LoadBalancers))
+		for _, loadBalancer := range r.LoadBalancers {
+			names = append(names, loadBalancer.Name)
+		}
+		result += " (used by load balancers " + strings.Join(names, ", ") + ")"
+	}
+	return result
+}
+
+// Report is the result of a scan of a [Monitor].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Report struct {
+	Time time.Time
+	// Scanned is the number of scanned certificates.
+	Scanned int
+	// Certificates holds the certificates requiring attention.
+	Certificates []CertificateReport
+}
+
+// Monitor scans the certificates of a project.
+//
+// A Monitor must be created using the [NewMonitor] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Monitor struct {
+	client *hcloud.Client
+	opts   MonitorOpts
+	now    func() time.Time
+
+	mu      sync.Mutex
+	retries map[int64]retryState
+}
+
+type retryState struct {
+	attempts int
+	next     time.Time
+}
+
+// NewMonitor returns a new [Monitor] scanning the certificates with the client.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewMonitor(client *hcloud.Client, opts MonitorOpts) *Monitor {
+	if opts.ExpiryWindow <= 0 {
+		opts.ExpiryWindow = DefaultExpiryWindow
+	}
+	if opts.RetryBackoffFunc == nil {
+		opts.RetryBackoffFunc = hcloud.ExponentialBackoffWithOpts(hcloud.ExponentialBackoffOpts{
+			Base:       10 * time.Minute,
+			Multiplier: 2,
+			Cap:        24 * time.Hour,
+		})
+	}
+	if opts.MaxNonTransientRetries <= 0 {
+		opts.MaxNonTransientRetries = DefaultMaxNonTransientRetries
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+
+	return &Monitor{
+		client:  client,
+		opts:    opts,
+		now:     time.Now,
+		retries: make(map[int64]retryState),
+	}
+}
+
+// Run scans the certificates every interval until the context is done, and passes
+// the result of every scan to the handler.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (m *Monitor) Run(ctx context.Context, handler func(report *Report, err error)) {
+	for {
+		report, err := m.Scan(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		handler(report, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.opts.Interval):
+		}
+	}
+}
+
+// Scan scans the certificates once, and retries the issuance of the failed managed
+// certificates whose backoff elapsed. The issuance is retried even if the
+// [Diagnostic] is not transient, as the cause of the error may have been resolved,
+// but at most [MonitorOpts.MaxNonTransientRetries] times.
+//
+// The report is returned along with the errors of the retries and of the Load
+// Balancers that could not be fetched.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (m *Monitor) Scan(ctx context.Context) (*Report, error) {
+	certificates, err := m.client.Certificate.AllWithOpts(ctx, hcloud.CertificateListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: m.opts.LabelSelector},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list certificates: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	report := &Report{Time: now, Scanned: len(certificates)}
+	errs := []error{}
+
+	loadBalancers := map[int64]*hcloud.LoadBalancer{}
+	getLoadBalancer := func(id int64) (*hcloud.LoadBalancer, error) {
+		if loadBalancer, ok := loadBalancers[id]; ok {
+			return loadBalancer, nil
+		}
+		loadBalancer, _, err := m.client.LoadBalancer.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get load balancer %d: %w", id, err)
+		}
+		loadBalancers[id] = loadBalancer
+		return loadBalancer, nil
+	}
+
+	scanned := make(map[int64]bool, len(certificates))
+	for _, certificate := range certificates {
+		scanned[certificate.ID] = true
+
+		entry := CertificateReport{Certificate: certificate}
+		if !certificate.NotValidAfter.IsZero() {
+			entry.ExpiresIn = certificate.NotValidAfter.Sub(now)
+			entry.Expiring = entry.ExpiresIn <= m.opts.ExpiryWindow
+		}
+		if certificate.Status != nil {
+			entry.Failed = certificate.Status.IsFailed()
+			if certificate.Status.Error != nil {
+				diagnostic := Diagnose(certificate.Status.Error)
+				entry.Diagnostic = &diagnostic
+			}
+		}
+
+		if entry.Failed && certificate.Type == hcloud.CertificateTypeManaged && !m.opts.DisableRetry {
+			entry.Retry, entry.NextRetry = m.retry(ctx, certificate, entry.Diagnostic, now)
+			if entry.Retry != nil && entry.Retry.Err != nil {
+				errs = append(errs, fmt.Errorf("could not retry issuance of certificate %d: %w", certificate.ID, entry.Retry.Err))
+			}
+		} else if settled(certificate) {
+			// Only reset the backoff once the retried issuance is done, to not retry
+			// immediately when it fails again.
+			delete(m.retries, certificate.ID)
+		}
+
+		if !entry.Expiring && !entry.Failed && entry.Diagnostic == nil {
+			continue
+		}
+
+		for _, ref := range certificate.UsedBy {
+			if ref.Type != hcloud.CertificateUsedByRefTypeLoadBalancer {
+				continue
+			}
+			loadBalancer, err := getLoadBalancer(ref.ID)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if loadBalancer != nil {
+				entry.LoadBalancers = append(entry.LoadBalancers, loadBalancer)
+			}
+		}
+		report.Certificates = append(report.Certificates, entry)
+	}
+
+	for id := range m.retries {
+		if !scanned[id] {
+			delete(m.retries, id)
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// retry retries the issuance of the certificate if its backoff elapsed, and returns
+// the time of the next retry, zero once the retries of a non-transient error are
+// exhausted.
+func (m *Monitor) retry(ctx context.Context, certificate *hcloud.Certificate, diagnostic *Diagnostic, now time.Time) (*Retry, time.Time) {
+	state := m.retries[certificate.ID]
+	exhausted := func() bool {
+		return diagnostic != nil && !diagnostic.Transient && state.attempts >= m.opts.MaxNonTransientRetries
+	}
+	if exhausted() {
+		return nil, time.Time{}
+	}
+	if now.Before(state.next) {
+		return nil, state.next
+	}
+
+	action, _, err := m.client.Certificate.RetryIssuance(ctx, certificate)
+	state.attempts++
+	state.next = now.Add(m.opts.RetryBackoffFunc(state.attempts - 1))
+	m.retries[certificate.ID] = state
+
+	retry := &Retry{Attempt: state.attempts, Action: action, Err: err}
+	if exhausted() {
+		return retry, time.Time{}
+	}
+	return retry, state.next
+}
+
+// settled reports whether the certificate is neither failed nor pending.
+func settled(certificate *hcloud.Certificate) bool {
+	status := certificate.Status
+	if status == nil {
+		return true
+	}
+	return !status.IsFailed() &&
+		status.Issuance != hcloud.CertificateStatusTypePending &&
+		status.Renewal != hcloud.CertificateStatusTypePending
+}
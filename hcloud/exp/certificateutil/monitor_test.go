@@ -0,0 +1,186 @@
+package certificateutil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/mockutil"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func TestMonitorScan(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	expiring := schema.Certificate{
+		ID:            1,
+		Name:          "uploaded",
+		Type:          "uploaded",
+		NotValidAfter: now.Add(10 * 24 * time.Hour),
+		UsedBy:        []schema.CertificateUsedByRef{{ID: 5, Type: "load_balancer"}},
+	}
+	failed := schema.Certificate{
+		ID:   2,
+		Name: "managed",
+		Type: "managed",
+		Status: &schema.CertificateStatusRef{
+			Issuance: "failed",
+			Renewal:  "unavailable",
+			Error:    &schema.Error{Code: "caa_record_does_not_allow_ca", Message: "CAA record does not allow certificate authority"},
+		},
+		UsedBy: []schema.CertificateUsedByRef{{ID: 5, Type: "load_balancer"}, {ID: 6, Type: "load_balancer"}},
+	}
+	valid := schema.Certificate{
+		ID:            3,
+		Name:          "valid",
+		Type:          "managed",
+		NotValidAfter: now.Add(80 * 24 * time.Hour),
+		Status:        &schema.CertificateStatusRef{Issuance: "completed", Renewal: "scheduled"},
+	}
+
+	listCertificates := func(certificates ...schema.Certificate) mockutil.Request {
+		return mockutil.Request{
+			Method: "GET",
+			Want: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/certificates", r.URL.Path)
+				assert.Equal(t, "env=prod", r.URL.Query().Get("label_selector"))
+			},
+			Status: 200,
+			JSON:   schema.CertificateListResponse{Certificates: certificates},
+		}
+	}
+	getLoadBalancer := mockutil.Request{
+		Method: "GET", Path: "/load_balancers/5",
+		Status: 200,
+		JSON:   schema.LoadBalancerGetResponse{LoadBalancer: schema.LoadBalancer{ID: 5, Name: "web"}},
+	}
+	getLoadBalancerNotFound := mockutil.Request{
+		Method: "GET", Path: "/load_balancers/6",
+		Status: 404,
+		JSON:   schema.ErrorResponse{Error: schema.Error{Code: "not_found"}},
+	}
+
+	server := mockutil.NewServer(t, []mockutil.Request{
+		listCertificates(expiring, failed, valid),
+		getLoadBalancer,
+		{
+			Method: "POST", Path: "/certificates/2/actions/retry",
+			Status: 201,
+			JSON:   schema.CertificateIssuanceRetryResponse{Action: schema.Action{ID: 10, Status: "running"}},
+		},
+		getLoadBalancerNotFound,
+
+		// The backoff did not elapse
+		listCertificates(failed),
+		getLoadBalancer,
+		getLoadBalancerNotFound,
+
+		// The backoff elapsed
+		listCertificates(failed),
+		{
+			Method: "POST", Path: "/certificates/2/actions/retry",
+			Status: 422,
+			JSON:   schema.ErrorResponse{Error: schema.Error{Code: "unprocessable_entity", Message: "retry failed"}},
+		},
+		getLoadBalancer,
+		getLoadBalancerNotFound,
+
+		// The retries of the non-transient error are exhausted
+		listCertificates(failed),
+		getLoadBalancer,
+		getLoadBalancerNotFound,
+	})
+
+	client := hcloud.NewClient(
+		hcloud.WithEndpoint(server.URL),
+		hcloud.WithToken("token"),
+		hcloud.WithRetryOpts(hcloud.RetryOpts{BackoffFunc: hcloud.ConstantBackoff(0), MaxRetries: 0}),
+	)
+	monitor := NewMonitor(client, MonitorOpts{
+		LabelSelector:          "env=prod",
+		RetryBackoffFunc:       hcloud.ConstantBackoff(time.Hour),
+		MaxNonTransientRetries: 2,
+	})
+	monitor.now = func() time.Time { return now }
+
+	report, err := monitor.Scan(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now, report.Time)
+	assert.Equal(t, 3, report.Scanned)
+	require.Len(t, report.Certificates, 2)
+
+	certificate := report.Certificates[0]
+	assert.True(t, certificate.Expiring)
+	assert.Equal(t, 10*24*time.Hour, certificate.ExpiresIn)
+	assert.False(t, certificate.Failed)
+	assert.Nil(t, certificate.Retry)
+	require.Len(t, certificate.LoadBalancers, 1)
+	assert.Equal(t, "certificate 1 (uploaded): expires in 240h0m0s (used by load balancers web)", certificate.String())
+
+	certificate = report.Certificates[1]
+	assert.False(t, certificate.Expiring)
+	assert.True(t, certificate.Failed)
+	require.NotNil(t, certificate.Diagnostic)
+	assert.Equal(t, hcloud.ErrorCodeCAARecordDoesNotAllowCA, certificate.Diagnostic.Code)
+	assert.False(t, certificate.Diagnostic.Transient)
+	require.NotNil(t, certificate.Retry)
+	assert.Equal(t, 1, certificate.Retry.Attempt)
+	assert.Equal(t, int64(10), certificate.Retry.Action.ID)
+	assert.Equal(t, now.Add(time.Hour), certificate.NextRetry)
+	require.Len(t, certificate.LoadBalancers, 1)
+	assert.Equal(t, int64(5), certificate.LoadBalancers[0].ID)
+
+	report, err = monitor.Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Certificates, 1)
+	assert.Nil(t, report.Certificates[0].Retry)
+	assert.Equal(t, now.Add(time.Hour), report.Certificates[0].NextRetry)
+
+	now = now.Add(time.Hour)
+	report, err = monitor.Scan(ctx)
+	require.EqualError(t, err, "could not retry issuance of certificate 2: retry failed (unprocessable_entity)")
+	require.Len(t, report.Certificates, 1)
+	require.NotNil(t, report.Certificates[0].Retry)
+	assert.Equal(t, 2, report.Certificates[0].Retry.Attempt)
+	assert.Nil(t, report.Certificates[0].Retry.Action)
+	assert.True(t, report.Certificates[0].NextRetry.IsZero())
+
+	now = now.Add(time.Hour)
+	report, err = monitor.Scan(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Certificates, 1)
+	assert.True(t, report.Certificates[0].Failed)
+	assert.Nil(t, report.Certificates[0].Retry)
+	assert.True(t, report.Certificates[0].NextRetry.IsZero())
+}
+
+func TestMonitorRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := mockutil.NewServer(t, []mockutil.Request{
+		{Method: "GET", Status: 200, JSON: schema.CertificateListResponse{Certificates: []schema.Certificate{}}},
+		{Method: "GET", Status: 200, JSON: schema.CertificateListResponse{Certificates: []schema.Certificate{}}},
+	})
+
+	client := hcloud.NewClient(hcloud.WithEndpoint(server.URL), hcloud.WithToken("token"))
+	monitor := NewMonitor(client, MonitorOpts{Interval: time.Millisecond})
+
+	scans := 0
+	monitor.Run(ctx, func(report *Report, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Scanned)
+
+		scans++
+		if scans == 2 {
+			cancel()
+		}
+	})
+	assert.Equal(t, 2, scans)
+}
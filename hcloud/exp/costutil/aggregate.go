@@ -0,0 +1,49 @@
+package costutil
+
+// Total returns the sum of the cost of the estimates.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Total(estimates []*Estimate) Cost {
+	result := Cost{}
+	for _, estimate := range estimates {
+		result = result.Add(estimate.Cost)
+	}
+	return result
+}
+
+// ByLocation returns the sum of the cost of the estimates per location.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ByLocation(estimates []*Estimate) map[string]Cost {
+	return groupBy(estimates, func(estimate *Estimate) string {
+		return estimate.Location
+	})
+}
+
+// ByResourceType returns the sum of the cost of the estimates per resource type.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ByResourceType(estimates []*Estimate) map[ResourceType]Cost {
+	return groupBy(estimates, func(estimate *Estimate) ResourceType {
+		return estimate.ResourceType
+	})
+}
+
+// ByLabel returns the sum of the cost of the estimates per value of the label. The
+// cost of the estimates without the label is stored under the empty string.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ByLabel(estimates []*Estimate, key string) map[string]Cost {
+	return groupBy(estimates, func(estimate *Estimate) string {
+		return estimate.Labels[key]
+	})
+}
+
+func groupBy[K comparable](estimates []*Estimate, keyFunc func(*Estimate) K) map[K]Cost {
+	result := make(map[K]Cost)
+	for _, estimate := range estimates {
+		key := keyFunc(estimate)
+		result[key] = result[key].Add(estimate.Cost)
+	}
+	return result
+}
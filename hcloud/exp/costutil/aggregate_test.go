@@ -0,0 +1,37 @@
+package costutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	cost := func(monthly float64) Cost {
+		return Cost{Monthly: Amount{Net: monthly, Gross: monthly * 1.19}}
+	}
+	estimates := []*Estimate{
+		{ResourceType: ResourceTypeServer, Location: "fsn1", Labels: map[string]string{"team": "a"}, Cost: cost(6)},
+		{ResourceType: ResourceTypeServer, Location: "hel1", Labels: map[string]string{"team": "b"}, Cost: cost(5)},
+		{ResourceType: ResourceTypeVolume, Location: "fsn1", Labels: map[string]string{"team": "a"}, Cost: cost(2)},
+		{ResourceType: ResourceTypeFloatingIP, Location: "fsn1", Cost: cost(1)},
+	}
+
+	assert.InDelta(t, 14.0, Total(estimates).Monthly.Net, 1e-9)
+	assert.InDelta(t, 14*1.19, Total(estimates).Monthly.Gross, 1e-9)
+
+	byLocation := ByLocation(estimates)
+	assert.Len(t, byLocation, 2)
+	assert.InDelta(t, 9.0, byLocation["fsn1"].Monthly.Net, 1e-9)
+	assert.InDelta(t, 5.0, byLocation["hel1"].Monthly.Net, 1e-9)
+
+	byResourceType := ByResourceType(estimates)
+	assert.Len(t, byResourceType, 3)
+	assert.InDelta(t, 11.0, byResourceType[ResourceTypeServer].Monthly.Net, 1e-9)
+
+	byLabel := ByLabel(estimates, "team")
+	assert.Len(t, byLabel, 3)
+	assert.InDelta(t, 8.0, byLabel["a"].Monthly.Net, 1e-9)
+	assert.InDelta(t, 5.0, byLabel["b"].Monthly.Net, 1e-9)
+	assert.InDelta(t, 1.0, byLabel[""].Monthly.Net, 1e-9)
+}
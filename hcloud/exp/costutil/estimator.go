@@ -0,0 +1,529 @@
+// Package costutil estimates the cost of resources from the prices returned by the
+// pricing API, for example to enforce a budget before creating resources:
+//
+//	pricing, _, err := client.Pricing.Get(ctx)
+//	estimator := costutil.NewEstimator(pricing)
+//
+//	estimate, err := estimator.ServerCreateOpts(opts)
+//	if estimate.Cost.Monthly.Gross > budget {
+//		return errors.New("over budget")
+//	}
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package costutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// HoursPerMonth is the average number of hours in a month, used to derive the
+// hourly cost of the resources only priced per month (volumes and floating IPs).
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const HoursPerMonth = 730
+
+// ResourceType is the type of the resource of an [Estimate].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type ResourceType string
+
+const (
+	ResourceTypeServer       ResourceType = "server"
+	ResourceTypeVolume       ResourceType = "volume"
+	ResourceTypeLoadBalancer ResourceType = "load_balancer"
+	ResourceTypePrimaryIP    ResourceType = "primary_ip"
+	ResourceTypeFloatingIP   ResourceType = "floating_ip"
+)
+
+// Amount is a net and gross amount of money, in the currency of the pricing.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Amount struct {
+	Net   float64
+	Gross float64
+}
+
+// Add returns the sum of the amounts.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (a Amount) Add(b Amount) Amount {
+	return Amount{Net: a.Net + b.Net, Gross: a.Gross + b.Gross}
+}
+
+// Mul returns the amount multiplied by the factor.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (a Amount) Mul(factor float64) Amount {
+	return Amount{Net: a.Net * factor, Gross: a.Gross * factor}
+}
+
+// Cost is the hourly and monthly cost of one or more resources.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Cost struct {
+	Hourly  Amount
+	Monthly Amount
+}
+
+// Add returns the sum of the costs.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (c Cost) Add(b Cost) Cost {
+	return Cost{Hourly: c.Hourly.Add(b.Hourly), Monthly: c.Monthly.Add(b.Monthly)}
+}
+
+// Estimate is the estimated cost of a resource.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Estimate struct {
+	ResourceType ResourceType
+	// ID is 0 for the estimates of create options.
+	ID       int64
+	Name     string
+	Labels   map[string]string
+	Location string
+
+	// Cost is the cost of the resource, including the backup surcharge and the primary
+	// IPv4 of a server.
+	Cost Cost
+	// Backup is the surcharge for the backups of a server.
+	Backup Cost
+	// PrimaryIPv4 is the cost of the primary IPv4 created with a server, only set for
+	// the estimates of create options. The primary IPs of existing servers are
+	// estimated separately, see [Estimator.PrimaryIP].
+	PrimaryIPv4 Cost
+
+	// IncludedTraffic is the free traffic per month in bytes, for servers and load
+	// balancers.
+	IncludedTraffic uint64
+	// PerTBTraffic is the price of the traffic exceeding the included traffic.
+	PerTBTraffic Amount
+}
+
+// Estimator computes the estimated cost of resources from a [hcloud.Pricing].
+//
+// An Estimator must be created using the [NewEstimator] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Estimator struct {
+	pricing hcloud.Pricing
+}
+
+// NewEstimator returns a new [Estimator] using the given pricing, as returned by
+// [hcloud.PricingClient.Get].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewEstimator(pricing hcloud.Pricing) *Estimator {
+	return &Estimator{pricing: pricing}
+}
+
+// Server returns the estimated cost of the server. The backup surcharge is included
+// when the backups of the server are enabled.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) Server(server *hcloud.Server) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeServer,
+		ID:           server.ID,
+		Name:         server.Name,
+		Labels:       server.Labels,
+		Location:     locationName(server.Location, server.Datacenter), // nolint:staticcheck // Deprecated
+	}
+	if err := e.serverCost(estimate, server.ServerType, server.BackupWindow != ""); err != nil {
+		return nil, fmt.Errorf("server %d: %w", server.ID, err)
+	}
+	return estimate, nil
+}
+
+// ServerCreateOpts returns the estimated cost of the server created with the
+// options, including the primary IPv4 created with the server unless it is disabled
+// or an existing primary IPv4 is assigned. The backups can not be enabled on create,
+// the estimate does not include the backup surcharge.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) ServerCreateOpts(opts hcloud.ServerCreateOpts) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeServer,
+		Name:         opts.Name,
+		Labels:       opts.Labels,
+		Location:     locationName(opts.Location, opts.Datacenter), // nolint:staticcheck // Deprecated
+	}
+	if err := e.serverCost(estimate, opts.ServerType, false); err != nil {
+		return nil, fmt.Errorf("server %s: %w", opts.Name, err)
+	}
+
+	// A primary IPv4 is created with the server by default.
+	if opts.PublicNet == nil || (opts.PublicNet.EnableIPv4 && opts.PublicNet.IPv4 == nil) {
+		primaryIPv4 := &Estimate{Location: estimate.Location}
+		if err := e.primaryIPCost(primaryIPv4, hcloud.PrimaryIPTypeIPv4); err != nil {
+			return nil, fmt.Errorf("server %s: %w", opts.Name, err)
+		}
+		estimate.PrimaryIPv4 = primaryIPv4.Cost
+		estimate.Cost = estimate.Cost.Add(estimate.PrimaryIPv4)
+	}
+	return estimate, nil
+}
+
+func locationName(location *hcloud.Location, datacenter *hcloud.Datacenter) string {
+	switch {
+	case location != nil:
+		return location.Name
+	case datacenter != nil && datacenter.Location != nil:
+		return datacenter.Location.Name
+	default:
+		return ""
+	}
+}
+
+func (e *Estimator) serverCost(estimate *Estimate, serverType *hcloud.ServerType, backup bool) error {
+	if serverType == nil {
+		return errors.New("missing server type")
+	}
+	if estimate.Location == "" {
+		return errors.New("missing location")
+	}
+
+	for _, typePricing := range e.pricing.ServerTypes {
+		if !matchServerType(typePricing.ServerType, serverType) {
+			continue
+		}
+		for _, pricing := range typePricing.Pricings {
+			if pricing.Location == nil || pricing.Location.Name != estimate.Location {
+				continue
+			}
+
+			var err error
+			if estimate.Cost.Hourly, err = parsePrice(pricing.Hourly.Net, pricing.Hourly.Gross); err != nil {
+				return err
+			}
+			if estimate.Cost.Monthly, err = parsePrice(pricing.Monthly.Net, pricing.Monthly.Gross); err != nil {
+				return err
+			}
+			if estimate.PerTBTraffic, err = parsePrice(pricing.PerTBTraffic.Net, pricing.PerTBTraffic.Gross); err != nil {
+				return err
+			}
+			estimate.IncludedTraffic = pricing.IncludedTraffic
+
+			if backup {
+				percentage, err := strconv.ParseFloat(e.pricing.ServerBackup.Percentage, 64)
+				if err != nil {
+					return fmt.Errorf("invalid backup percentage %q: %w", e.pricing.ServerBackup.Percentage, err)
+				}
+				estimate.Backup = Cost{
+					Hourly:  estimate.Cost.Hourly.Mul(percentage / 100),
+					Monthly: estimate.Cost.Monthly.Mul(percentage / 100),
+				}
+				estimate.Cost = estimate.Cost.Add(estimate.Backup)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no pricing for server type %s in location %s", serverTypeName(serverType), estimate.Location)
+}
+
+func matchServerType(a, b *hcloud.ServerType) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.ID != 0 && b.ID != 0 {
+		return a.ID == b.ID
+	}
+	return a.Name != "" && a.Name == b.Name
+}
+
+func serverTypeName(serverType *hcloud.ServerType) string {
+	if serverType.Name != "" {
+		return serverType.Name
+	}
+	return strconv.FormatInt(serverType.ID, 10)
+}
+
+// Volume returns the estimated cost of the volume.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) Volume(volume *hcloud.Volume) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeVolume,
+		ID:           volume.ID,
+		Name:         volume.Name,
+		Labels:       volume.Labels,
+	}
+	if volume.Location != nil {
+		estimate.Location = volume.Location.Name
+	}
+	if err := e.volumeCost(estimate, volume.Size); err != nil {
+		return nil, fmt.Errorf("volume %d: %w", volume.ID, err)
+	}
+	return estimate, nil
+}
+
+// VolumeCreateOpts returns the estimated cost of the volume created with the options.
+// The location of the volume is taken from the server when no location is given.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) VolumeCreateOpts(opts hcloud.VolumeCreateOpts) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeVolume,
+		Name:         opts.Name,
+		Labels:       opts.Labels,
+	}
+	switch {
+	case opts.Location != nil:
+		estimate.Location = opts.Location.Name
+	case opts.Server != nil:
+		estimate.Location = locationName(opts.Server.Location, opts.Server.Datacenter) // nolint:staticcheck // Deprecated
+	}
+	if err := e.volumeCost(estimate, opts.Size); err != nil {
+		return nil, fmt.Errorf("volume %s: %w", opts.Name, err)
+	}
+	return estimate, nil
+}
+
+func (e *Estimator) volumeCost(estimate *Estimate, size int) error {
+	perGB, err := parsePrice(e.pricing.Volume.PerGBMonthly.Net, e.pricing.Volume.PerGBMonthly.Gross)
+	if err != nil {
+		return err
+	}
+	estimate.Cost = monthlyCost(perGB.Mul(float64(size)))
+	return nil
+}
+
+// LoadBalancer returns the estimated cost of the load balancer.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) LoadBalancer(loadBalancer *hcloud.LoadBalancer) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeLoadBalancer,
+		ID:           loadBalancer.ID,
+		Name:         loadBalancer.Name,
+		Labels:       loadBalancer.Labels,
+	}
+	if loadBalancer.Location != nil {
+		estimate.Location = loadBalancer.Location.Name
+	}
+	if err := e.loadBalancerCost(estimate, loadBalancer.LoadBalancerType); err != nil {
+		return nil, fmt.Errorf("load balancer %d: %w", loadBalancer.ID, err)
+	}
+	return estimate, nil
+}
+
+// LoadBalancerCreateOpts returns the estimated cost of the load balancer created with
+// the options.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) LoadBalancerCreateOpts(opts hcloud.LoadBalancerCreateOpts) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeLoadBalancer,
+		Name:         opts.Name,
+		Labels:       opts.Labels,
+	}
+	if opts.Location != nil {
+		estimate.Location = opts.Location.Name
+	}
+	if err := e.loadBalancerCost(estimate, opts.LoadBalancerType); err != nil {
+		return nil, fmt.Errorf("load balancer %s: %w", opts.Name, err)
+	}
+	return estimate, nil
+}
+
+func (e *Estimator) loadBalancerCost(estimate *Estimate, loadBalancerType *hcloud.LoadBalancerType) error {
+	if loadBalancerType == nil {
+		return errors.New("missing load balancer type")
+	}
+	if estimate.Location == "" {
+		return errors.New("missing location")
+	}
+
+	for _, typePricing := range e.pricing.LoadBalancerTypes {
+		if !matchLoadBalancerType(typePricing.LoadBalancerType, loadBalancerType) {
+			continue
+		}
+		for _, pricing := range typePricing.Pricings {
+			if pricing.Location == nil || pricing.Location.Name != estimate.Location {
+				continue
+			}
+
+			var err error
+			if estimate.Cost.Hourly, err = parsePrice(pricing.Hourly.Net, pricing.Hourly.Gross); err != nil {
+				return err
+			}
+			if estimate.Cost.Monthly, err = parsePrice(pricing.Monthly.Net, pricing.Monthly.Gross); err != nil {
+				return err
+			}
+			if estimate.PerTBTraffic, err = parsePrice(pricing.PerTBTraffic.Net, pricing.PerTBTraffic.Gross); err != nil {
+				return err
+			}
+			estimate.IncludedTraffic = pricing.IncludedTraffic
+			return nil
+		}
+	}
+	return fmt.Errorf("no pricing for load balancer type %s in location %s", loadBalancerTypeName(loadBalancerType), estimate.Location)
+}
+
+func matchLoadBalancerType(a, b *hcloud.LoadBalancerType) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.ID != 0 && b.ID != 0 {
+		return a.ID == b.ID
+	}
+	return a.Name != "" && a.Name == b.Name
+}
+
+func loadBalancerTypeName(loadBalancerType *hcloud.LoadBalancerType) string {
+	if loadBalancerType.Name != "" {
+		return loadBalancerType.Name
+	}
+	return strconv.FormatInt(loadBalancerType.ID, 10)
+}
+
+// PrimaryIP returns the estimated cost of the primary IP.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) PrimaryIP(primaryIP *hcloud.PrimaryIP) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypePrimaryIP,
+		ID:           primaryIP.ID,
+		Name:         primaryIP.Name,
+		Labels:       primaryIP.Labels,
+		Location:     locationName(primaryIP.Location, primaryIP.Datacenter), // nolint:staticcheck // Deprecated
+	}
+	if err := e.primaryIPCost(estimate, primaryIP.Type); err != nil {
+		return nil, fmt.Errorf("primary ip %d: %w", primaryIP.ID, err)
+	}
+	return estimate, nil
+}
+
+// PrimaryIPCreateOpts returns the estimated cost of the primary IP created with the
+// options.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) PrimaryIPCreateOpts(opts hcloud.PrimaryIPCreateOpts) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypePrimaryIP,
+		Name:         opts.Name,
+		Labels:       opts.Labels,
+		Location:     opts.Location,
+	}
+	if err := e.primaryIPCost(estimate, opts.Type); err != nil {
+		return nil, fmt.Errorf("primary ip %s: %w", opts.Name, err)
+	}
+	return estimate, nil
+}
+
+func (e *Estimator) primaryIPCost(estimate *Estimate, ipType hcloud.PrimaryIPType) error {
+	if estimate.Location == "" {
+		return errors.New("missing location")
+	}
+
+	for _, typePricing := range e.pricing.PrimaryIPs {
+		if typePricing.Type != string(ipType) {
+			continue
+		}
+		for _, pricing := range typePricing.Pricings {
+			if pricing.Location != estimate.Location {
+				continue
+			}
+
+			var err error
+			if estimate.Cost.Hourly, err = parsePrice(pricing.Hourly.Net, pricing.Hourly.Gross); err != nil {
+				return err
+			}
+			if estimate.Cost.Monthly, err = parsePrice(pricing.Monthly.Net, pricing.Monthly.Gross); err != nil {
+				return err
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no pricing for primary ip type %s in location %s", ipType, estimate.Location)
+}
+
+// FloatingIP returns the estimated cost of the floating IP.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) FloatingIP(floatingIP *hcloud.FloatingIP) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeFloatingIP,
+		ID:           floatingIP.ID,
+		Name:         floatingIP.Name,
+		Labels:       floatingIP.Labels,
+	}
+	if floatingIP.HomeLocation != nil {
+		estimate.Location = floatingIP.HomeLocation.Name
+	}
+	if err := e.floatingIPCost(estimate, floatingIP.Type); err != nil {
+		return nil, fmt.Errorf("floating ip %d: %w", floatingIP.ID, err)
+	}
+	return estimate, nil
+}
+
+// FloatingIPCreateOpts returns the estimated cost of the floating IP created with the
+// options. The location of the floating IP is taken from the server when no home
+// location is given.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (e *Estimator) FloatingIPCreateOpts(opts hcloud.FloatingIPCreateOpts) (*Estimate, error) {
+	estimate := &Estimate{
+		ResourceType: ResourceTypeFloatingIP,
+		Labels:       opts.Labels,
+	}
+	if opts.Name != nil {
+		estimate.Name = *opts.Name
+	}
+	switch {
+	case opts.HomeLocation != nil:
+		estimate.Location = opts.HomeLocation.Name
+	case opts.Server != nil:
+		estimate.Location = locationName(opts.Server.Location, opts.Server.Datacenter) // nolint:staticcheck // Deprecated
+	}
+	if err := e.floatingIPCost(estimate, opts.Type); err != nil {
+		return nil, fmt.Errorf("floating ip %s: %w", estimate.Name, err)
+	}
+	return estimate, nil
+}
+
+func (e *Estimator) floatingIPCost(estimate *Estimate, ipType hcloud.FloatingIPType) error {
+	if estimate.Location == "" {
+		return errors.New("missing location")
+	}
+
+	for _, typePricing := range e.pricing.FloatingIPs {
+		if typePricing.Type != ipType {
+			continue
+		}
+		for _, pricing := range typePricing.Pricings {
+			if pricing.Location == nil || pricing.Location.Name != estimate.Location {
+				continue
+			}
+
+			monthly, err := parsePrice(pricing.Monthly.Net, pricing.Monthly.Gross)
+			if err != nil {
+				return err
+			}
+			estimate.Cost = monthlyCost(monthly)
+			return nil
+		}
+	}
+	return fmt.Errorf("no pricing for floating ip type %s in location %s", ipType, estimate.Location)
+}
+
+// monthlyCost returns the cost of a resource only priced per month.
+func monthlyCost(monthly Amount) Cost {
+	return Cost{Hourly: monthly.Mul(1.0 / HoursPerMonth), Monthly: monthly}
+}
+
+func parsePrice(net, gross string) (Amount, error) {
+	var result Amount
+	var err error
+	if result.Net, err = strconv.ParseFloat(net, 64); err != nil {
+		return Amount{}, fmt.Errorf("invalid price %q: %w", net, err)
+	}
+	if result.Gross, err = strconv.ParseFloat(gross, 64); err != nil {
+		return Amount{}, fmt.Errorf("invalid price %q: %w", gross, err)
+	}
+	return result, nil
+}
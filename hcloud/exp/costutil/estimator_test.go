@@ -0,0 +1,243 @@
+package costutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+var testPricing = hcloud.Pricing{
+	Currency:     "EUR",
+	VATRate:      "19.00",
+	ServerBackup: hcloud.ServerBackupPricing{Percentage: "20.00"},
+	ServerTypes: []hcloud.ServerTypePricing{{
+		ServerType: &hcloud.ServerType{ID: 1, Name: "cpx22"},
+		Pricings: []hcloud.ServerTypeLocationPricing{
+			{
+				Location:        &hcloud.Location{Name: "fsn1"},
+				Hourly:          hcloud.Price{Net: "0.0100000000", Gross: "0.0119000000"},
+				Monthly:         hcloud.Price{Net: "6.0000000000", Gross: "7.1400000000"},
+				IncludedTraffic: 21990232555520,
+				PerTBTraffic:    hcloud.Price{Net: "1.0000000000", Gross: "1.1900000000"},
+			},
+			{
+				Location:        &hcloud.Location{Name: "hel1"},
+				Hourly:          hcloud.Price{Net: "0.0090000000", Gross: "0.0107100000"},
+				Monthly:         hcloud.Price{Net: "5.0000000000", Gross: "5.9500000000"},
+				IncludedTraffic: 21990232555520,
+				PerTBTraffic:    hcloud.Price{Net: "1.0000000000", Gross: "1.1900000000"},
+			},
+		},
+	}},
+	LoadBalancerTypes: []hcloud.LoadBalancerTypePricing{{
+		LoadBalancerType: &hcloud.LoadBalancerType{ID: 1, Name: "lb11"},
+		Pricings: []hcloud.LoadBalancerTypeLocationPricing{{
+			Location:        &hcloud.Location{Name: "fsn1"},
+			Hourly:          hcloud.Price{Net: "0.0080000000", Gross: "0.0095200000"},
+			Monthly:         hcloud.Price{Net: "5.0000000000", Gross: "5.9500000000"},
+			IncludedTraffic: 21990232555520,
+			PerTBTraffic:    hcloud.Price{Net: "1.0000000000", Gross: "1.1900000000"},
+		}},
+	}},
+	Volume: hcloud.VolumePricing{PerGBMonthly: hcloud.Price{Net: "0.0440000000", Gross: "0.0523600000"}},
+	PrimaryIPs: []hcloud.PrimaryIPPricing{{
+		Type: "ipv4",
+		Pricings: []hcloud.PrimaryIPTypePricing{{
+			Location: "fsn1",
+			Hourly:   hcloud.PrimaryIPPrice{Net: "0.0010000000", Gross: "0.0011900000"},
+			Monthly:  hcloud.PrimaryIPPrice{Net: "0.5000000000", Gross: "0.5950000000"},
+		}},
+	}},
+	FloatingIPs: []hcloud.FloatingIPTypePricing{{
+		Type: hcloud.FloatingIPTypeIPv4,
+		Pricings: []hcloud.FloatingIPTypeLocationPricing{{
+			Location: &hcloud.Location{Name: "fsn1"},
+			Monthly:  hcloud.Price{Net: "3.6500000000", Gross: "4.3435000000"},
+		}},
+	}},
+}
+
+func TestEstimatorServer(t *testing.T) {
+	estimator := NewEstimator(testPricing)
+
+	t.Run("without backups", func(t *testing.T) {
+		estimate, err := estimator.Server(&hcloud.Server{
+			ID:         42,
+			Name:       "web",
+			Labels:     map[string]string{"team": "a"},
+			ServerType: &hcloud.ServerType{ID: 1, Name: "cpx22"},
+			Location:   &hcloud.Location{Name: "fsn1"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, ResourceTypeServer, estimate.ResourceType)
+		assert.Equal(t, int64(42), estimate.ID)
+		assert.Equal(t, "fsn1", estimate.Location)
+		assert.InDelta(t, 0.01, estimate.Cost.Hourly.Net, 1e-9)
+		assert.InDelta(t, 7.14, estimate.Cost.Monthly.Gross, 1e-9)
+		assert.Equal(t, Cost{}, estimate.Backup)
+		assert.Equal(t, uint64(21990232555520), estimate.IncludedTraffic)
+		assert.InDelta(t, 1.0, estimate.PerTBTraffic.Net, 1e-9)
+	})
+
+	t.Run("with backups", func(t *testing.T) {
+		estimate, err := estimator.Server(&hcloud.Server{
+			ID:           42,
+			ServerType:   &hcloud.ServerType{ID: 1},
+			Location:     &hcloud.Location{Name: "fsn1"},
+			BackupWindow: "22-02",
+		})
+		require.NoError(t, err)
+
+		assert.InDelta(t, 1.2, estimate.Backup.Monthly.Net, 1e-9)
+		assert.InDelta(t, 7.2, estimate.Cost.Monthly.Net, 1e-9)
+		assert.InDelta(t, 0.012, estimate.Cost.Hourly.Net, 1e-9)
+	})
+
+	t.Run("location from datacenter", func(t *testing.T) {
+		estimate, err := estimator.Server(&hcloud.Server{
+			ID:         42,
+			ServerType: &hcloud.ServerType{Name: "cpx22"},
+			Datacenter: &hcloud.Datacenter{Location: &hcloud.Location{Name: "hel1"}}, // nolint:staticcheck // Deprecated
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "hel1", estimate.Location)
+		assert.InDelta(t, 5.0, estimate.Cost.Monthly.Net, 1e-9)
+	})
+
+	t.Run("unknown location", func(t *testing.T) {
+		_, err := estimator.Server(&hcloud.Server{
+			ID:         42,
+			ServerType: &hcloud.ServerType{Name: "cpx22"},
+			Location:   &hcloud.Location{Name: "nbg1"},
+		})
+		assert.EqualError(t, err, "server 42: no pricing for server type cpx22 in location nbg1")
+	})
+}
+
+func TestEstimatorServerCreateOpts(t *testing.T) {
+	estimator := NewEstimator(testPricing)
+
+	estimate, err := estimator.ServerCreateOpts(hcloud.ServerCreateOpts{
+		Name:       "web",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Location:   &hcloud.Location{Name: "fsn1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "web", estimate.Name)
+	assert.InDelta(t, 0.5, estimate.PrimaryIPv4.Monthly.Net, 1e-9)
+	assert.InDelta(t, 6.5, estimate.Cost.Monthly.Net, 1e-9)
+	assert.InDelta(t, 0.011, estimate.Cost.Hourly.Net, 1e-9)
+
+	// Without IPv4, or with an existing primary IPv4
+	for _, publicNet := range []*hcloud.ServerCreatePublicNet{
+		{EnableIPv4: false, EnableIPv6: true},
+		{EnableIPv4: true, IPv4: &hcloud.PrimaryIP{ID: 1}},
+	} {
+		estimate, err = estimator.ServerCreateOpts(hcloud.ServerCreateOpts{
+			Name:       "web",
+			ServerType: &hcloud.ServerType{Name: "cpx22"},
+			Location:   &hcloud.Location{Name: "fsn1"},
+			PublicNet:  publicNet,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, Cost{}, estimate.PrimaryIPv4)
+		assert.InDelta(t, 6.0, estimate.Cost.Monthly.Net, 1e-9)
+	}
+
+	_, err = estimator.ServerCreateOpts(hcloud.ServerCreateOpts{
+		Name:       "web",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Location:   &hcloud.Location{Name: "hel1"},
+	})
+	assert.EqualError(t, err, "server web: no pricing for primary ip type ipv4 in location hel1")
+
+	_, err = estimator.ServerCreateOpts(hcloud.ServerCreateOpts{Name: "web", ServerType: &hcloud.ServerType{Name: "cpx22"}})
+	assert.EqualError(t, err, "server web: missing location")
+
+	_, err = estimator.ServerCreateOpts(hcloud.ServerCreateOpts{Name: "web", Location: &hcloud.Location{Name: "fsn1"}})
+	assert.EqualError(t, err, "server web: missing server type")
+}
+
+func TestEstimatorVolume(t *testing.T) {
+	estimator := NewEstimator(testPricing)
+
+	estimate, err := estimator.Volume(&hcloud.Volume{ID: 1, Size: 100, Location: &hcloud.Location{Name: "fsn1"}})
+	require.NoError(t, err)
+	assert.InDelta(t, 4.4, estimate.Cost.Monthly.Net, 1e-9)
+	assert.InDelta(t, 5.236, estimate.Cost.Monthly.Gross, 1e-9)
+	assert.InDelta(t, 4.4/HoursPerMonth, estimate.Cost.Hourly.Net, 1e-9)
+
+	estimate, err = estimator.VolumeCreateOpts(hcloud.VolumeCreateOpts{
+		Name:   "data",
+		Size:   10,
+		Server: &hcloud.Server{ID: 1, Location: &hcloud.Location{Name: "hel1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hel1", estimate.Location)
+	assert.InDelta(t, 0.44, estimate.Cost.Monthly.Net, 1e-9)
+}
+
+func TestEstimatorLoadBalancer(t *testing.T) {
+	estimator := NewEstimator(testPricing)
+
+	estimate, err := estimator.LoadBalancer(&hcloud.LoadBalancer{
+		ID:               1,
+		LoadBalancerType: &hcloud.LoadBalancerType{ID: 1},
+		Location:         &hcloud.Location{Name: "fsn1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ResourceTypeLoadBalancer, estimate.ResourceType)
+	assert.InDelta(t, 5.95, estimate.Cost.Monthly.Gross, 1e-9)
+	assert.Equal(t, uint64(21990232555520), estimate.IncludedTraffic)
+
+	_, err = estimator.LoadBalancerCreateOpts(hcloud.LoadBalancerCreateOpts{
+		Name:             "lb",
+		LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb21"},
+		Location:         &hcloud.Location{Name: "fsn1"},
+	})
+	assert.EqualError(t, err, "load balancer lb: no pricing for load balancer type lb21 in location fsn1")
+}
+
+func TestEstimatorPrimaryIP(t *testing.T) {
+	estimator := NewEstimator(testPricing)
+
+	estimate, err := estimator.PrimaryIP(&hcloud.PrimaryIP{ID: 1, Type: hcloud.PrimaryIPTypeIPv4, Location: &hcloud.Location{Name: "fsn1"}})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.001, estimate.Cost.Hourly.Net, 1e-9)
+	assert.InDelta(t, 0.595, estimate.Cost.Monthly.Gross, 1e-9)
+
+	_, err = estimator.PrimaryIPCreateOpts(hcloud.PrimaryIPCreateOpts{Name: "ip", Type: hcloud.PrimaryIPTypeIPv6, Location: "fsn1"})
+	assert.EqualError(t, err, "primary ip ip: no pricing for primary ip type ipv6 in location fsn1")
+}
+
+func TestEstimatorFloatingIP(t *testing.T) {
+	estimator := NewEstimator(testPricing)
+
+	estimate, err := estimator.FloatingIP(&hcloud.FloatingIP{ID: 1, Type: hcloud.FloatingIPTypeIPv4, HomeLocation: &hcloud.Location{Name: "fsn1"}})
+	require.NoError(t, err)
+	assert.InDelta(t, 3.65, estimate.Cost.Monthly.Net, 1e-9)
+	assert.InDelta(t, 3.65/HoursPerMonth, estimate.Cost.Hourly.Net, 1e-9)
+
+	estimate, err = estimator.FloatingIPCreateOpts(hcloud.FloatingIPCreateOpts{
+		Type:   hcloud.FloatingIPTypeIPv4,
+		Name:   hcloud.Ptr("ip"),
+		Server: &hcloud.Server{ID: 1, Location: &hcloud.Location{Name: "fsn1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ip", estimate.Name)
+	assert.Equal(t, "fsn1", estimate.Location)
+}
+
+func TestEstimatorInvalidPrice(t *testing.T) {
+	pricing := testPricing
+	pricing.Volume = hcloud.VolumePricing{PerGBMonthly: hcloud.Price{Net: "", Gross: ""}}
+	estimator := NewEstimator(pricing)
+
+	_, err := estimator.Volume(&hcloud.Volume{ID: 1, Size: 10, Location: &hcloud.Location{Name: "fsn1"}})
+	assert.ErrorContains(t, err, `volume 1: invalid price ""`)
+}
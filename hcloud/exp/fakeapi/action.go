@@ -0,0 +1,206 @@
+package fakeapi
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// action is a running or completed action. The onSuccess callback is used to update
+// the state of the resources once the action completes.
+type action struct {
+	schema.Action
+
+	onSuccess func()
+}
+
+// resourceRef returns an action resource reference.
+func resourceRef(kind string, id int64) schema.ActionResourceReference {
+	return schema.ActionResourceReference{ID: id, Type: kind}
+}
+
+// newAction creates a new running action. The onSuccess callback, if any, is called
+// with the state locked when the action completes.
+func (h *Handler) newAction(command string, resources []schema.ActionResourceReference, onSuccess func()) schema.Action {
+	h.lastActionID++
+
+	a := &action{
+		Action: schema.Action{
+			ID:        h.lastActionID,
+			Status:    "running",
+			Command:   command,
+			Progress:  0,
+			Started:   h.now(),
+			Resources: resources,
+		},
+		onSuccess: onSuccess,
+	}
+	h.actions[a.ID] = a
+
+	return a.Action
+}
+
+// advanceActions updates the progress of all running actions, and completes the actions
+// that are running for longer than the configured action duration.
+func (h *Handler) advanceActions() {
+	now := h.now()
+
+	for _, a := range sortedValues(h.actions) {
+		if a.Status != "running" {
+			continue
+		}
+
+		elapsed := now.Sub(a.Started)
+		if elapsed < h.actionDuration {
+			a.Progress = int(100 * elapsed / h.actionDuration)
+			continue
+		}
+
+		finished := now
+		a.Status = "success"
+		a.Progress = 100
+		a.Finished = &finished
+
+		if a.onSuccess != nil {
+			a.onSuccess()
+			a.onSuccess = nil
+		}
+	}
+}
+
+// checkNotLocked returns a locked error if an action is running for the resource.
+func (h *Handler) checkNotLocked(kind string, id int64) error {
+	for _, a := range h.actions {
+		if a.Status != "running" {
+			continue
+		}
+		if slices.Contains(a.Resources, resourceRef(kind, id)) {
+			return lockedError(kind)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) registerActionRoutes() {
+	h.route("GET /actions", h.listActions(""))
+	h.route("GET /actions/{id}", h.getAction(""))
+
+	for path, kind := range map[string]string{
+		"servers":        "server",
+		"volumes":        "volume",
+		"networks":       "network",
+		"firewalls":      "firewall",
+		"load_balancers": "load_balancer",
+		"primary_ips":    "primary_ip",
+		"zones":          "zone",
+	} {
+		h.route("GET /"+path+"/actions", h.listActions(kind))
+
+		// A single pattern is used for "/{resource}/actions/{id}" and
+		// "/{resource}/{id}/actions", as both patterns would conflict.
+		getAction, listResourceActions := h.getAction(kind), h.listResourceActions(kind)
+		h.route("GET /"+path+"/{first}/{second}", func(r *http.Request) (int, any, error) {
+			switch {
+			case r.PathValue("first") == "actions":
+				r.SetPathValue("id", r.PathValue("second"))
+				return getAction(r)
+			case r.PathValue("second") == "actions":
+				r.SetPathValue("id", r.PathValue("first"))
+				return listResourceActions(r)
+			default:
+				return 0, nil, notFound("route")
+			}
+		})
+	}
+}
+
+// actionOfKind returns whether the action references a resource of the given kind.
+func actionOfKind(a *action, kind string) bool {
+	if kind == "" {
+		return true
+	}
+	return slices.ContainsFunc(a.Resources, func(ref schema.ActionResourceReference) bool {
+		return ref.Type == kind
+	})
+}
+
+func (h *Handler) getAction(kind string) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		a, err := getByID(r, h.actions, "action")
+		if err != nil {
+			return 0, nil, err
+		}
+		if !actionOfKind(a, kind) {
+			return 0, nil, notFound("action")
+		}
+		return http.StatusOK, schema.ActionGetResponse{Action: a.Action}, nil
+	}
+}
+
+func (h *Handler) listActions(kind string) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		query := r.URL.Query()
+
+		// Listing actions requires a list of IDs since 30 January 2025.
+		if kind == "" && !query.Has("id") {
+			return 0, nil, invalidInput("id", "id is required")
+		}
+
+		ids := make([]int64, 0, len(query["id"]))
+		for _, value := range query["id"] {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, nil, invalidInput("id", "invalid id")
+			}
+			ids = append(ids, id)
+		}
+
+		return h.writeActionList(r, func(a *action) bool {
+			if !actionOfKind(a, kind) {
+				return false
+			}
+			if len(ids) > 0 && !slices.Contains(ids, a.ID) {
+				return false
+			}
+			if query.Has("status") && !slices.Contains(query["status"], a.Status) {
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func (h *Handler) listResourceActions(kind string) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		var id int64
+		if kind == "zone" {
+			// Zones may be referenced by ID or name.
+			zone, err := h.zoneByIDOrName(r)
+			if err != nil {
+				return 0, nil, err
+			}
+			id = zone.ID
+		} else {
+			var err error
+			id, err = pathID(r, "id")
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+		return h.writeActionList(r, func(a *action) bool {
+			return slices.Contains(a.Resources, resourceRef(kind, id))
+		})
+	}
+}
+
+func (h *Handler) writeActionList(r *http.Request, keep func(a *action) bool) (int, any, error) {
+	result := []schema.Action{}
+	for _, a := range sortedValues(h.actions) {
+		if keep(a) {
+			result = append(result, a.Action)
+		}
+	}
+	return listResponse(r, "actions", result)
+}
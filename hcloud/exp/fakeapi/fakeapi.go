@@ -0,0 +1,464 @@
+// Package fakeapi implements a stateful, in-memory fake of the Hetzner Cloud API.
+//
+// In contrast to the `mockutil` package, which replays a fixed list of HTTP exchanges,
+// the fake keeps track of the resources created through it, so a test may create a
+// server, list it and delete it without scripting every request by hand.
+//
+// The fake is plug-compatible with `hcloud.WithEndpoint`:
+//
+//	server := fakeapi.NewServer(t)
+//	client := hcloud.NewClient(hcloud.WithEndpoint(server.URL))
+//
+// Tests that do not need the [Server] use [NewClient] instead:
+//
+//	client := fakeapi.NewClient(t)
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package fakeapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// An Option is used to configure a [Handler].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Option func(*Handler)
+
+// WithActionDuration configures the time it takes for an action to go from
+// `running` to `success`. Defaults to 0, meaning that actions are completed on the
+// next request received by the [Handler].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func WithActionDuration(d time.Duration) Option {
+	return func(h *Handler) {
+		h.actionDuration = d
+	}
+}
+
+// WithClock configures the function used by the [Handler] to get the current time.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func WithClock(now func() time.Time) Option {
+	return func(h *Handler) {
+		h.now = now
+	}
+}
+
+// WithHandlerFunc registers a handler for the given [http.ServeMux] pattern, e.g. to
+// answer requests for resources not supported by the [Handler]. The pattern must not
+// conflict with the routes of the [Handler].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func WithHandlerFunc(pattern string, handler http.HandlerFunc) Option {
+	return func(h *Handler) {
+		h.mux.HandleFunc(pattern, handler)
+	}
+}
+
+// Handler is an [http.Handler] that answers Hetzner Cloud API requests from an
+// in-memory state.
+//
+// The following resources are supported: actions, servers, volumes, networks,
+// firewalls, load balancers, primary IPs, placement groups, zones and zone RRSets.
+//
+// A Handler must be created using the [NewHandler] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Handler struct {
+	mux *http.ServeMux
+	mu  sync.Mutex
+
+	now            func() time.Time
+	actionDuration time.Duration
+
+	lastID       int64
+	lastActionID int64
+	lastIP       int
+
+	actions         map[int64]*action
+	servers         map[int64]*schema.Server
+	volumes         map[int64]*schema.Volume
+	networks        map[int64]*schema.Network
+	firewalls       map[int64]*schema.Firewall
+	loadBalancers   map[int64]*schema.LoadBalancer
+	primaryIPs      map[int64]*schema.PrimaryIP
+	placementGroups map[int64]*schema.PlacementGroup
+	zones           map[int64]*schema.Zone
+	rrsets          map[int64]map[string]*schema.ZoneRRSet
+}
+
+// NewHandler returns a new [Handler] with an empty state.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewHandler(options ...Option) *Handler {
+	h := &Handler{
+		mux: http.NewServeMux(),
+		now: time.Now,
+
+		actions:         make(map[int64]*action),
+		servers:         make(map[int64]*schema.Server),
+		volumes:         make(map[int64]*schema.Volume),
+		networks:        make(map[int64]*schema.Network),
+		firewalls:       make(map[int64]*schema.Firewall),
+		loadBalancers:   make(map[int64]*schema.LoadBalancer),
+		primaryIPs:      make(map[int64]*schema.PrimaryIP),
+		placementGroups: make(map[int64]*schema.PlacementGroup),
+		zones:           make(map[int64]*schema.Zone),
+		rrsets:          make(map[int64]map[string]*schema.ZoneRRSet),
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	h.registerActionRoutes()
+	h.registerServerRoutes()
+	h.registerVolumeRoutes()
+	h.registerNetworkRoutes()
+	h.registerFirewallRoutes()
+	h.registerLoadBalancerRoutes()
+	h.registerPrimaryIPRoutes()
+	h.registerPlacementGroupRoutes()
+	h.registerZoneRoutes()
+
+	h.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, notFound(fmt.Sprintf("route %s %s", r.Method, r.URL.Path)))
+	})
+
+	return h
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// NewServer returns a new [Server] that closes itself at the end of the test.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewServer(t *testing.T, options ...Option) *Server {
+	t.Helper()
+
+	s := &Server{Handler: NewHandler(options...)}
+	s.Server = httptest.NewServer(s.Handler)
+	t.Cleanup(s.Server.Close)
+
+	return s
+}
+
+// Server embeds a [httptest.Server] that answers Hetzner Cloud API requests using a
+// [Handler].
+//
+// A Server must be created using the [NewServer] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Server struct {
+	*httptest.Server
+	Handler *Handler
+}
+
+// Client returns a new [hcloud.Client] using the server. Retries are disabled and
+// actions are polled every millisecond, the options are applied afterwards.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Server) Client(options ...hcloud.ClientOption) *hcloud.Client {
+	return hcloud.NewClient(append([]hcloud.ClientOption{
+		hcloud.WithEndpoint(s.URL),
+		hcloud.WithToken("token"),
+		hcloud.WithRetryOpts(hcloud.RetryOpts{BackoffFunc: hcloud.ConstantBackoff(0), MaxRetries: 0}),
+		hcloud.WithPollOpts(hcloud.PollOpts{BackoffFunc: hcloud.ConstantBackoff(time.Millisecond)}),
+	}, options...)...)
+}
+
+// NewClient returns a new [hcloud.Client] using a new [Server], see [Server.Client].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewClient(t *testing.T, options ...Option) *hcloud.Client {
+	t.Helper()
+
+	return NewServer(t, options...).Client()
+}
+
+// routeFunc handles a request and returns the status code and the body of the response.
+// A nil body produces an empty response.
+type routeFunc func(r *http.Request) (int, any, error)
+
+// route registers a [routeFunc] for the given pattern. The state is locked, and the
+// running actions are advanced before the [routeFunc] is called.
+func (h *Handler) route(pattern string, fn routeFunc) {
+	h.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		h.advanceActions()
+
+		status, body, err := fn(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, status, body)
+	})
+}
+
+func (h *Handler) nextID() int64 {
+	h.lastID++
+	return h.lastID
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// decodeBody decodes the JSON request body into v.
+func decodeBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    "json_error",
+			message: fmt.Sprintf("invalid JSON in request body: %s", err),
+		}
+	}
+	return nil
+}
+
+// pathID returns the integer ID found in the request path.
+func pathID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		return 0, invalidInput(name, "invalid id")
+	}
+	return id, nil
+}
+
+// getByID returns the item with the given path ID, or a not_found error.
+func getByID[T any](r *http.Request, items map[int64]*T, kind string) (*T, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	item, ok := items[id]
+	if !ok {
+		return nil, notFound(kind)
+	}
+	return item, nil
+}
+
+// resourceAction returns a [routeFunc] for an action on a resource. The resource is
+// fetched using the path ID, and must not be locked by another running action.
+func resourceAction[T any](h *Handler, items map[int64]*T, kind string, fn func(r *http.Request, item *T) (schema.Action, error)) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		item, err := getByID(r, items, kind)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		id, _ := pathID(r, "id")
+		if err := h.checkNotLocked(kind, id); err != nil {
+			return 0, nil, err
+		}
+
+		a, err := fn(r, item)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusCreated, schema.ActionGetResponse{Action: a}, nil
+	}
+}
+
+// sortedValues returns the items of the map sorted by ID.
+func sortedValues[T any](items map[int64]*T) []*T {
+	result := make([]*T, 0, len(items))
+	for _, id := range slices.Sorted(maps.Keys(items)) {
+		result = append(result, items[id])
+	}
+	return result
+}
+
+// listFilter is used to filter items from a list response using the request query.
+type listFilter[T any] struct {
+	name   func(*T) string
+	labels func(*T) map[string]string
+}
+
+// filterList filters items using the `name` and `label_selector` query parameters.
+func filterList[T any](r *http.Request, items []*T, filter listFilter[T]) ([]*T, error) {
+	query := r.URL.Query()
+
+	var selector labelSelector
+	if query.Has("label_selector") {
+		if filter.labels == nil {
+			return nil, invalidInput("label_selector", "label_selector is not supported")
+		}
+
+		var err error
+		selector, err = parseLabelSelector(query.Get("label_selector"))
+		if err != nil {
+			return nil, invalidInput("label_selector", err.Error())
+		}
+	}
+
+	result := make([]*T, 0, len(items))
+	for _, item := range items {
+		if query.Has("name") && filter.name != nil && filter.name(item) != query.Get("name") {
+			continue
+		}
+		if selector != nil && !selector.matches(filter.labels(item)) {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// paginate returns the requested page of the items, and the pagination meta.
+func paginate[T any](r *http.Request, items []T) ([]T, schema.Meta, error) {
+	query := r.URL.Query()
+
+	page, perPage := 1, 25
+	if query.Has("page") {
+		value, err := strconv.Atoi(query.Get("page"))
+		if err != nil || value < 1 {
+			return nil, schema.Meta{}, invalidInput("page", "must be a positive integer")
+		}
+		page = value
+	}
+	if query.Has("per_page") {
+		value, err := strconv.Atoi(query.Get("per_page"))
+		if err != nil || value < 1 {
+			return nil, schema.Meta{}, invalidInput("per_page", "must be a positive integer")
+		}
+		perPage = min(value, 50)
+	}
+
+	lastPage := max(1, (len(items)+perPage-1)/perPage)
+
+	pagination := &schema.MetaPagination{
+		Page:         page,
+		PerPage:      perPage,
+		LastPage:     lastPage,
+		TotalEntries: len(items),
+	}
+	if page > 1 {
+		pagination.PreviousPage = page - 1
+	}
+	if page < lastPage {
+		pagination.NextPage = page + 1
+	}
+
+	start := min(len(items), (page-1)*perPage)
+	end := min(len(items), start+perPage)
+
+	result := make([]T, 0, end-start)
+	result = append(result, items[start:end]...)
+
+	return result, schema.Meta{Pagination: pagination}, nil
+}
+
+// listResponse builds a paginated list response, with the items stored in the key
+// property.
+func listResponse[T any](r *http.Request, key string, items []T) (int, any, error) {
+	page, meta, err := paginate(r, items)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, map[string]any{key: page, "meta": meta}, nil
+}
+
+// labelsOrEmpty returns the labels from a request, or an empty map.
+func labelsOrEmpty(labels *map[string]string) map[string]string {
+	if labels == nil || *labels == nil {
+		return map[string]string{}
+	}
+	return maps.Clone(*labels)
+}
+
+// apiError is an error response returned by the [Handler].
+type apiError struct {
+	status  int
+	code    string
+	message string
+	details any
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.message, e.code)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{status: http.StatusInternalServerError, code: "server_error", message: err.Error()}
+	}
+
+	body := schema.ErrorResponse{Error: schema.Error{Code: e.code, Message: e.message}}
+	if e.details != nil {
+		body.Error.DetailsRaw, _ = json.Marshal(e.details)
+	}
+
+	writeJSON(w, e.status, body)
+}
+
+func notFound(kind string) error {
+	return &apiError{status: http.StatusNotFound, code: "not_found", message: kind + " not found"}
+}
+
+func invalidInput(field, message string) error {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    "invalid_input",
+		message: fmt.Sprintf("invalid input in field '%s'", field),
+		details: map[string]any{
+			"fields": []map[string]any{{"name": field, "messages": []string{message}}},
+		},
+	}
+}
+
+func uniquenessError(field string) error {
+	return &apiError{
+		status:  http.StatusConflict,
+		code:    "uniqueness_error",
+		message: fmt.Sprintf("%s is already used", field),
+		details: map[string]any{
+			"fields": []map[string]any{{"name": field}},
+		},
+	}
+}
+
+func protectedError(kind string) error {
+	return &apiError{status: http.StatusForbidden, code: "protected", message: kind + " is protected"}
+}
+
+func lockedError(kind string) error {
+	return &apiError{status: http.StatusLocked, code: "locked", message: kind + " is locked by a running action"}
+}
+
+func conflictError(code, message string) error {
+	return &apiError{status: http.StatusConflict, code: code, message: message}
+}
+
+func unprocessableError(code, message string) error {
+	return &apiError{status: http.StatusUnprocessableEntity, code: code, message: message}
+}
@@ -0,0 +1,196 @@
+package fakeapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func mustParseCIDR(t *testing.T, value string) *net.IPNet {
+	t.Helper()
+
+	_, ipNet, err := net.ParseCIDR(value)
+	require.NoError(t, err)
+	return ipNet
+}
+
+func TestHandlerNotFound(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	_, _, err := client.Server.DeleteWithResult(ctx, &hcloud.Server{ID: 42})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeNotFound))
+
+	server, _, err := client.Server.GetByID(ctx, 42)
+	require.NoError(t, err)
+	assert.Nil(t, server)
+}
+
+func TestHandlerUnknownRoute(t *testing.T) {
+	server := NewServer(t)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/unknown", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestHandlerPagination(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	for i := range 30 {
+		_, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+			Name:    fmt.Sprintf("network-%d", i),
+			IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+		})
+		require.NoError(t, err)
+	}
+
+	networks, resp, err := client.Network.List(ctx, hcloud.NetworkListOpts{ListOpts: hcloud.ListOpts{Page: 2, PerPage: 20}})
+	require.NoError(t, err)
+	assert.Len(t, networks, 10)
+	assert.Equal(t, "network-20", networks[0].Name)
+	assert.Equal(t, 2, resp.Meta.Pagination.Page)
+	assert.Equal(t, 1, resp.Meta.Pagination.PreviousPage)
+	assert.Equal(t, 0, resp.Meta.Pagination.NextPage)
+	assert.Equal(t, 2, resp.Meta.Pagination.LastPage)
+	assert.Equal(t, 30, resp.Meta.Pagination.TotalEntries)
+
+	all, err := client.Network.All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 30)
+}
+
+func TestHandlerLabelSelector(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	for name, labels := range map[string]map[string]string{
+		"a": {"env": "prod", "team": "web"},
+		"b": {"env": "dev", "team": "web"},
+		"c": {"env": "prod"},
+	} {
+		_, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+			Name:    name,
+			IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+			Labels:  labels,
+		})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "env=prod", want: []string{"a", "c"}},
+		{selector: "env!=prod", want: []string{"b"}},
+		{selector: "team", want: []string{"a", "b"}},
+		{selector: "!team", want: []string{"c"}},
+		{selector: "env in (dev, staging)", want: []string{"b"}},
+		{selector: "env=prod,team=web", want: []string{"a"}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.selector, func(t *testing.T) {
+			networks, err := client.Network.AllWithOpts(ctx, hcloud.NetworkListOpts{ListOpts: hcloud.ListOpts{LabelSelector: tt.selector}})
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(networks))
+			for _, network := range networks {
+				names = append(names, network.Name)
+			}
+			assert.ElementsMatch(t, tt.want, names)
+		})
+	}
+
+	_, err := client.Network.AllWithOpts(ctx, hcloud.NetworkListOpts{ListOpts: hcloud.ListOpts{LabelSelector: "env in prod)"}})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeInvalidInput))
+}
+
+func TestHandlerActionProgress(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := NewClient(t,
+		WithActionDuration(10*time.Second),
+		WithClock(func() time.Time { return now }),
+	)
+	ctx := context.Background()
+
+	result, _, err := client.Volume.Create(ctx, hcloud.VolumeCreateOpts{
+		Name:     "volume",
+		Size:     10,
+		Location: &hcloud.Location{Name: "fsn1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.ActionStatusRunning, result.Action.Status)
+	assert.Equal(t, hcloud.VolumeStatusCreating, result.Volume.Status)
+
+	now = now.Add(5 * time.Second)
+
+	action, _, err := client.Action.GetByID(ctx, result.Action.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.ActionStatusRunning, action.Status)
+	assert.Equal(t, 50, action.Progress)
+
+	_, err = client.Volume.Delete(ctx, result.Volume)
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeLocked))
+
+	now = now.Add(5 * time.Second)
+
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+	volume, _, err := client.Volume.GetByID(ctx, result.Volume.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.VolumeStatusAvailable, volume.Status)
+}
+
+func TestHandlerListActions(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	result, _, err := client.Volume.Create(ctx, hcloud.VolumeCreateOpts{
+		Name:     "volume",
+		Size:     10,
+		Location: &hcloud.Location{Name: "fsn1"},
+	})
+	require.NoError(t, err)
+
+	_, _, err = client.Action.List(ctx, hcloud.ActionListOpts{})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeInvalidInput))
+
+	actions, err := client.Action.AllWithOpts(ctx, hcloud.ActionListOpts{ID: []int64{result.Action.ID}})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, "create_volume", actions[0].Command)
+
+	actions, err = client.Volume.Action.All(ctx, hcloud.ActionListOpts{})
+	require.NoError(t, err)
+	assert.Len(t, actions, 1)
+
+	actions, err = client.Volume.Action.AllFor(ctx, result.Volume, hcloud.ActionListOpts{})
+	require.NoError(t, err)
+	assert.Len(t, actions, 1)
+
+	action, _, err := client.Volume.Action.GetByID(ctx, result.Action.ID)
+	require.NoError(t, err)
+	assert.Equal(t, result.Action.ID, action.ID)
+
+	action, _, err = client.Server.Action.GetByID(ctx, result.Action.ID)
+	require.NoError(t, err)
+	assert.Nil(t, action)
+}
@@ -0,0 +1,356 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerFirewallRoutes() {
+	h.route("GET /firewalls", h.listFirewalls)
+	h.route("POST /firewalls", h.createFirewall)
+	h.route("GET /firewalls/{id}", h.getFirewall)
+	h.route("PUT /firewalls/{id}", h.updateFirewall)
+	h.route("DELETE /firewalls/{id}", h.deleteFirewall)
+
+	h.route("POST /firewalls/{id}/actions/set_rules", h.firewallActions(h.firewallSetRules))
+	h.route("POST /firewalls/{id}/actions/apply_to_resources", h.firewallActions(h.firewallApplyToResources))
+	h.route("POST /firewalls/{id}/actions/remove_from_resources", h.firewallActions(h.firewallRemoveFromResources))
+}
+
+// firewallActions is similar to [resourceAction], but firewall actions respond with a
+// list of actions.
+func (h *Handler) firewallActions(fn func(r *http.Request, firewall *schema.Firewall) ([]schema.Action, error)) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		firewall, err := getByID(r, h.firewalls, "firewall")
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := h.checkNotLocked("firewall", firewall.ID); err != nil {
+			return 0, nil, err
+		}
+
+		actions, err := fn(r, firewall)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusCreated, map[string]any{"actions": actions}, nil
+	}
+}
+
+// firewallAppliedToServer returns whether the firewall is applied to the server,
+// either directly or through a label selector.
+func (h *Handler) firewallAppliedToServer(firewall *schema.Firewall, server *schema.Server) bool {
+	for _, resource := range firewall.AppliedTo {
+		switch resource.Type {
+		case "server":
+			if resource.Server.ID == server.ID {
+				return true
+			}
+		case "label_selector":
+			if matchLabelSelector(resource.LabelSelector.Selector, server.Labels) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderFirewall returns the firewall with the resources matched by the label
+// selectors.
+func (h *Handler) renderFirewall(firewall *schema.Firewall) schema.Firewall {
+	result := *firewall
+	result.Rules = slices.Clone(firewall.Rules)
+	result.AppliedTo = make([]schema.FirewallResource, 0, len(firewall.AppliedTo))
+
+	for _, resource := range firewall.AppliedTo {
+		if resource.Type == "label_selector" {
+			resource.AppliedToResources = []schema.FirewallResource{}
+			for _, server := range h.serversMatching(resource.LabelSelector.Selector) {
+				resource.AppliedToResources = append(resource.AppliedToResources, schema.FirewallResource{
+					Type:   "server",
+					Server: &schema.FirewallResourceServer{ID: server.ID},
+				})
+			}
+		}
+		result.AppliedTo = append(result.AppliedTo, resource)
+	}
+
+	return result
+}
+
+func (h *Handler) listFirewalls(r *http.Request) (int, any, error) {
+	firewalls, err := filterList(r, sortedValues(h.firewalls), listFilter[schema.Firewall]{
+		name:   func(o *schema.Firewall) string { return o.Name },
+		labels: func(o *schema.Firewall) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]schema.Firewall, 0, len(firewalls))
+	for _, firewall := range firewalls {
+		result = append(result, h.renderFirewall(firewall))
+	}
+	return listResponse(r, "firewalls", result)
+}
+
+func (h *Handler) getFirewall(r *http.Request) (int, any, error) {
+	firewall, err := getByID(r, h.firewalls, "firewall")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.FirewallGetResponse{Firewall: h.renderFirewall(firewall)}, nil
+}
+
+func (h *Handler) firewallNameUsed(name string) bool {
+	for _, firewall := range h.firewalls {
+		if firewall.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// firewallRules validates and converts the requested rules.
+func firewallRules(rules []schema.FirewallRuleRequest) ([]schema.FirewallRule, error) {
+	result := make([]schema.FirewallRule, 0, len(rules))
+
+	for i, rule := range rules {
+		field := fmt.Sprintf("rules[%d]", i)
+
+		switch rule.Direction {
+		case "in":
+			if len(rule.SourceIPs) == 0 {
+				return nil, invalidInput(field+".source_ips", "source_ips are required for incoming rules")
+			}
+		case "out":
+			if len(rule.DestinationIPs) == 0 {
+				return nil, invalidInput(field+".destination_ips", "destination_ips are required for outgoing rules")
+			}
+		default:
+			return nil, invalidInput(field+".direction", fmt.Sprintf("unknown direction '%s'", rule.Direction))
+		}
+
+		switch rule.Protocol {
+		case "tcp", "udp":
+			if rule.Port == nil {
+				return nil, invalidInput(field+".port", "port is required for tcp and udp rules")
+			}
+		case "icmp", "esp", "gre":
+			if rule.Port != nil {
+				return nil, invalidInput(field+".port", "port is only allowed for tcp and udp rules")
+			}
+		default:
+			return nil, invalidInput(field+".protocol", fmt.Sprintf("unknown protocol '%s'", rule.Protocol))
+		}
+
+		for _, ip := range slices.Concat(rule.SourceIPs, rule.DestinationIPs) {
+			if _, err := netip.ParsePrefix(ip); err != nil {
+				return nil, invalidInput(field, fmt.Sprintf("invalid CIDR '%s'", ip))
+			}
+		}
+
+		sourceIPs, destinationIPs := rule.SourceIPs, rule.DestinationIPs
+		if sourceIPs == nil {
+			sourceIPs = []string{}
+		}
+		if destinationIPs == nil {
+			destinationIPs = []string{}
+		}
+
+		result = append(result, schema.FirewallRule{
+			Direction:      rule.Direction,
+			SourceIPs:      sourceIPs,
+			DestinationIPs: destinationIPs,
+			Protocol:       rule.Protocol,
+			Port:           rule.Port,
+			Description:    rule.Description,
+		})
+	}
+
+	return result, nil
+}
+
+// applyFirewall applies the firewall to the resources, and returns an action per
+// resource.
+func (h *Handler) applyFirewall(firewall *schema.Firewall, resources []schema.FirewallResource) ([]schema.Action, error) {
+	for _, resource := range resources {
+		switch resource.Type {
+		case "server":
+			if resource.Server == nil {
+				return nil, invalidInput("server", "server is required")
+			}
+			if _, ok := h.servers[resource.Server.ID]; !ok {
+				return nil, unprocessableError("firewall_resource_not_found", "server not found")
+			}
+			if slices.ContainsFunc(firewall.AppliedTo, func(o schema.FirewallResource) bool {
+				return o.Server != nil && o.Server.ID == resource.Server.ID
+			}) {
+				return nil, conflictError("firewall_already_applied", "firewall is already applied to the server")
+			}
+		case "label_selector":
+			if resource.LabelSelector == nil {
+				return nil, invalidInput("label_selector", "label_selector is required")
+			}
+			if _, err := parseLabelSelector(resource.LabelSelector.Selector); err != nil {
+				return nil, invalidInput("label_selector", err.Error())
+			}
+			if slices.ContainsFunc(firewall.AppliedTo, func(o schema.FirewallResource) bool {
+				return o.LabelSelector != nil && o.LabelSelector.Selector == resource.LabelSelector.Selector
+			}) {
+				return nil, conflictError("firewall_already_applied", "firewall is already applied to the label selector")
+			}
+		default:
+			return nil, invalidInput("type", fmt.Sprintf("unknown resource type '%s'", resource.Type))
+		}
+	}
+
+	actions := make([]schema.Action, 0, len(resources))
+	for _, resource := range resources {
+		refs := []schema.ActionResourceReference{resourceRef("firewall", firewall.ID)}
+		if resource.Server != nil {
+			refs = append(refs, resourceRef("server", resource.Server.ID))
+		}
+
+		resource.AppliedToResources = nil
+		firewall.AppliedTo = append(firewall.AppliedTo, resource)
+		actions = append(actions, h.newAction("apply_firewall", refs, nil))
+	}
+	return actions, nil
+}
+
+func (h *Handler) createFirewall(r *http.Request) (int, any, error) {
+	var req schema.FirewallCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case h.firewallNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	rules, err := firewallRules(req.Rules)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	firewall := &schema.Firewall{
+		ID:        h.nextID(),
+		Name:      req.Name,
+		Labels:    labelsOrEmpty(req.Labels),
+		Created:   h.now(),
+		Rules:     rules,
+		AppliedTo: []schema.FirewallResource{},
+	}
+
+	actions, err := h.applyFirewall(firewall, req.ApplyTo)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	h.firewalls[firewall.ID] = firewall
+
+	return http.StatusCreated, schema.FirewallCreateResponse{
+		Firewall: h.renderFirewall(firewall),
+		Actions:  actions,
+	}, nil
+}
+
+func (h *Handler) updateFirewall(r *http.Request) (int, any, error) {
+	firewall, err := getByID(r, h.firewalls, "firewall")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.FirewallUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != nil && *req.Name != firewall.Name {
+		if h.firewallNameUsed(*req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		firewall.Name = *req.Name
+	}
+	if req.Labels != nil {
+		firewall.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.FirewallUpdateResponse{Firewall: h.renderFirewall(firewall)}, nil
+}
+
+func (h *Handler) deleteFirewall(r *http.Request) (int, any, error) {
+	firewall, err := getByID(r, h.firewalls, "firewall")
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(firewall.AppliedTo) > 0 {
+		return 0, nil, conflictError("resource_in_use", "firewall must not be in use to be deleted")
+	}
+
+	delete(h.firewalls, firewall.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) firewallSetRules(r *http.Request, firewall *schema.Firewall) ([]schema.Action, error) {
+	var req schema.FirewallActionSetRulesRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	rules, err := firewallRules(req.Rules)
+	if err != nil {
+		return nil, err
+	}
+	firewall.Rules = rules
+
+	return []schema.Action{
+		h.newAction("set_firewall_rules", []schema.ActionResourceReference{resourceRef("firewall", firewall.ID)}, nil),
+	}, nil
+}
+
+func (h *Handler) firewallApplyToResources(r *http.Request, firewall *schema.Firewall) ([]schema.Action, error) {
+	var req schema.FirewallActionApplyToResourcesRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	return h.applyFirewall(firewall, req.ApplyTo)
+}
+
+func (h *Handler) firewallRemoveFromResources(r *http.Request, firewall *schema.Firewall) ([]schema.Action, error) {
+	var req schema.FirewallActionRemoveFromResourcesRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	actions := make([]schema.Action, 0, len(req.RemoveFrom))
+	for _, resource := range req.RemoveFrom {
+		refs := []schema.ActionResourceReference{resourceRef("firewall", firewall.ID)}
+
+		switch {
+		case resource.Server != nil:
+			refs = append(refs, resourceRef("server", resource.Server.ID))
+			firewall.AppliedTo = slices.DeleteFunc(firewall.AppliedTo, func(o schema.FirewallResource) bool {
+				return o.Server != nil && o.Server.ID == resource.Server.ID
+			})
+		case resource.LabelSelector != nil:
+			firewall.AppliedTo = slices.DeleteFunc(firewall.AppliedTo, func(o schema.FirewallResource) bool {
+				return o.LabelSelector != nil && o.LabelSelector.Selector == resource.LabelSelector.Selector
+			})
+		default:
+			return nil, invalidInput("remove_from", "one of server or label_selector is required")
+		}
+
+		actions = append(actions, h.newAction("remove_firewall", refs, nil))
+	}
+	return actions, nil
+}
@@ -0,0 +1,152 @@
+package fakeapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// labelSelector is a parsed label selector, a resource matches the selector when all
+// the requirements are met.
+type labelSelector []labelRequirement
+
+type labelOperator string
+
+const (
+	labelOpEquals    labelOperator = "="
+	labelOpNotEquals labelOperator = "!="
+	labelOpIn        labelOperator = "in"
+	labelOpNotIn     labelOperator = "notin"
+	labelOpExists    labelOperator = "exists"
+	labelOpNotExists labelOperator = "!exists"
+)
+
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	values   []string
+}
+
+// parseLabelSelector parses a label selector as described in
+// https://docs.hetzner.cloud/reference/cloud#label-selector.
+func parseLabelSelector(value string) (labelSelector, error) {
+	result := labelSelector{}
+
+	for _, part := range splitLabelSelector(value) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty requirement in label selector '%s'", value)
+		}
+
+		req, err := parseLabelRequirement(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, req)
+	}
+
+	return result, nil
+}
+
+// splitLabelSelector splits the label selector at commas that are not enclosed in
+// parentheses.
+func splitLabelSelector(value string) []string {
+	var result []string
+
+	depth, start := 0, 0
+	for i, c := range value {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, value[start:])
+}
+
+func parseLabelRequirement(value string) (labelRequirement, error) {
+	if key, values, ok := cutSetOperator(value, " notin "); ok {
+		return labelRequirement{key: key, operator: labelOpNotIn, values: values}, nil
+	}
+	if key, values, ok := cutSetOperator(value, " in "); ok {
+		return labelRequirement{key: key, operator: labelOpIn, values: values}, nil
+	}
+	if key, v, ok := strings.Cut(value, "!="); ok {
+		return labelRequirement{key: strings.TrimSpace(key), operator: labelOpNotEquals, values: []string{strings.TrimSpace(v)}}, nil
+	}
+	if key, v, ok := strings.Cut(value, "=="); ok {
+		return labelRequirement{key: strings.TrimSpace(key), operator: labelOpEquals, values: []string{strings.TrimSpace(v)}}, nil
+	}
+	if key, v, ok := strings.Cut(value, "="); ok {
+		return labelRequirement{key: strings.TrimSpace(key), operator: labelOpEquals, values: []string{strings.TrimSpace(v)}}, nil
+	}
+	if key, ok := strings.CutPrefix(value, "!"); ok {
+		return labelRequirement{key: strings.TrimSpace(key), operator: labelOpNotExists}, nil
+	}
+	if strings.ContainsAny(value, " ()") {
+		return labelRequirement{}, fmt.Errorf("invalid requirement '%s' in label selector", value)
+	}
+	return labelRequirement{key: value, operator: labelOpExists}, nil
+}
+
+// cutSetOperator parses set based requirements, e.g. `key in (a, b)`.
+func cutSetOperator(value, operator string) (string, []string, bool) {
+	key, rest, ok := strings.Cut(value, operator)
+	if !ok {
+		return "", nil, false
+	}
+
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+		return "", nil, false
+	}
+
+	values := strings.Split(strings.Trim(rest, "()"), ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return strings.TrimSpace(key), values, true
+}
+
+// matches returns whether the labels fulfill all the requirements of the selector.
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, exists := labels[req.key]
+
+		var ok bool
+		switch req.operator {
+		case labelOpEquals:
+			ok = exists && value == req.values[0]
+		case labelOpNotEquals:
+			ok = !exists || value != req.values[0]
+		case labelOpIn:
+			ok = exists && slices.Contains(req.values, value)
+		case labelOpNotIn:
+			ok = !exists || !slices.Contains(req.values, value)
+		case labelOpExists:
+			ok = exists
+		case labelOpNotExists:
+			ok = !exists
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchLabelSelector returns whether the labels match the given label selector. Invalid
+// label selectors never match.
+func matchLabelSelector(selector string, labels map[string]string) bool {
+	parsed, err := parseLabelSelector(selector)
+	if err != nil {
+		return false
+	}
+	return parsed.matches(labels)
+}
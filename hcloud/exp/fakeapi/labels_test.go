@@ -0,0 +1,40 @@
+package fakeapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	labels := map[string]string{"env": "prod", "team": "web"}
+
+	testCases := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "env=prod", want: true},
+		{selector: "env==prod", want: true},
+		{selector: "env!=prod", want: false},
+		{selector: "env", want: true},
+		{selector: "!env", want: false},
+		{selector: "!missing", want: true},
+		{selector: "env in (prod,dev)", want: true},
+		{selector: "env notin (prod, dev)", want: false},
+		{selector: "env=prod, team in (web)", want: true},
+		{selector: "env=prod,team=api", want: false},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := parseLabelSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, selector.matches(labels))
+		})
+	}
+
+	for _, value := range []string{"", "env=prod,", "env in prod)"} {
+		_, err := parseLabelSelector(value)
+		assert.Error(t, err, value)
+	}
+}
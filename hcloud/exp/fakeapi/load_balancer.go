@@ -0,0 +1,739 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerLoadBalancerRoutes() {
+	h.route("GET /load_balancers", h.listLoadBalancers)
+	h.route("POST /load_balancers", h.createLoadBalancer)
+	h.route("GET /load_balancers/{id}", h.getLoadBalancer)
+	h.route("PUT /load_balancers/{id}", h.updateLoadBalancer)
+	h.route("DELETE /load_balancers/{id}", h.deleteLoadBalancer)
+
+	h.route("POST /load_balancers/{id}/actions/add_target", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerAddTarget))
+	h.route("POST /load_balancers/{id}/actions/remove_target", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerRemoveTarget))
+	h.route("POST /load_balancers/{id}/actions/add_service", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerAddService))
+	h.route("POST /load_balancers/{id}/actions/update_service", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerUpdateService))
+	h.route("POST /load_balancers/{id}/actions/delete_service", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerDeleteService))
+	h.route("POST /load_balancers/{id}/actions/change_algorithm", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerChangeAlgorithm))
+	h.route("POST /load_balancers/{id}/actions/change_type", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerChangeType))
+	h.route("POST /load_balancers/{id}/actions/change_protection", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerChangeProtection))
+	h.route("POST /load_balancers/{id}/actions/change_dns_ptr", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerChangeDNSPtr))
+	h.route("POST /load_balancers/{id}/actions/attach_to_network", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerAttachToNetwork))
+	h.route("POST /load_balancers/{id}/actions/detach_from_network", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerDetachFromNetwork))
+	h.route("POST /load_balancers/{id}/actions/enable_public_interface", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerSetPublicInterface("enable_public_interface", true)))
+	h.route("POST /load_balancers/{id}/actions/disable_public_interface", resourceAction(h, h.loadBalancers, "load_balancer", h.loadBalancerSetPublicInterface("disable_public_interface", false)))
+}
+
+// expandLoadBalancerTargets returns the server and IP targets of the load balancer,
+// including the servers matched by the label selector targets.
+func (h *Handler) expandLoadBalancerTargets(lb *schema.LoadBalancer) []schema.LoadBalancerTarget {
+	result := []schema.LoadBalancerTarget{}
+	for _, target := range lb.Targets {
+		if target.Type != "label_selector" {
+			result = append(result, target)
+			continue
+		}
+		for _, server := range h.serversMatching(target.LabelSelector.Selector) {
+			result = append(result, schema.LoadBalancerTarget{
+				Type:         "server",
+				Server:       &schema.LoadBalancerTargetServer{ID: server.ID},
+				UsePrivateIP: target.UsePrivateIP,
+			})
+		}
+	}
+	return result
+}
+
+// targetHealthStatus returns the health status of a target for every service. Server
+// targets are healthy when the server is running, IP targets are always healthy.
+func (h *Handler) targetHealthStatus(lb *schema.LoadBalancer, target schema.LoadBalancerTarget) []schema.LoadBalancerTargetHealthStatus {
+	status := "healthy"
+	if target.Server != nil {
+		if server, ok := h.servers[target.Server.ID]; !ok || server.Status != "running" {
+			status = "unhealthy"
+		}
+	}
+
+	result := make([]schema.LoadBalancerTargetHealthStatus, 0, len(lb.Services))
+	for _, service := range lb.Services {
+		result = append(result, schema.LoadBalancerTargetHealthStatus{ListenPort: service.ListenPort, Status: status})
+	}
+	return result
+}
+
+// renderLoadBalancer returns the load balancer with the targets matched by the label
+// selectors and the health status of the targets.
+func (h *Handler) renderLoadBalancer(lb *schema.LoadBalancer) schema.LoadBalancer {
+	result := *lb
+	result.PrivateNet = slices.Clone(lb.PrivateNet)
+	result.Services = slices.Clone(lb.Services)
+	result.Targets = make([]schema.LoadBalancerTarget, 0, len(lb.Targets))
+
+	for _, target := range lb.Targets {
+		if target.Type == "label_selector" {
+			target.Targets = []schema.LoadBalancerTarget{}
+			for _, server := range h.serversMatching(target.LabelSelector.Selector) {
+				serverTarget := schema.LoadBalancerTarget{
+					Type:         "server",
+					Server:       &schema.LoadBalancerTargetServer{ID: server.ID},
+					UsePrivateIP: target.UsePrivateIP,
+				}
+				serverTarget.HealthStatus = h.targetHealthStatus(lb, serverTarget)
+				target.Targets = append(target.Targets, serverTarget)
+			}
+		} else {
+			target.HealthStatus = h.targetHealthStatus(lb, target)
+		}
+		result.Targets = append(result.Targets, target)
+	}
+
+	return result
+}
+
+func (h *Handler) listLoadBalancers(r *http.Request) (int, any, error) {
+	lbs, err := filterList(r, sortedValues(h.loadBalancers), listFilter[schema.LoadBalancer]{
+		name:   func(o *schema.LoadBalancer) string { return o.Name },
+		labels: func(o *schema.LoadBalancer) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]schema.LoadBalancer, 0, len(lbs))
+	for _, lb := range lbs {
+		result = append(result, h.renderLoadBalancer(lb))
+	}
+	return listResponse(r, "load_balancers", result)
+}
+
+func (h *Handler) getLoadBalancer(r *http.Request) (int, any, error) {
+	lb, err := getByID(r, h.loadBalancers, "load_balancer")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.LoadBalancerGetResponse{LoadBalancer: h.renderLoadBalancer(lb)}, nil
+}
+
+func (h *Handler) loadBalancerNameUsed(name string) bool {
+	for _, lb := range h.loadBalancers {
+		if lb.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) createLoadBalancer(r *http.Request) (int, any, error) {
+	var req schema.LoadBalancerCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case req.LoadBalancerType.ID == 0 && req.LoadBalancerType.Name == "":
+		return 0, nil, invalidInput("load_balancer_type", "load_balancer_type is required")
+	case req.Location == nil && req.NetworkZone == nil:
+		return 0, nil, invalidInput("location", "one of location or network_zone is required")
+	case h.loadBalancerNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	var location schema.Location
+	var err error
+	if req.Location != nil {
+		location, err = findLocation(*req.Location)
+	} else {
+		location, err = findLocationByNetworkZone(*req.NetworkZone)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var network *schema.Network
+	if req.Network != nil {
+		var ok bool
+		network, ok = h.networks[*req.Network]
+		if !ok {
+			return 0, nil, notFound("network")
+		}
+	}
+
+	algorithm := "round_robin"
+	if req.Algorithm != nil {
+		algorithm = req.Algorithm.Type
+	}
+	if err := validateAlgorithm(algorithm); err != nil {
+		return 0, nil, err
+	}
+
+	lbTypeID, lbTypeName := typeID(req.LoadBalancerType)
+
+	lb := &schema.LoadBalancer{
+		ID:               h.nextID(),
+		Name:             req.Name,
+		Location:         location,
+		LoadBalancerType: schema.LoadBalancerType{ID: lbTypeID, Name: lbTypeName},
+		Labels:           labelsOrEmpty(req.Labels),
+		Created:          h.now(),
+		Algorithm:        schema.LoadBalancerAlgorithm{Type: algorithm},
+		PrivateNet:       []schema.LoadBalancerPrivateNet{},
+		Services:         []schema.LoadBalancerService{},
+		Targets:          []schema.LoadBalancerTarget{},
+		PublicNet: schema.LoadBalancerPublicNet{
+			Enabled: req.PublicInterface == nil || *req.PublicInterface,
+			IPv4:    schema.LoadBalancerPublicNetIPv4{IP: h.nextPublicIPv4()},
+			IPv6:    schema.LoadBalancerPublicNetIPv6{IP: netip.MustParsePrefix(h.nextPublicIPv6()).Addr().Next().String()},
+		},
+	}
+
+	if network != nil {
+		ip, err := h.allocateNetworkIP(network, "", "")
+		if err != nil {
+			return 0, nil, err
+		}
+		lb.PrivateNet = append(lb.PrivateNet, schema.LoadBalancerPrivateNet{Network: network.ID, IP: ip})
+	}
+
+	for _, service := range req.Services {
+		if err := addLoadBalancerService(lb, schema.LoadBalancerActionAddServiceRequest{
+			Protocol:        service.Protocol,
+			ListenPort:      service.ListenPort,
+			DestinationPort: service.DestinationPort,
+			Proxyprotocol:   service.Proxyprotocol,
+			HTTP:            (*schema.LoadBalancerActionAddServiceRequestHTTP)(service.HTTP),
+			HealthCheck:     loadBalancerCreateHealthCheck(service.HealthCheck),
+		}); err != nil {
+			return 0, nil, err
+		}
+	}
+	for _, target := range req.Targets {
+		if err := h.addLoadBalancerTarget(lb, schema.LoadBalancerActionAddTargetRequest{
+			Type:          target.Type,
+			Server:        (*schema.LoadBalancerActionAddTargetRequestServer)(target.Server),
+			LabelSelector: (*schema.LoadBalancerActionAddTargetRequestLabelSelector)(target.LabelSelector),
+			IP:            (*schema.LoadBalancerActionAddTargetRequestIP)(target.IP),
+			UsePrivateIP:  target.UsePrivateIP,
+		}); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	h.loadBalancers[lb.ID] = lb
+
+	a := h.newAction("create_load_balancer", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil)
+
+	return http.StatusCreated, schema.LoadBalancerCreateResponse{
+		LoadBalancer: h.renderLoadBalancer(lb),
+		Action:       a,
+	}, nil
+}
+
+// loadBalancerCreateHealthCheck converts the health check of a create request to the
+// health check of an add service request.
+func loadBalancerCreateHealthCheck(hc *schema.LoadBalancerCreateRequestServiceHealthCheck) *schema.LoadBalancerActionAddServiceRequestHealthCheck {
+	if hc == nil {
+		return nil
+	}
+	return &schema.LoadBalancerActionAddServiceRequestHealthCheck{
+		Protocol: hc.Protocol,
+		Port:     hc.Port,
+		Interval: hc.Interval,
+		Timeout:  hc.Timeout,
+		Retries:  hc.Retries,
+		HTTP:     (*schema.LoadBalancerActionAddServiceRequestHealthCheckHTTP)(hc.HTTP),
+	}
+}
+
+func (h *Handler) updateLoadBalancer(r *http.Request) (int, any, error) {
+	lb, err := getByID(r, h.loadBalancers, "load_balancer")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.LoadBalancerUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != nil && *req.Name != lb.Name {
+		if h.loadBalancerNameUsed(*req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		lb.Name = *req.Name
+	}
+	if req.Labels != nil {
+		lb.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.LoadBalancerUpdateResponse{LoadBalancer: h.renderLoadBalancer(lb)}, nil
+}
+
+func (h *Handler) deleteLoadBalancer(r *http.Request) (int, any, error) {
+	lb, err := getByID(r, h.loadBalancers, "load_balancer")
+	if err != nil {
+		return 0, nil, err
+	}
+	if lb.Protection.Delete {
+		return 0, nil, protectedError("load_balancer")
+	}
+	if err := h.checkNotLocked("load_balancer", lb.ID); err != nil {
+		return 0, nil, err
+	}
+
+	delete(h.loadBalancers, lb.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+func validateAlgorithm(algorithm string) error {
+	switch algorithm {
+	case "round_robin", "least_connections":
+		return nil
+	default:
+		return invalidInput("algorithm", fmt.Sprintf("unknown algorithm '%s'", algorithm))
+	}
+}
+
+// addLoadBalancerTarget validates and adds a target to the load balancer.
+func (h *Handler) addLoadBalancerTarget(lb *schema.LoadBalancer, req schema.LoadBalancerActionAddTargetRequest) error {
+	target := schema.LoadBalancerTarget{
+		Type:         req.Type,
+		UsePrivateIP: req.UsePrivateIP != nil && *req.UsePrivateIP,
+	}
+
+	switch req.Type {
+	case "server":
+		if req.Server == nil {
+			return invalidInput("server", "server is required")
+		}
+		server, ok := h.servers[req.Server.ID]
+		if !ok {
+			return unprocessableError("load_balancer_target_not_found", "server not found")
+		}
+		if target.UsePrivateIP && !slices.ContainsFunc(server.PrivateNet, func(o schema.ServerPrivateNet) bool {
+			return slices.ContainsFunc(lb.PrivateNet, func(p schema.LoadBalancerPrivateNet) bool { return p.Network == o.Network })
+		}) {
+			return unprocessableError("load_balancer_not_attached_to_network", "load balancer and server must share a network")
+		}
+		target.Server = &schema.LoadBalancerTargetServer{ID: server.ID}
+	case "label_selector":
+		if req.LabelSelector == nil {
+			return invalidInput("label_selector", "label_selector is required")
+		}
+		if _, err := parseLabelSelector(req.LabelSelector.Selector); err != nil {
+			return invalidInput("label_selector", err.Error())
+		}
+		target.LabelSelector = &schema.LoadBalancerTargetLabelSelector{Selector: req.LabelSelector.Selector}
+	case "ip":
+		if req.IP == nil {
+			return invalidInput("ip", "ip is required")
+		}
+		if _, err := netip.ParseAddr(req.IP.IP); err != nil {
+			return invalidInput("ip", "ip must be a valid IP")
+		}
+		target.IP = &schema.LoadBalancerTargetIP{IP: req.IP.IP}
+		target.UsePrivateIP = false
+	default:
+		return invalidInput("type", fmt.Sprintf("unknown target type '%s'", req.Type))
+	}
+
+	if slices.ContainsFunc(lb.Targets, func(o schema.LoadBalancerTarget) bool { return sameLoadBalancerTarget(o, target) }) {
+		return conflictError("target_already_defined", "target is already defined")
+	}
+
+	lb.Targets = append(lb.Targets, target)
+	return nil
+}
+
+func sameLoadBalancerTarget(a, b schema.LoadBalancerTarget) bool {
+	switch {
+	case a.Type != b.Type:
+		return false
+	case a.Server != nil:
+		return a.Server.ID == b.Server.ID
+	case a.LabelSelector != nil:
+		return a.LabelSelector.Selector == b.LabelSelector.Selector
+	case a.IP != nil:
+		return a.IP.IP == b.IP.IP
+	}
+	return false
+}
+
+// addLoadBalancerService validates and adds a service to the load balancer, the
+// defaults are the same as the ones used by the API.
+func addLoadBalancerService(lb *schema.LoadBalancer, req schema.LoadBalancerActionAddServiceRequest) error {
+	service := schema.LoadBalancerService{Protocol: req.Protocol}
+
+	switch req.Protocol {
+	case "tcp":
+		if req.ListenPort == nil {
+			return invalidInput("listen_port", "listen_port is required for tcp services")
+		}
+		service.ListenPort = *req.ListenPort
+	case "http":
+		service.ListenPort = 80
+	case "https":
+		service.ListenPort = 443
+	default:
+		return invalidInput("protocol", fmt.Sprintf("unknown protocol '%s'", req.Protocol))
+	}
+	if req.ListenPort != nil {
+		service.ListenPort = *req.ListenPort
+	}
+
+	service.DestinationPort = service.ListenPort
+	if req.Protocol == "https" {
+		service.DestinationPort = 80
+	}
+	if req.DestinationPort != nil {
+		service.DestinationPort = *req.DestinationPort
+	}
+	if req.Proxyprotocol != nil {
+		service.Proxyprotocol = *req.Proxyprotocol
+	}
+
+	if req.Protocol != "tcp" {
+		service.HTTP = &schema.LoadBalancerServiceHTTP{
+			CookieName:     "HCLBSTICKY",
+			CookieLifetime: 300,
+			Certificates:   []int64{},
+			TimeoutIdle:    60,
+		}
+		if req.HTTP != nil {
+			applyServiceHTTP(service.HTTP, (*schema.LoadBalancerActionUpdateServiceRequestHTTP)(req.HTTP))
+		}
+	}
+
+	healthCheckProtocol := "tcp"
+	if req.HealthCheck != nil {
+		healthCheckProtocol = req.HealthCheck.Protocol
+	}
+	service.HealthCheck = &schema.LoadBalancerServiceHealthCheck{
+		Protocol: healthCheckProtocol,
+		Port:     service.DestinationPort,
+		Interval: 15,
+		Timeout:  10,
+		Retries:  3,
+	}
+	if req.HealthCheck != nil {
+		applyServiceHealthCheck(service.HealthCheck, &schema.LoadBalancerActionUpdateServiceRequestHealthCheck{
+			Port:     req.HealthCheck.Port,
+			Interval: req.HealthCheck.Interval,
+			Timeout:  req.HealthCheck.Timeout,
+			Retries:  req.HealthCheck.Retries,
+			HTTP:     (*schema.LoadBalancerActionUpdateServiceRequestHealthCheckHTTP)(req.HealthCheck.HTTP),
+		})
+	}
+
+	if slices.ContainsFunc(lb.Services, func(o schema.LoadBalancerService) bool { return o.ListenPort == service.ListenPort }) {
+		return conflictError("source_port_already_used", fmt.Sprintf("listen port %d is already used", service.ListenPort))
+	}
+
+	lb.Services = append(lb.Services, service)
+	return nil
+}
+
+func applyServiceHTTP(o *schema.LoadBalancerServiceHTTP, req *schema.LoadBalancerActionUpdateServiceRequestHTTP) {
+	if req.CookieName != nil {
+		o.CookieName = *req.CookieName
+	}
+	if req.CookieLifetime != nil {
+		o.CookieLifetime = *req.CookieLifetime
+	}
+	if req.Certificates != nil {
+		o.Certificates = slices.Clone(*req.Certificates)
+	}
+	if req.RedirectHTTP != nil {
+		o.RedirectHTTP = *req.RedirectHTTP
+	}
+	if req.StickySessions != nil {
+		o.StickySessions = *req.StickySessions
+	}
+	if req.TimeoutIdle != nil {
+		o.TimeoutIdle = *req.TimeoutIdle
+	}
+}
+
+func applyServiceHealthCheck(hc *schema.LoadBalancerServiceHealthCheck, req *schema.LoadBalancerActionUpdateServiceRequestHealthCheck) {
+	if req.Protocol != nil {
+		hc.Protocol = *req.Protocol
+	}
+	if req.Port != nil {
+		hc.Port = *req.Port
+	}
+	if req.Interval != nil {
+		hc.Interval = *req.Interval
+	}
+	if req.Timeout != nil {
+		hc.Timeout = *req.Timeout
+	}
+	if req.Retries != nil {
+		hc.Retries = *req.Retries
+	}
+	if req.HTTP != nil {
+		if hc.HTTP == nil {
+			hc.HTTP = &schema.LoadBalancerServiceHealthCheckHTTP{Path: "/", StatusCodes: []string{"2??", "3??"}}
+		}
+		if req.HTTP.Domain != nil {
+			hc.HTTP.Domain = *req.HTTP.Domain
+		}
+		if req.HTTP.Path != nil {
+			hc.HTTP.Path = *req.HTTP.Path
+		}
+		if req.HTTP.Response != nil {
+			hc.HTTP.Response = *req.HTTP.Response
+		}
+		if req.HTTP.StatusCodes != nil {
+			hc.HTTP.StatusCodes = slices.Clone(*req.HTTP.StatusCodes)
+		}
+		if req.HTTP.TLS != nil {
+			hc.HTTP.TLS = *req.HTTP.TLS
+		}
+	}
+}
+
+func (h *Handler) loadBalancerAddTarget(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionAddTargetRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if err := h.addLoadBalancerTarget(lb, req); err != nil {
+		return schema.Action{}, err
+	}
+
+	resources := []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}
+	if req.Server != nil {
+		resources = append(resources, resourceRef("server", req.Server.ID))
+	}
+	return h.newAction("add_target", resources, nil), nil
+}
+
+func (h *Handler) loadBalancerRemoveTarget(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionRemoveTargetRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	target := schema.LoadBalancerTarget{Type: req.Type}
+	resources := []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}
+	switch {
+	case req.Server != nil:
+		target.Server = &schema.LoadBalancerTargetServer{ID: req.Server.ID}
+		resources = append(resources, resourceRef("server", req.Server.ID))
+	case req.LabelSelector != nil:
+		target.LabelSelector = &schema.LoadBalancerTargetLabelSelector{Selector: req.LabelSelector.Selector}
+	case req.IP != nil:
+		target.IP = &schema.LoadBalancerTargetIP{IP: req.IP.IP}
+	default:
+		return schema.Action{}, invalidInput("type", "one of server, label_selector or ip is required")
+	}
+
+	index := slices.IndexFunc(lb.Targets, func(o schema.LoadBalancerTarget) bool { return sameLoadBalancerTarget(o, target) })
+	if index < 0 {
+		return schema.Action{}, notFound("target")
+	}
+	lb.Targets = slices.Delete(lb.Targets, index, index+1)
+
+	return h.newAction("remove_target", resources, nil), nil
+}
+
+func (h *Handler) loadBalancerAddService(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionAddServiceRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if err := addLoadBalancerService(lb, req); err != nil {
+		return schema.Action{}, err
+	}
+	return h.newAction("add_service", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+}
+
+func (h *Handler) loadBalancerUpdateService(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionUpdateServiceRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.IndexFunc(lb.Services, func(o schema.LoadBalancerService) bool { return o.ListenPort == req.ListenPort })
+	if index < 0 {
+		return schema.Action{}, notFound("service")
+	}
+	service := &lb.Services[index]
+
+	if req.Protocol != nil {
+		switch *req.Protocol {
+		case "tcp":
+			service.HTTP = nil
+		case "http", "https":
+			if service.HTTP == nil {
+				service.HTTP = &schema.LoadBalancerServiceHTTP{CookieName: "HCLBSTICKY", CookieLifetime: 300, Certificates: []int64{}, TimeoutIdle: 60}
+			}
+		default:
+			return schema.Action{}, invalidInput("protocol", fmt.Sprintf("unknown protocol '%s'", *req.Protocol))
+		}
+		service.Protocol = *req.Protocol
+	}
+	if req.DestinationPort != nil {
+		service.DestinationPort = *req.DestinationPort
+	}
+	if req.Proxyprotocol != nil {
+		service.Proxyprotocol = *req.Proxyprotocol
+	}
+	if req.HTTP != nil && service.HTTP != nil {
+		applyServiceHTTP(service.HTTP, req.HTTP)
+	}
+	if req.HealthCheck != nil {
+		applyServiceHealthCheck(service.HealthCheck, req.HealthCheck)
+	}
+
+	return h.newAction("update_service", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+}
+
+func (h *Handler) loadBalancerDeleteService(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerDeleteServiceRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.IndexFunc(lb.Services, func(o schema.LoadBalancerService) bool { return o.ListenPort == req.ListenPort })
+	if index < 0 {
+		return schema.Action{}, notFound("service")
+	}
+	lb.Services = slices.Delete(lb.Services, index, index+1)
+
+	return h.newAction("delete_service", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+}
+
+func (h *Handler) loadBalancerChangeAlgorithm(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionChangeAlgorithmRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if err := validateAlgorithm(req.Type); err != nil {
+		return schema.Action{}, err
+	}
+	lb.Algorithm.Type = req.Type
+
+	return h.newAction("change_algorithm", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+}
+
+func (h *Handler) loadBalancerChangeType(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionChangeTypeRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.LoadBalancerType.ID == 0 && req.LoadBalancerType.Name == "" {
+		return schema.Action{}, invalidInput("load_balancer_type", "load_balancer_type is required")
+	}
+
+	lbTypeID, lbTypeName := typeID(req.LoadBalancerType)
+
+	return h.newAction("change_load_balancer_type", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, func() {
+		lb.LoadBalancerType = schema.LoadBalancerType{ID: lbTypeID, Name: lbTypeName}
+	}), nil
+}
+
+func (h *Handler) loadBalancerChangeProtection(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.Delete != nil {
+		lb.Protection.Delete = *req.Delete
+	}
+	return h.newAction("change_protection", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+}
+
+func (h *Handler) loadBalancerChangeDNSPtr(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionChangeDNSPtrRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	dnsPtr := ""
+	if req.DNSPtr != nil {
+		dnsPtr = *req.DNSPtr
+	}
+	switch req.IP {
+	case lb.PublicNet.IPv4.IP:
+		lb.PublicNet.IPv4.DNSPtr = dnsPtr
+	case lb.PublicNet.IPv6.IP:
+		lb.PublicNet.IPv6.DNSPtr = dnsPtr
+	default:
+		return schema.Action{}, invalidInput("ip", "ip does not belong to the load balancer")
+	}
+
+	return h.newAction("change_dns_ptr", []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+}
+
+func (h *Handler) loadBalancerAttachToNetwork(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionAttachToNetworkRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	network, ok := h.networks[req.Network]
+	if !ok {
+		return schema.Action{}, notFound("network")
+	}
+	if slices.ContainsFunc(lb.PrivateNet, func(o schema.LoadBalancerPrivateNet) bool { return o.Network == network.ID }) {
+		return schema.Action{}, conflictError("load_balancer_already_attached", "load balancer is already attached to the network")
+	}
+
+	var requestedIP, ipRange string
+	if req.IP != nil {
+		requestedIP = *req.IP
+	}
+	if req.IPRange != nil {
+		ipRange = *req.IPRange
+	}
+	ip, err := h.allocateNetworkIP(network, requestedIP, ipRange)
+	if err != nil {
+		return schema.Action{}, err
+	}
+	lb.PrivateNet = append(lb.PrivateNet, schema.LoadBalancerPrivateNet{Network: network.ID, IP: ip})
+
+	return h.newAction("attach_to_network", []schema.ActionResourceReference{
+		resourceRef("load_balancer", lb.ID),
+		resourceRef("network", network.ID),
+	}, nil), nil
+}
+
+func (h *Handler) loadBalancerDetachFromNetwork(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	var req schema.LoadBalancerActionDetachFromNetworkRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.IndexFunc(lb.PrivateNet, func(o schema.LoadBalancerPrivateNet) bool { return o.Network == req.Network })
+	if index < 0 {
+		return schema.Action{}, unprocessableError("load_balancer_not_attached_to_network", "load balancer is not attached to the network")
+	}
+	lb.PrivateNet = slices.Delete(lb.PrivateNet, index, index+1)
+
+	return h.newAction("detach_from_network", []schema.ActionResourceReference{
+		resourceRef("load_balancer", lb.ID),
+		resourceRef("network", req.Network),
+	}, nil), nil
+}
+
+// loadBalancerSetPublicInterface returns an action handler that enables or disables the
+// public interface of the load balancer.
+func (h *Handler) loadBalancerSetPublicInterface(command string, enabled bool) func(r *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+	return func(_ *http.Request, lb *schema.LoadBalancer) (schema.Action, error) {
+		if !enabled && len(lb.PrivateNet) == 0 {
+			return schema.Action{}, unprocessableError("load_balancer_not_attached_to_network", "load balancer must be attached to a network to disable its public interface")
+		}
+		lb.PublicNet.Enabled = enabled
+		return h.newAction(command, []schema.ActionResourceReference{resourceRef("load_balancer", lb.ID)}, nil), nil
+	}
+}
@@ -0,0 +1,70 @@
+package fakeapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestLoadBalancerTargets(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	serverResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+		Labels:     map[string]string{"role": "web"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, serverResult.NextActions...))
+
+	result, _, err := client.LoadBalancer.Create(ctx, hcloud.LoadBalancerCreateOpts{
+		Name:             "lb",
+		LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb11"},
+		Location:         &hcloud.Location{Name: "fsn1"},
+		Services: []hcloud.LoadBalancerCreateOptsService{
+			{Protocol: hcloud.LoadBalancerServiceProtocolHTTP},
+		},
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			{Type: hcloud.LoadBalancerTargetTypeLabelSelector, LabelSelector: hcloud.LoadBalancerCreateOptsTargetLabelSelector{Selector: "role=web"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+	lb, _, err := client.LoadBalancer.GetByID(ctx, result.LoadBalancer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.LoadBalancerAlgorithmTypeRoundRobin, lb.Algorithm.Type)
+	require.Len(t, lb.Services, 1)
+	assert.Equal(t, 80, lb.Services[0].ListenPort)
+	require.Len(t, lb.Targets, 1)
+	require.Len(t, lb.Targets[0].Targets, 1)
+	assert.Equal(t, serverResult.Server.ID, lb.Targets[0].Targets[0].Server.Server.ID)
+	require.Len(t, lb.Targets[0].Targets[0].HealthStatus, 1)
+	assert.Equal(t, hcloud.LoadBalancerTargetHealthStatusStatusHealthy, lb.Targets[0].Targets[0].HealthStatus[0].Status)
+
+	server, _, err := client.Server.GetByID(ctx, serverResult.Server.ID)
+	require.NoError(t, err)
+	require.Len(t, server.LoadBalancers, 1)
+	assert.Equal(t, lb.ID, server.LoadBalancers[0].ID)
+
+	_, _, err = client.LoadBalancer.AddService(ctx, lb, hcloud.LoadBalancerAddServiceOpts{Protocol: hcloud.LoadBalancerServiceProtocolHTTP})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeSourcePortAlreadyUsed))
+
+	action, _, err := client.LoadBalancer.RemoveLabelSelectorTarget(ctx, lb, "role=web")
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	lb, _, err = client.LoadBalancer.GetByID(ctx, lb.ID)
+	require.NoError(t, err)
+	assert.Empty(t, lb.Targets)
+
+	_, err = client.LoadBalancer.Delete(ctx, lb)
+	require.NoError(t, err)
+}
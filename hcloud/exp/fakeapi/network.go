@@ -0,0 +1,475 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerNetworkRoutes() {
+	h.route("GET /networks", h.listNetworks)
+	h.route("POST /networks", h.createNetwork)
+	h.route("GET /networks/{id}", h.getNetwork)
+	h.route("PUT /networks/{id}", h.updateNetwork)
+	h.route("DELETE /networks/{id}", h.deleteNetwork)
+
+	h.route("POST /networks/{id}/actions/add_subnet", resourceAction(h, h.networks, "network", h.networkAddSubnet))
+	h.route("POST /networks/{id}/actions/delete_subnet", resourceAction(h, h.networks, "network", h.networkDeleteSubnet))
+	h.route("POST /networks/{id}/actions/add_route", resourceAction(h, h.networks, "network", h.networkAddRoute))
+	h.route("POST /networks/{id}/actions/delete_route", resourceAction(h, h.networks, "network", h.networkDeleteRoute))
+	h.route("POST /networks/{id}/actions/change_ip_range", resourceAction(h, h.networks, "network", h.networkChangeIPRange))
+	h.route("POST /networks/{id}/actions/change_protection", resourceAction(h, h.networks, "network", h.networkChangeProtection))
+}
+
+// renderNetwork returns the network with all the properties derived from other resources.
+func (h *Handler) renderNetwork(network *schema.Network) schema.Network {
+	result := *network
+	result.Subnets = slices.Clone(network.Subnets)
+	result.Routes = slices.Clone(network.Routes)
+
+	result.Servers = []int64{}
+	for _, server := range sortedValues(h.servers) {
+		if slices.ContainsFunc(server.PrivateNet, func(o schema.ServerPrivateNet) bool { return o.Network == network.ID }) {
+			result.Servers = append(result.Servers, server.ID)
+		}
+	}
+
+	result.LoadBalancers = []int64{}
+	for _, lb := range sortedValues(h.loadBalancers) {
+		if slices.ContainsFunc(lb.PrivateNet, func(o schema.LoadBalancerPrivateNet) bool { return o.Network == network.ID }) {
+			result.LoadBalancers = append(result.LoadBalancers, lb.ID)
+		}
+	}
+
+	return result
+}
+
+func (h *Handler) listNetworks(r *http.Request) (int, any, error) {
+	networks, err := filterList(r, sortedValues(h.networks), listFilter[schema.Network]{
+		name:   func(o *schema.Network) string { return o.Name },
+		labels: func(o *schema.Network) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]schema.Network, 0, len(networks))
+	for _, network := range networks {
+		result = append(result, h.renderNetwork(network))
+	}
+	return listResponse(r, "networks", result)
+}
+
+func (h *Handler) getNetwork(r *http.Request) (int, any, error) {
+	network, err := getByID(r, h.networks, "network")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.NetworkGetResponse{Network: h.renderNetwork(network)}, nil
+}
+
+func (h *Handler) networkNameUsed(name string) bool {
+	for _, network := range h.networks {
+		if network.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) createNetwork(r *http.Request) (int, any, error) {
+	var req schema.NetworkCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case h.networkNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	ipRange, err := netip.ParsePrefix(req.IPRange)
+	if err != nil {
+		return 0, nil, invalidInput("ip_range", "ip_range must be a valid CIDR")
+	}
+
+	network := &schema.Network{
+		ID:                    h.nextID(),
+		Name:                  req.Name,
+		Created:               h.now(),
+		IPRange:               ipRange.Masked().String(),
+		Subnets:               []schema.NetworkSubnet{},
+		Routes:                []schema.NetworkRoute{},
+		Labels:                labelsOrEmpty(req.Labels),
+		ExposeRoutesToVSwitch: req.ExposeRoutesToVSwitch,
+	}
+
+	for _, subnet := range req.Subnets {
+		if err := h.addSubnet(network, schema.NetworkActionAddSubnetRequest{
+			Type:        subnet.Type,
+			IPRange:     subnet.IPRange,
+			NetworkZone: subnet.NetworkZone,
+			VSwitchID:   subnet.VSwitchID,
+		}); err != nil {
+			return 0, nil, err
+		}
+	}
+	for _, route := range req.Routes {
+		if err := addRoute(network, route); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	h.networks[network.ID] = network
+
+	return http.StatusCreated, schema.NetworkCreateResponse{Network: h.renderNetwork(network)}, nil
+}
+
+func (h *Handler) updateNetwork(r *http.Request) (int, any, error) {
+	network, err := getByID(r, h.networks, "network")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.NetworkUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != "" && req.Name != network.Name {
+		if h.networkNameUsed(req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		network.Name = req.Name
+	}
+	if req.Labels != nil {
+		network.Labels = labelsOrEmpty(req.Labels)
+	}
+	if req.ExposeRoutesToVSwitch != nil {
+		network.ExposeRoutesToVSwitch = *req.ExposeRoutesToVSwitch
+	}
+
+	return http.StatusOK, schema.NetworkUpdateResponse{Network: h.renderNetwork(network)}, nil
+}
+
+func (h *Handler) deleteNetwork(r *http.Request) (int, any, error) {
+	network, err := getByID(r, h.networks, "network")
+	if err != nil {
+		return 0, nil, err
+	}
+	if network.Protection.Delete {
+		return 0, nil, protectedError("network")
+	}
+	if err := h.checkNotLocked("network", network.ID); err != nil {
+		return 0, nil, err
+	}
+
+	// Attached servers and load balancers are detached from the network.
+	for _, server := range h.servers {
+		server.PrivateNet = slices.DeleteFunc(server.PrivateNet, func(o schema.ServerPrivateNet) bool { return o.Network == network.ID })
+	}
+	for _, lb := range h.loadBalancers {
+		lb.PrivateNet = slices.DeleteFunc(lb.PrivateNet, func(o schema.LoadBalancerPrivateNet) bool { return o.Network == network.ID })
+	}
+
+	delete(h.networks, network.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+// networkGateway returns the gateway of the network, which is the first IP of the
+// network IP range.
+func networkGateway(network *schema.Network) netip.Addr {
+	return netip.MustParsePrefix(network.IPRange).Masked().Addr().Next()
+}
+
+// addSubnet validates and adds a subnet to the network. When no IP range is given, the
+// next free /24 subnet is used.
+func (h *Handler) addSubnet(network *schema.Network, req schema.NetworkActionAddSubnetRequest) error {
+	switch req.Type {
+	case "cloud", "server", "vswitch":
+	default:
+		return invalidInput("type", fmt.Sprintf("unknown subnet type '%s'", req.Type))
+	}
+	if _, err := findLocationByNetworkZone(req.NetworkZone); err != nil {
+		return err
+	}
+	if req.Type == "vswitch" && req.VSwitchID == 0 {
+		return invalidInput("vswitch_id", "vswitch_id is required for vswitch subnets")
+	}
+
+	networkRange := netip.MustParsePrefix(network.IPRange)
+
+	var subnetRange netip.Prefix
+	if req.IPRange == "" {
+		next, ok := nextFreeSubnet(networkRange, network.Subnets, 24)
+		if !ok {
+			return unprocessableError("no_subnet_available", "no subnet available in the network ip range")
+		}
+		subnetRange = next
+	} else {
+		parsed, err := netip.ParsePrefix(req.IPRange)
+		if err != nil {
+			return invalidInput("ip_range", "ip_range must be a valid CIDR")
+		}
+		subnetRange = parsed.Masked()
+		if !prefixContains(networkRange, subnetRange) {
+			return invalidInput("ip_range", "ip_range must be inside the network ip_range")
+		}
+		for _, subnet := range network.Subnets {
+			if netip.MustParsePrefix(subnet.IPRange).Overlaps(subnetRange) {
+				return invalidInput("ip_range", "ip_range overlaps with an existing subnet")
+			}
+		}
+	}
+
+	subnet := schema.NetworkSubnet{
+		Type:        req.Type,
+		IPRange:     subnetRange.String(),
+		NetworkZone: req.NetworkZone,
+		Gateway:     networkGateway(network).String(),
+		VSwitchID:   req.VSwitchID,
+	}
+	network.Subnets = append(network.Subnets, subnet)
+	return nil
+}
+
+// nextFreeSubnet returns the first subnet with the given prefix length that does not
+// overlap with the existing subnets.
+func nextFreeSubnet(networkRange netip.Prefix, subnets []schema.NetworkSubnet, bits int) (netip.Prefix, bool) {
+	if bits < networkRange.Bits() {
+		return netip.Prefix{}, false
+	}
+
+	candidate := netip.PrefixFrom(networkRange.Masked().Addr(), bits)
+	for networkRange.Contains(candidate.Addr()) {
+		if !slices.ContainsFunc(subnets, func(o schema.NetworkSubnet) bool {
+			return netip.MustParsePrefix(o.IPRange).Overlaps(candidate)
+		}) {
+			return candidate, true
+		}
+
+		next, ok := nextPrefix(candidate)
+		if !ok {
+			break
+		}
+		candidate = next
+	}
+	return netip.Prefix{}, false
+}
+
+// nextPrefix returns the prefix directly following the given prefix.
+func nextPrefix(prefix netip.Prefix) (netip.Prefix, bool) {
+	last := lastAddr(prefix)
+	next := last.Next()
+	if !next.IsValid() {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(next, prefix.Bits()), true
+}
+
+// lastAddr returns the last address of the prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Masked().Addr().AsSlice()
+	for i := prefix.Bits(); i < len(bytes)*8; i++ {
+		bytes[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return addr
+}
+
+// prefixContains returns whether the inner prefix is fully contained in the outer prefix.
+func prefixContains(outer, inner netip.Prefix) bool {
+	return outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+func addRoute(network *schema.Network, route schema.NetworkRoute) error {
+	destination, err := netip.ParsePrefix(route.Destination)
+	if err != nil {
+		return invalidInput("destination", "destination must be a valid CIDR")
+	}
+	gateway, err := netip.ParseAddr(route.Gateway)
+	if err != nil {
+		return invalidInput("gateway", "gateway must be a valid IP")
+	}
+	if !netip.MustParsePrefix(network.IPRange).Contains(gateway) {
+		return invalidInput("gateway", "gateway must be inside the network ip_range")
+	}
+	if gateway == networkGateway(network) {
+		return invalidInput("gateway", "gateway must not be the network gateway")
+	}
+
+	route = schema.NetworkRoute{Destination: destination.Masked().String(), Gateway: gateway.String()}
+	if slices.Contains(network.Routes, route) {
+		return invalidInput("destination", "route already exists")
+	}
+
+	network.Routes = append(network.Routes, route)
+	return nil
+}
+
+// usedNetworkIPs returns all the IPs used in the network.
+func (h *Handler) usedNetworkIPs(network *schema.Network) map[netip.Addr]struct{} {
+	used := map[netip.Addr]struct{}{
+		networkGateway(network): {},
+	}
+	add := func(value string) {
+		if ip, err := netip.ParseAddr(value); err == nil {
+			used[ip] = struct{}{}
+		}
+	}
+
+	for _, server := range h.servers {
+		for _, privateNet := range server.PrivateNet {
+			if privateNet.Network == network.ID {
+				add(privateNet.IP)
+				for _, aliasIP := range privateNet.AliasIPs {
+					add(aliasIP)
+				}
+			}
+		}
+	}
+	for _, lb := range h.loadBalancers {
+		for _, privateNet := range lb.PrivateNet {
+			if privateNet.Network == network.ID {
+				add(privateNet.IP)
+			}
+		}
+	}
+	return used
+}
+
+// allocateNetworkIP returns a free IP in the network subnets. When an IP is requested,
+// it is validated and returned. The IP search may be restricted to a subnet using the
+// ipRange argument.
+func (h *Handler) allocateNetworkIP(network *schema.Network, requested string, ipRange string) (string, error) {
+	used := h.usedNetworkIPs(network)
+
+	subnets := make([]netip.Prefix, 0, len(network.Subnets))
+	for _, subnet := range network.Subnets {
+		if subnet.Type == "vswitch" {
+			continue
+		}
+		if ipRange != "" && subnet.IPRange != ipRange {
+			continue
+		}
+		subnets = append(subnets, netip.MustParsePrefix(subnet.IPRange))
+	}
+
+	if requested != "" {
+		ip, err := netip.ParseAddr(requested)
+		if err != nil {
+			return "", invalidInput("ip", "ip must be a valid IP")
+		}
+		if !slices.ContainsFunc(subnets, func(o netip.Prefix) bool { return o.Contains(ip) }) {
+			return "", invalidInput("ip", "ip must be inside a subnet of the network")
+		}
+		if _, ok := used[ip]; ok {
+			return "", conflictError("ip_not_available", "the provided network ip is not available")
+		}
+		return ip.String(), nil
+	}
+
+	for _, subnet := range subnets {
+		last := lastAddr(subnet)
+		for ip := subnet.Masked().Addr().Next(); ip.IsValid() && ip.Less(last); ip = ip.Next() {
+			if _, ok := used[ip]; !ok {
+				return ip.String(), nil
+			}
+		}
+	}
+	return "", unprocessableError("no_subnet_available", "no subnet or ip is available in the network")
+}
+
+func (h *Handler) networkAddSubnet(r *http.Request, network *schema.Network) (schema.Action, error) {
+	var req schema.NetworkActionAddSubnetRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if err := h.addSubnet(network, req); err != nil {
+		return schema.Action{}, err
+	}
+	return h.newAction("add_subnet", []schema.ActionResourceReference{resourceRef("network", network.ID)}, nil), nil
+}
+
+func (h *Handler) networkDeleteSubnet(r *http.Request, network *schema.Network) (schema.Action, error) {
+	var req schema.NetworkActionDeleteSubnetRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.IndexFunc(network.Subnets, func(o schema.NetworkSubnet) bool { return o.IPRange == req.IPRange })
+	if index < 0 {
+		return schema.Action{}, notFound("subnet")
+	}
+	subnet := netip.MustParsePrefix(network.Subnets[index].IPRange)
+	for ip := range h.usedNetworkIPs(network) {
+		if ip != networkGateway(network) && subnet.Contains(ip) {
+			return schema.Action{}, conflictError("resource_in_use", "subnet has attached resources")
+		}
+	}
+	network.Subnets = slices.Delete(network.Subnets, index, index+1)
+
+	return h.newAction("delete_subnet", []schema.ActionResourceReference{resourceRef("network", network.ID)}, nil), nil
+}
+
+func (h *Handler) networkAddRoute(r *http.Request, network *schema.Network) (schema.Action, error) {
+	var req schema.NetworkActionAddRouteRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if err := addRoute(network, schema.NetworkRoute(req)); err != nil {
+		return schema.Action{}, err
+	}
+	return h.newAction("add_route", []schema.ActionResourceReference{resourceRef("network", network.ID)}, nil), nil
+}
+
+func (h *Handler) networkDeleteRoute(r *http.Request, network *schema.Network) (schema.Action, error) {
+	var req schema.NetworkActionDeleteRouteRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.Index(network.Routes, schema.NetworkRoute(req))
+	if index < 0 {
+		return schema.Action{}, notFound("route")
+	}
+	network.Routes = slices.Delete(network.Routes, index, index+1)
+
+	return h.newAction("delete_route", []schema.ActionResourceReference{resourceRef("network", network.ID)}, nil), nil
+}
+
+func (h *Handler) networkChangeIPRange(r *http.Request, network *schema.Network) (schema.Action, error) {
+	var req schema.NetworkActionChangeIPRangeRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	ipRange, err := netip.ParsePrefix(req.IPRange)
+	if err != nil {
+		return schema.Action{}, invalidInput("ip_range", "ip_range must be a valid CIDR")
+	}
+	// The IP range may only be extended.
+	if !prefixContains(ipRange, netip.MustParsePrefix(network.IPRange)) {
+		return schema.Action{}, invalidInput("ip_range", "ip_range must contain the current ip_range")
+	}
+	network.IPRange = ipRange.Masked().String()
+
+	return h.newAction("change_ip_range", []schema.ActionResourceReference{resourceRef("network", network.ID)}, nil), nil
+}
+
+func (h *Handler) networkChangeProtection(r *http.Request, network *schema.Network) (schema.Action, error) {
+	var req schema.NetworkActionChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.Delete != nil {
+		network.Protection.Delete = *req.Delete
+	}
+	return h.newAction("change_protection", []schema.ActionResourceReference{resourceRef("network", network.ID)}, nil), nil
+}
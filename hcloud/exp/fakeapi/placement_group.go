@@ -0,0 +1,166 @@
+package fakeapi
+
+import (
+	"net/http"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerPlacementGroupRoutes() {
+	h.route("GET /placement_groups", h.listPlacementGroups)
+	h.route("POST /placement_groups", h.createPlacementGroup)
+	h.route("GET /placement_groups/{id}", h.getPlacementGroup)
+	h.route("PUT /placement_groups/{id}", h.updatePlacementGroup)
+	h.route("DELETE /placement_groups/{id}", h.deletePlacementGroup)
+}
+
+// renderPlacementGroup returns the placement group with all the properties derived
+// from other resources.
+func (h *Handler) renderPlacementGroup(placementGroup *schema.PlacementGroup) schema.PlacementGroup {
+	result := *placementGroup
+
+	result.Servers = []int64{}
+	for _, server := range sortedValues(h.servers) {
+		if server.PlacementGroup != nil && server.PlacementGroup.ID == placementGroup.ID {
+			result.Servers = append(result.Servers, server.ID)
+		}
+	}
+	return result
+}
+
+func (h *Handler) listPlacementGroups(r *http.Request) (int, any, error) {
+	placementGroups, err := filterList(r, sortedValues(h.placementGroups), listFilter[schema.PlacementGroup]{
+		name:   func(o *schema.PlacementGroup) string { return o.Name },
+		labels: func(o *schema.PlacementGroup) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]schema.PlacementGroup, 0, len(placementGroups))
+	for _, placementGroup := range placementGroups {
+		result = append(result, h.renderPlacementGroup(placementGroup))
+	}
+	return listResponse(r, "placement_groups", result)
+}
+
+func (h *Handler) getPlacementGroup(r *http.Request) (int, any, error) {
+	placementGroup, err := getByID(r, h.placementGroups, "placement_group")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.PlacementGroupGetResponse{PlacementGroup: h.renderPlacementGroup(placementGroup)}, nil
+}
+
+func (h *Handler) placementGroupNameUsed(name string) bool {
+	for _, placementGroup := range h.placementGroups {
+		if placementGroup.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) createPlacementGroup(r *http.Request) (int, any, error) {
+	var req schema.PlacementGroupCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case req.Type != "spread":
+		return 0, nil, invalidInput("type", "type must be spread")
+	case h.placementGroupNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	placementGroup := &schema.PlacementGroup{
+		ID:      h.nextID(),
+		Name:    req.Name,
+		Type:    req.Type,
+		Labels:  labelsOrEmpty(req.Labels),
+		Created: h.now(),
+	}
+	h.placementGroups[placementGroup.ID] = placementGroup
+
+	return http.StatusCreated, schema.PlacementGroupCreateResponse{
+		PlacementGroup: h.renderPlacementGroup(placementGroup),
+	}, nil
+}
+
+func (h *Handler) updatePlacementGroup(r *http.Request) (int, any, error) {
+	placementGroup, err := getByID(r, h.placementGroups, "placement_group")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.PlacementGroupUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != nil && *req.Name != placementGroup.Name {
+		if h.placementGroupNameUsed(*req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		placementGroup.Name = *req.Name
+	}
+	if req.Labels != nil {
+		placementGroup.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.PlacementGroupUpdateResponse{PlacementGroup: h.renderPlacementGroup(placementGroup)}, nil
+}
+
+func (h *Handler) deletePlacementGroup(r *http.Request) (int, any, error) {
+	placementGroup, err := getByID(r, h.placementGroups, "placement_group")
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(h.renderPlacementGroup(placementGroup).Servers) > 0 {
+		return 0, nil, conflictError("resource_in_use", "placement group must be empty to be deleted")
+	}
+
+	delete(h.placementGroups, placementGroup.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) serverAddToPlacementGroup(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionAddToPlacementGroupRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	placementGroup, ok := h.placementGroups[req.PlacementGroup]
+	if !ok {
+		return schema.Action{}, notFound("placement_group")
+	}
+	if server.Status != "off" {
+		return schema.Action{}, unprocessableError("server_not_stopped", "server must be stopped to be added to a placement group")
+	}
+	if server.PlacementGroup != nil {
+		return schema.Action{}, conflictError("server_already_in_placement_group", "server is already in a placement group")
+	}
+	server.PlacementGroup = placementGroup
+
+	return h.newAction("add_to_placement_group", []schema.ActionResourceReference{
+		resourceRef("server", server.ID),
+		resourceRef("placement_group", placementGroup.ID),
+	}, nil), nil
+}
+
+func (h *Handler) serverRemoveFromPlacementGroup(_ *http.Request, server *schema.Server) (schema.Action, error) {
+	if server.PlacementGroup == nil {
+		return schema.Action{}, unprocessableError("server_not_in_placement_group", "server is not in a placement group")
+	}
+	placementGroupID := server.PlacementGroup.ID
+	server.PlacementGroup = nil
+
+	return h.newAction("remove_from_placement_group", []schema.ActionResourceReference{
+		resourceRef("server", server.ID),
+		resourceRef("placement_group", placementGroupID),
+	}, nil), nil
+}
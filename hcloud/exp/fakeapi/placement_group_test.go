@@ -0,0 +1,58 @@
+package fakeapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestPlacementGroup(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	pgResult, _, err := client.PlacementGroup.Create(ctx, hcloud.PlacementGroupCreateOpts{
+		Name:   "pg",
+		Type:   hcloud.PlacementGroupTypeSpread,
+		Labels: map[string]string{"env": "test"},
+	})
+	require.NoError(t, err)
+
+	serverResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:           "server",
+		ServerType:     &hcloud.ServerType{Name: "cpx22"},
+		Image:          &hcloud.Image{Name: "debian-13"},
+		PlacementGroup: pgResult.PlacementGroup,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, serverResult.Action))
+	assert.Equal(t, pgResult.PlacementGroup.ID, serverResult.Server.PlacementGroup.ID)
+
+	placementGroups, err := client.PlacementGroup.AllWithOpts(ctx, hcloud.PlacementGroupListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: "env=test"},
+	})
+	require.NoError(t, err)
+	require.Len(t, placementGroups, 1)
+	assert.Equal(t, []int64{serverResult.Server.ID}, placementGroups[0].Servers)
+
+	_, err = client.PlacementGroup.Delete(ctx, pgResult.PlacementGroup)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeResourceInUse))
+
+	action, _, err := client.Server.RemoveFromPlacementGroup(ctx, serverResult.Server)
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	server, _, err := client.Server.GetByID(ctx, serverResult.Server.ID)
+	require.NoError(t, err)
+	assert.Nil(t, server.PlacementGroup)
+
+	// Servers must be stopped to be added to a placement group
+	_, _, err = client.Server.AddToPlacementGroup(ctx, server, pgResult.PlacementGroup)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeServerNotStopped))
+
+	_, err = client.PlacementGroup.Delete(ctx, pgResult.PlacementGroup)
+	require.NoError(t, err)
+}
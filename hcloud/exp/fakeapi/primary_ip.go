@@ -0,0 +1,285 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerPrimaryIPRoutes() {
+	h.route("GET /primary_ips", h.listPrimaryIPs)
+	h.route("POST /primary_ips", h.createPrimaryIP)
+	h.route("GET /primary_ips/{id}", h.getPrimaryIP)
+	h.route("PUT /primary_ips/{id}", h.updatePrimaryIP)
+	h.route("DELETE /primary_ips/{id}", h.deletePrimaryIP)
+
+	h.route("POST /primary_ips/{id}/actions/assign", resourceAction(h, h.primaryIPs, "primary_ip", h.primaryIPAssign))
+	h.route("POST /primary_ips/{id}/actions/unassign", resourceAction(h, h.primaryIPs, "primary_ip", h.primaryIPUnassign))
+	h.route("POST /primary_ips/{id}/actions/change_dns_ptr", resourceAction(h, h.primaryIPs, "primary_ip", h.primaryIPChangeDNSPtr))
+	h.route("POST /primary_ips/{id}/actions/change_protection", resourceAction(h, h.primaryIPs, "primary_ip", h.primaryIPChangeProtection))
+}
+
+func (h *Handler) listPrimaryIPs(r *http.Request) (int, any, error) {
+	primaryIPs, err := filterList(r, sortedValues(h.primaryIPs), listFilter[schema.PrimaryIP]{
+		name:   func(o *schema.PrimaryIP) string { return o.Name },
+		labels: func(o *schema.PrimaryIP) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := r.URL.Query()
+	result := make([]schema.PrimaryIP, 0, len(primaryIPs))
+	for _, primaryIP := range primaryIPs {
+		if query.Has("ip") && query.Get("ip") != primaryIP.IP {
+			continue
+		}
+		result = append(result, *primaryIP)
+	}
+	return listResponse(r, "primary_ips", result)
+}
+
+func (h *Handler) getPrimaryIP(r *http.Request) (int, any, error) {
+	primaryIP, err := getByID(r, h.primaryIPs, "primary_ip")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.PrimaryIPGetResponse{PrimaryIP: *primaryIP}, nil
+}
+
+func (h *Handler) primaryIPNameUsed(name string) bool {
+	for _, primaryIP := range h.primaryIPs {
+		if primaryIP.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// serverPrimaryIP returns the primary IP of the given type assigned to the server.
+func (h *Handler) serverPrimaryIP(serverID int64, ipType string) *schema.PrimaryIP {
+	for _, primaryIP := range h.primaryIPs {
+		if primaryIP.Type == ipType && primaryIP.AssigneeID != nil && *primaryIP.AssigneeID == serverID {
+			return primaryIP
+		}
+	}
+	return nil
+}
+
+// validateAssignee returns the server the primary IP may be assigned to.
+func (h *Handler) validateAssignee(primaryIP *schema.PrimaryIP, assigneeType string, assigneeID int64) (*schema.Server, error) {
+	if assigneeType != "server" {
+		return nil, invalidInput("assignee_type", fmt.Sprintf("unknown assignee type '%s'", assigneeType))
+	}
+	server, ok := h.servers[assigneeID]
+	if !ok {
+		return nil, notFound("server")
+	}
+	if server.Location.Name != primaryIP.Location.Name {
+		return nil, invalidInput("assignee_id", "server must be in the same location as the primary ip")
+	}
+	if server.Status != "off" {
+		return nil, unprocessableError("server_not_stopped", "server must be stopped to assign a primary ip")
+	}
+	if h.serverPrimaryIP(server.ID, primaryIP.Type) != nil {
+		return nil, conflictError("server_has_ipv4", fmt.Sprintf("server already has a primary %s", primaryIP.Type))
+	}
+	return server, nil
+}
+
+func (h *Handler) createPrimaryIP(r *http.Request) (int, any, error) {
+	var req schema.PrimaryIPCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case req.Type != "ipv4" && req.Type != "ipv6":
+		return 0, nil, invalidInput("type", fmt.Sprintf("unknown type '%s'", req.Type))
+	case req.AssigneeID == nil && req.Location == "" && req.Datacenter == "": //nolint:staticcheck // Required until the field is removed.
+		return 0, nil, invalidInput("location", "one of location or assignee_id is required")
+	case h.primaryIPNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	primaryIP := &schema.PrimaryIP{
+		Name:       req.Name,
+		Type:       req.Type,
+		Labels:     labelsOrEmpty(req.Labels),
+		AutoDelete: req.AutoDelete != nil && *req.AutoDelete,
+		Created:    h.now(),
+		DNSPtr:     []schema.PrimaryIPDNSPTR{},
+	}
+
+	var server *schema.Server
+	if req.AssigneeID != nil {
+		var ok bool
+		server, ok = h.servers[*req.AssigneeID]
+		if !ok {
+			return 0, nil, notFound("server")
+		}
+		primaryIP.Location = server.Location
+
+		if _, err := h.validateAssignee(primaryIP, req.AssigneeType, server.ID); err != nil {
+			return 0, nil, err
+		}
+	} else {
+		locationName := req.Location
+		if locationName == "" {
+			locationName = req.Datacenter //nolint:staticcheck // Required until the field is removed.
+		}
+		location, err := findLocation(locationName)
+		if err != nil {
+			return 0, nil, err
+		}
+		primaryIP.Location = location
+	}
+
+	primaryIP.ID = h.nextID()
+	if req.Type == "ipv4" {
+		primaryIP.IP = h.nextPublicIPv4()
+	} else {
+		primaryIP.IP = h.nextPublicIPv6()
+	}
+	h.primaryIPs[primaryIP.ID] = primaryIP
+
+	var a *schema.Action
+	if server != nil {
+		primaryIP.AssigneeID = &server.ID
+		primaryIP.AssigneeType = "server"
+
+		assignAction := h.newAction("assign_primary_ip", []schema.ActionResourceReference{
+			resourceRef("primary_ip", primaryIP.ID),
+			resourceRef("server", server.ID),
+		}, nil)
+		a = &assignAction
+	}
+
+	return http.StatusCreated, schema.PrimaryIPCreateResponse{PrimaryIP: *primaryIP, Action: a}, nil
+}
+
+func (h *Handler) updatePrimaryIP(r *http.Request) (int, any, error) {
+	primaryIP, err := getByID(r, h.primaryIPs, "primary_ip")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.PrimaryIPUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != "" && req.Name != primaryIP.Name {
+		if h.primaryIPNameUsed(req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		primaryIP.Name = req.Name
+	}
+	if req.Labels != nil {
+		primaryIP.Labels = labelsOrEmpty(req.Labels)
+	}
+	if req.AutoDelete != nil {
+		primaryIP.AutoDelete = *req.AutoDelete
+	}
+
+	return http.StatusOK, schema.PrimaryIPUpdateResponse{PrimaryIP: *primaryIP}, nil
+}
+
+func (h *Handler) deletePrimaryIP(r *http.Request) (int, any, error) {
+	primaryIP, err := getByID(r, h.primaryIPs, "primary_ip")
+	if err != nil {
+		return 0, nil, err
+	}
+	if primaryIP.Protection.Delete {
+		return 0, nil, protectedError("primary_ip")
+	}
+	if primaryIP.AssigneeID != nil {
+		return 0, nil, conflictError("resource_in_use", "primary ip must be unassigned to be deleted")
+	}
+	if err := h.checkNotLocked("primary_ip", primaryIP.ID); err != nil {
+		return 0, nil, err
+	}
+
+	delete(h.primaryIPs, primaryIP.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) primaryIPAssign(r *http.Request, primaryIP *schema.PrimaryIP) (schema.Action, error) {
+	var req schema.PrimaryIPActionAssignRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if primaryIP.AssigneeID != nil {
+		return schema.Action{}, conflictError("primary_ip_assigned", "primary ip is already assigned")
+	}
+
+	server, err := h.validateAssignee(primaryIP, req.AssigneeType, req.AssigneeID)
+	if err != nil {
+		return schema.Action{}, err
+	}
+	primaryIP.AssigneeID = &server.ID
+	primaryIP.AssigneeType = "server"
+
+	return h.newAction("assign_primary_ip", []schema.ActionResourceReference{
+		resourceRef("primary_ip", primaryIP.ID),
+		resourceRef("server", server.ID),
+	}, nil), nil
+}
+
+func (h *Handler) primaryIPUnassign(_ *http.Request, primaryIP *schema.PrimaryIP) (schema.Action, error) {
+	if primaryIP.AssigneeID == nil {
+		return schema.Action{}, unprocessableError("primary_ip_not_assigned", "primary ip is not assigned")
+	}
+
+	server := h.servers[*primaryIP.AssigneeID]
+	if server != nil && server.Status != "off" {
+		return schema.Action{}, unprocessableError("server_not_stopped", "server must be stopped to unassign a primary ip")
+	}
+
+	resources := []schema.ActionResourceReference{
+		resourceRef("primary_ip", primaryIP.ID),
+		resourceRef("server", *primaryIP.AssigneeID),
+	}
+	primaryIP.AssigneeID = nil
+
+	return h.newAction("unassign_primary_ip", resources, nil), nil
+}
+
+func (h *Handler) primaryIPChangeDNSPtr(r *http.Request, primaryIP *schema.PrimaryIP) (schema.Action, error) {
+	var req schema.PrimaryIPActionChangeDNSPtrRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	ip, err := netip.ParseAddr(req.IP)
+	if err != nil {
+		return schema.Action{}, invalidInput("ip", "ip must be a valid IP")
+	}
+	if primaryIP.Type == "ipv4" && req.IP != primaryIP.IP ||
+		primaryIP.Type == "ipv6" && !netip.MustParsePrefix(primaryIP.IP).Contains(ip) {
+		return schema.Action{}, invalidInput("ip", "ip does not belong to the primary ip")
+	}
+
+	primaryIP.DNSPtr = slices.DeleteFunc(primaryIP.DNSPtr, func(o schema.PrimaryIPDNSPTR) bool { return o.IP == ip.String() })
+	if req.DNSPtr != nil {
+		primaryIP.DNSPtr = append(primaryIP.DNSPtr, schema.PrimaryIPDNSPTR{IP: ip.String(), DNSPtr: *req.DNSPtr})
+	}
+
+	return h.newAction("change_dns_ptr", []schema.ActionResourceReference{resourceRef("primary_ip", primaryIP.ID)}, nil), nil
+}
+
+func (h *Handler) primaryIPChangeProtection(r *http.Request, primaryIP *schema.PrimaryIP) (schema.Action, error) {
+	var req schema.PrimaryIPActionChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	primaryIP.Protection.Delete = req.Delete
+
+	return h.newAction("change_protection", []schema.ActionResourceReference{resourceRef("primary_ip", primaryIP.ID)}, nil), nil
+}
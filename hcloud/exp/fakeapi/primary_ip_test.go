@@ -0,0 +1,59 @@
+package fakeapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestPrimaryIPAssign(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	serverResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:             "server",
+		ServerType:       &hcloud.ServerType{Name: "cpx22"},
+		Image:            &hcloud.Image{Name: "debian-13"},
+		StartAfterCreate: hcloud.Ptr(false),
+		PublicNet:        &hcloud.ServerCreatePublicNet{EnableIPv4: false, EnableIPv6: true},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, serverResult.Action))
+
+	result, _, err := client.PrimaryIP.Create(ctx, hcloud.PrimaryIPCreateOpts{
+		Name:         "ipv4",
+		Type:         hcloud.PrimaryIPTypeIPv4,
+		Location:     "fsn1",
+		AssigneeType: "server",
+	})
+	require.NoError(t, err)
+	assert.Nil(t, result.Action)
+
+	action, _, err := client.PrimaryIP.Assign(ctx, hcloud.PrimaryIPAssignOpts{
+		ID:           result.PrimaryIP.ID,
+		AssigneeID:   serverResult.Server.ID,
+		AssigneeType: "server",
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	server, _, err := client.Server.GetByID(ctx, serverResult.Server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, result.PrimaryIP.ID, server.PublicNet.IPv4.ID)
+	assert.Equal(t, result.PrimaryIP.IP, server.PublicNet.IPv4.IP)
+
+	_, err = client.PrimaryIP.Delete(ctx, result.PrimaryIP)
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeResourceInUse))
+
+	action, _, err = client.PrimaryIP.Unassign(ctx, result.PrimaryIP.ID)
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	_, err = client.PrimaryIP.Delete(ctx, result.PrimaryIP)
+	require.NoError(t, err)
+}
@@ -0,0 +1,545 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerServerRoutes() {
+	h.route("GET /servers", h.listServers)
+	h.route("POST /servers", h.createServer)
+	h.route("GET /servers/{id}", h.getServer)
+	h.route("PUT /servers/{id}", h.updateServer)
+	h.route("DELETE /servers/{id}", h.deleteServer)
+
+	h.route("POST /servers/{id}/actions/poweron", resourceAction(h, h.servers, "server", h.serverPowerAction("start_server", "running")))
+	h.route("POST /servers/{id}/actions/poweroff", resourceAction(h, h.servers, "server", h.serverPowerAction("stop_server", "off")))
+	h.route("POST /servers/{id}/actions/shutdown", resourceAction(h, h.servers, "server", h.serverPowerAction("shutdown_server", "off")))
+	h.route("POST /servers/{id}/actions/reboot", resourceAction(h, h.servers, "server", h.serverPowerAction("reboot_server", "running")))
+	h.route("POST /servers/{id}/actions/reset", resourceAction(h, h.servers, "server", h.serverPowerAction("reset_server", "running")))
+	h.route("POST /servers/{id}/actions/change_type", resourceAction(h, h.servers, "server", h.serverChangeType))
+	h.route("POST /servers/{id}/actions/change_protection", resourceAction(h, h.servers, "server", h.serverChangeProtection))
+	h.route("POST /servers/{id}/actions/rebuild", resourceAction(h, h.servers, "server", h.serverRebuild))
+	h.route("POST /servers/{id}/actions/attach_to_network", resourceAction(h, h.servers, "server", h.serverAttachToNetwork))
+	h.route("POST /servers/{id}/actions/detach_from_network", resourceAction(h, h.servers, "server", h.serverDetachFromNetwork))
+	h.route("POST /servers/{id}/actions/change_alias_ips", resourceAction(h, h.servers, "server", h.serverChangeAliasIPs))
+	h.route("POST /servers/{id}/actions/add_to_placement_group", resourceAction(h, h.servers, "server", h.serverAddToPlacementGroup))
+	h.route("POST /servers/{id}/actions/remove_from_placement_group", resourceAction(h, h.servers, "server", h.serverRemoveFromPlacementGroup))
+}
+
+// renderServer returns the server with all the properties derived from other resources.
+func (h *Handler) renderServer(server *schema.Server) schema.Server {
+	result := *server
+	result.PrivateNet = slices.Clone(server.PrivateNet)
+	result.PublicNet = schema.ServerPublicNet{
+		FloatingIPs: []int64{},
+		Firewalls:   []schema.ServerFirewall{},
+	}
+
+	for _, primaryIP := range sortedValues(h.primaryIPs) {
+		if primaryIP.AssigneeID == nil || *primaryIP.AssigneeID != server.ID {
+			continue
+		}
+		switch primaryIP.Type {
+		case "ipv4":
+			result.PublicNet.IPv4 = schema.ServerPublicNetIPv4{ID: primaryIP.ID, IP: primaryIP.IP, Blocked: primaryIP.Blocked}
+			if len(primaryIP.DNSPtr) > 0 {
+				result.PublicNet.IPv4.DNSPtr = primaryIP.DNSPtr[0].DNSPtr
+			}
+		case "ipv6":
+			result.PublicNet.IPv6 = schema.ServerPublicNetIPv6{ID: primaryIP.ID, IP: primaryIP.IP, Blocked: primaryIP.Blocked}
+		}
+	}
+
+	for _, firewall := range sortedValues(h.firewalls) {
+		if h.firewallAppliedToServer(firewall, server) {
+			result.PublicNet.Firewalls = append(result.PublicNet.Firewalls, schema.ServerFirewall{ID: firewall.ID, Status: "applied"})
+		}
+	}
+
+	if server.PlacementGroup != nil {
+		placementGroup := h.renderPlacementGroup(server.PlacementGroup)
+		result.PlacementGroup = &placementGroup
+	}
+
+	result.Volumes = []int64{}
+	for _, volume := range sortedValues(h.volumes) {
+		if volume.Server != nil && *volume.Server == server.ID {
+			result.Volumes = append(result.Volumes, volume.ID)
+		}
+	}
+
+	result.LoadBalancers = []int64{}
+	for _, lb := range sortedValues(h.loadBalancers) {
+		if slices.ContainsFunc(h.expandLoadBalancerTargets(lb), func(target schema.LoadBalancerTarget) bool {
+			return target.Server != nil && target.Server.ID == server.ID
+		}) {
+			result.LoadBalancers = append(result.LoadBalancers, lb.ID)
+		}
+	}
+
+	return result
+}
+
+func (h *Handler) listServers(r *http.Request) (int, any, error) {
+	servers, err := filterList(r, sortedValues(h.servers), listFilter[schema.Server]{
+		name:   func(o *schema.Server) string { return o.Name },
+		labels: func(o *schema.Server) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := r.URL.Query()
+	result := make([]schema.Server, 0, len(servers))
+	for _, server := range servers {
+		if query.Has("status") && !slices.Contains(query["status"], server.Status) {
+			continue
+		}
+		result = append(result, h.renderServer(server))
+	}
+	return listResponse(r, "servers", result)
+}
+
+func (h *Handler) getServer(r *http.Request) (int, any, error) {
+	server, err := getByID(r, h.servers, "server")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.ServerGetResponse{Server: h.renderServer(server)}, nil
+}
+
+func (h *Handler) serverNameUsed(name string) bool {
+	for _, server := range h.servers {
+		if server.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) createServer(r *http.Request) (int, any, error) {
+	var req schema.ServerCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case req.ServerType.ID == 0 && req.ServerType.Name == "":
+		return 0, nil, invalidInput("server_type", "server_type is required")
+	case req.Image.ID == 0 && req.Image.Name == "":
+		return 0, nil, invalidInput("image", "image is required")
+	case h.serverNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	locationName := req.Location
+	if locationName == "" {
+		locationName = req.Datacenter //nolint:staticcheck // Required until the field is removed.
+	}
+	location, err := findLocation(locationName)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Validate all the referenced resources before changing the state.
+	for _, id := range req.Volumes {
+		volume, ok := h.volumes[id]
+		if !ok {
+			return 0, nil, notFound("volume")
+		}
+		if volume.Server != nil {
+			return 0, nil, conflictError("volume_already_attached", "volume is already attached to a server")
+		}
+	}
+	for _, id := range req.Networks {
+		if _, ok := h.networks[id]; !ok {
+			return 0, nil, notFound("network")
+		}
+	}
+	for _, firewall := range req.Firewalls {
+		if _, ok := h.firewalls[firewall.Firewall]; !ok {
+			return 0, nil, notFound("firewall")
+		}
+	}
+	var placementGroup *schema.PlacementGroup
+	if req.PlacementGroup != 0 {
+		var ok bool
+		if placementGroup, ok = h.placementGroups[req.PlacementGroup]; !ok {
+			return 0, nil, notFound("placement_group")
+		}
+	}
+	publicNet := req.PublicNet
+	if publicNet == nil {
+		publicNet = &schema.ServerCreatePublicNet{EnableIPv4: true, EnableIPv6: true}
+	}
+	for _, id := range []int64{publicNet.IPv4ID, publicNet.IPv6ID} {
+		if id == 0 {
+			continue
+		}
+		primaryIP, ok := h.primaryIPs[id]
+		if !ok {
+			return 0, nil, notFound("primary_ip")
+		}
+		if primaryIP.AssigneeID != nil {
+			return 0, nil, conflictError("primary_ip_assigned", "primary ip is already assigned")
+		}
+	}
+
+	serverTypeID, serverTypeName := typeID(req.ServerType)
+	imageID, imageName := typeID(req.Image)
+
+	server := &schema.Server{
+		ID:      h.nextID(),
+		Name:    req.Name,
+		Status:  "initializing",
+		Created: h.now(),
+		ServerType: schema.ServerType{
+			ID:           serverTypeID,
+			Name:         serverTypeName,
+			Architecture: "x86",
+		},
+		Image: &schema.Image{
+			ID:           imageID,
+			Name:         &imageName,
+			Type:         "system",
+			Status:       "available",
+			Architecture: "x86",
+		},
+		Location:       location,
+		Labels:         labelsOrEmpty(req.Labels),
+		PrivateNet:     []schema.ServerPrivateNet{},
+		PlacementGroup: placementGroup,
+	}
+	for _, id := range req.Networks {
+		ip, err := h.allocateNetworkIP(h.networks[id], "", "")
+		if err != nil {
+			return 0, nil, err
+		}
+		server.PrivateNet = append(server.PrivateNet, schema.ServerPrivateNet{
+			Network:    id,
+			IP:         ip,
+			AliasIPs:   []string{},
+			MACAddress: fmt.Sprintf("86:00:00:%02x:%02x:%02x", byte(server.ID>>16), byte(server.ID>>8), byte(id)),
+		})
+	}
+
+	h.servers[server.ID] = server
+
+	for _, id := range req.Volumes {
+		h.volumes[id].Server = &server.ID
+	}
+	for _, firewall := range req.Firewalls {
+		h.firewalls[firewall.Firewall].AppliedTo = append(h.firewalls[firewall.Firewall].AppliedTo, schema.FirewallResource{
+			Type:   "server",
+			Server: &schema.FirewallResourceServer{ID: server.ID},
+		})
+	}
+	if publicNet.EnableIPv4 {
+		h.assignOrCreatePrimaryIP(server, "ipv4", publicNet.IPv4ID)
+	}
+	if publicNet.EnableIPv6 {
+		h.assignOrCreatePrimaryIP(server, "ipv6", publicNet.IPv6ID)
+	}
+
+	resources := []schema.ActionResourceReference{resourceRef("server", server.ID)}
+	createAction := h.newAction("create_server", resources, func() {
+		server.Status = "off"
+	})
+
+	nextActions := []schema.Action{}
+	if req.StartAfterCreate == nil || *req.StartAfterCreate {
+		nextActions = append(nextActions, h.newAction("start_server", resources, func() {
+			server.Status = "running"
+		}))
+	}
+
+	var rootPassword *string
+	if len(req.SSHKeys) == 0 {
+		password := "fake-root-password"
+		rootPassword = &password
+	}
+
+	return http.StatusCreated, schema.ServerCreateResponse{
+		Server:       h.renderServer(server),
+		Action:       createAction,
+		NextActions:  nextActions,
+		RootPassword: rootPassword,
+	}, nil
+}
+
+// assignOrCreatePrimaryIP assigns the existing primary IP to the server, or creates a
+// new auto deleted primary IP when id is 0.
+func (h *Handler) assignOrCreatePrimaryIP(server *schema.Server, ipType string, id int64) {
+	if id != 0 {
+		h.primaryIPs[id].AssigneeID = &server.ID
+		h.primaryIPs[id].AssigneeType = "server"
+		return
+	}
+
+	primaryIP := &schema.PrimaryIP{
+		ID:           h.nextID(),
+		Name:         fmt.Sprintf("primary_ip-%d", h.lastID),
+		Type:         ipType,
+		AssigneeID:   &server.ID,
+		AssigneeType: "server",
+		AutoDelete:   true,
+		Created:      h.now(),
+		Location:     server.Location,
+		Labels:       map[string]string{},
+		DNSPtr:       []schema.PrimaryIPDNSPTR{},
+	}
+	if ipType == "ipv4" {
+		primaryIP.IP = h.nextPublicIPv4()
+	} else {
+		primaryIP.IP = h.nextPublicIPv6()
+	}
+	h.primaryIPs[primaryIP.ID] = primaryIP
+}
+
+func (h *Handler) updateServer(r *http.Request) (int, any, error) {
+	server, err := getByID(r, h.servers, "server")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.ServerUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != "" && req.Name != server.Name {
+		if h.serverNameUsed(req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		server.Name = req.Name
+	}
+	if req.Labels != nil {
+		server.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.ServerUpdateResponse{Server: h.renderServer(server)}, nil
+}
+
+func (h *Handler) deleteServer(r *http.Request) (int, any, error) {
+	server, err := getByID(r, h.servers, "server")
+	if err != nil {
+		return 0, nil, err
+	}
+	if server.Protection.Delete {
+		return 0, nil, protectedError("server")
+	}
+	if err := h.checkNotLocked("server", server.ID); err != nil {
+		return 0, nil, err
+	}
+
+	server.Status = "deleting"
+	delete(h.servers, server.ID)
+
+	for _, volume := range h.volumes {
+		if volume.Server != nil && *volume.Server == server.ID {
+			volume.Server = nil
+			volume.LinuxDevice = ""
+		}
+	}
+	for _, primaryIP := range h.primaryIPs {
+		if primaryIP.AssigneeID != nil && *primaryIP.AssigneeID == server.ID {
+			if primaryIP.AutoDelete {
+				delete(h.primaryIPs, primaryIP.ID)
+			} else {
+				primaryIP.AssigneeID = nil
+			}
+		}
+	}
+	for _, firewall := range h.firewalls {
+		firewall.AppliedTo = slices.DeleteFunc(firewall.AppliedTo, func(resource schema.FirewallResource) bool {
+			return resource.Server != nil && resource.Server.ID == server.ID
+		})
+	}
+	for _, lb := range h.loadBalancers {
+		lb.Targets = slices.DeleteFunc(lb.Targets, func(target schema.LoadBalancerTarget) bool {
+			return target.Server != nil && target.Server.ID == server.ID
+		})
+	}
+
+	a := h.newAction("delete_server", []schema.ActionResourceReference{resourceRef("server", server.ID)}, nil)
+	return http.StatusOK, schema.ServerDeleteResponse{Action: a}, nil
+}
+
+// serverPowerAction returns an action handler that changes the status of the server
+// once the action completes.
+func (h *Handler) serverPowerAction(command, status string) func(r *http.Request, server *schema.Server) (schema.Action, error) {
+	return func(_ *http.Request, server *schema.Server) (schema.Action, error) {
+		return h.newAction(command, []schema.ActionResourceReference{resourceRef("server", server.ID)}, func() {
+			server.Status = status
+		}), nil
+	}
+}
+
+func (h *Handler) serverChangeType(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionChangeTypeRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.ServerType.ID == 0 && req.ServerType.Name == "" {
+		return schema.Action{}, invalidInput("server_type", "server_type is required")
+	}
+	if server.Status != "off" {
+		return schema.Action{}, unprocessableError("server_not_stopped", "server must be stopped to change its type")
+	}
+
+	serverTypeID, serverTypeName := typeID(req.ServerType)
+
+	return h.newAction("change_server_type", []schema.ActionResourceReference{resourceRef("server", server.ID)}, func() {
+		server.ServerType = schema.ServerType{ID: serverTypeID, Name: serverTypeName, Architecture: "x86"}
+	}), nil
+}
+
+func (h *Handler) serverChangeProtection(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	if req.Delete != nil {
+		server.Protection.Delete = *req.Delete
+	}
+	if req.Rebuild != nil {
+		server.Protection.Rebuild = *req.Rebuild
+	}
+
+	return h.newAction("change_protection", []schema.ActionResourceReference{resourceRef("server", server.ID)}, nil), nil
+}
+
+func (h *Handler) serverRebuild(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionRebuildRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.Image.ID == 0 && req.Image.Name == "" {
+		return schema.Action{}, invalidInput("image", "image is required")
+	}
+	if server.Protection.Rebuild {
+		return schema.Action{}, protectedError("server")
+	}
+
+	imageID, imageName := typeID(req.Image)
+	server.Image = &schema.Image{ID: imageID, Name: &imageName, Type: "system", Status: "available", Architecture: "x86"}
+
+	return h.newAction("rebuild_server", []schema.ActionResourceReference{resourceRef("server", server.ID)}, nil), nil
+}
+
+func (h *Handler) serverAttachToNetwork(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionAttachToNetworkRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	network, ok := h.networks[req.Network]
+	if !ok {
+		return schema.Action{}, notFound("network")
+	}
+	if slices.ContainsFunc(server.PrivateNet, func(o schema.ServerPrivateNet) bool { return o.Network == network.ID }) {
+		return schema.Action{}, conflictError("server_already_attached", "server is already attached to the network")
+	}
+	if err := h.checkNotLocked("network", network.ID); err != nil {
+		return schema.Action{}, err
+	}
+
+	var requestedIP, ipRange string
+	if req.IP != nil {
+		requestedIP = *req.IP
+	}
+	if req.IPRange != nil {
+		ipRange = *req.IPRange
+	}
+	ip, err := h.allocateNetworkIP(network, requestedIP, ipRange)
+	if err != nil {
+		return schema.Action{}, err
+	}
+
+	aliasIPs := []string{}
+	for _, aliasIP := range req.AliasIPs {
+		if aliasIP == nil {
+			continue
+		}
+		if _, err := h.allocateNetworkIP(network, *aliasIP, ""); err != nil {
+			return schema.Action{}, err
+		}
+		aliasIPs = append(aliasIPs, *aliasIP)
+	}
+
+	server.PrivateNet = append(server.PrivateNet, schema.ServerPrivateNet{
+		Network:    network.ID,
+		IP:         ip,
+		AliasIPs:   aliasIPs,
+		MACAddress: fmt.Sprintf("86:00:00:%02x:%02x:%02x", byte(server.ID>>16), byte(server.ID>>8), byte(network.ID)),
+	})
+
+	return h.newAction("attach_to_network", []schema.ActionResourceReference{
+		resourceRef("server", server.ID),
+		resourceRef("network", network.ID),
+	}, nil), nil
+}
+
+func (h *Handler) serverDetachFromNetwork(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionDetachFromNetworkRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.IndexFunc(server.PrivateNet, func(o schema.ServerPrivateNet) bool { return o.Network == req.Network })
+	if index < 0 {
+		return schema.Action{}, unprocessableError("server_not_attached_to_network", "server is not attached to the network")
+	}
+	server.PrivateNet = slices.Delete(server.PrivateNet, index, index+1)
+
+	return h.newAction("detach_from_network", []schema.ActionResourceReference{
+		resourceRef("server", server.ID),
+		resourceRef("network", req.Network),
+	}, nil), nil
+}
+
+func (h *Handler) serverChangeAliasIPs(r *http.Request, server *schema.Server) (schema.Action, error) {
+	var req schema.ServerActionChangeAliasIPsRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	index := slices.IndexFunc(server.PrivateNet, func(o schema.ServerPrivateNet) bool { return o.Network == req.Network })
+	if index < 0 {
+		return schema.Action{}, unprocessableError("server_not_attached_to_network", "server is not attached to the network")
+	}
+
+	// Release the current alias IPs, so they can be requested again.
+	previous := server.PrivateNet[index].AliasIPs
+	server.PrivateNet[index].AliasIPs = []string{}
+	for _, aliasIP := range req.AliasIPs {
+		if _, err := h.allocateNetworkIP(h.networks[req.Network], aliasIP, ""); err != nil {
+			server.PrivateNet[index].AliasIPs = previous
+			return schema.Action{}, err
+		}
+	}
+	server.PrivateNet[index].AliasIPs = slices.Clone(req.AliasIPs)
+
+	return h.newAction("change_alias_ips", []schema.ActionResourceReference{
+		resourceRef("server", server.ID),
+		resourceRef("network", req.Network),
+	}, nil), nil
+}
+
+// serversMatching returns the servers matching the label selector.
+func (h *Handler) serversMatching(selector string) []*schema.Server {
+	result := []*schema.Server{}
+	for _, server := range sortedValues(h.servers) {
+		if matchLabelSelector(selector, server.Labels) {
+			result = append(result, server)
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package fakeapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestServerLifecycle(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	result, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+		Location:   &hcloud.Location{Name: "hel1"},
+		Labels:     map[string]string{"env": "test"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.ServerStatusInitializing, result.Server.Status)
+	assert.Equal(t, "hel1", result.Server.Location.Name)
+	assert.NotEmpty(t, result.RootPassword)
+	assert.NotEmpty(t, result.Server.PublicNet.IPv4.IP)
+	assert.NotEmpty(t, result.Server.PublicNet.IPv6.IP)
+	require.Len(t, result.NextActions, 1)
+
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+	require.NoError(t, client.Action.WaitFor(ctx, result.NextActions...))
+
+	_, _, err = client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeUniquenessError))
+
+	servers, err := client.Server.AllWithOpts(ctx, hcloud.ServerListOpts{ListOpts: hcloud.ListOpts{LabelSelector: "env=test"}})
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, hcloud.ServerStatusRunning, servers[0].Status)
+
+	action, _, err := client.Server.Poweroff(ctx, servers[0])
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	server, _, err := client.Server.GetByName(ctx, "server")
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.ServerStatusOff, server.Status)
+
+	deleteResult, _, err := client.Server.DeleteWithResult(ctx, server)
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, deleteResult.Action))
+
+	servers, err = client.Server.All(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, servers)
+
+	// The auto deleted primary IPs are removed with the server.
+	primaryIPs, err := client.PrimaryIP.All(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, primaryIPs)
+}
+
+func TestServerProtection(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	result, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	})
+	require.NoError(t, err)
+
+	action, _, err := client.Server.ChangeProtection(ctx, result.Server, hcloud.ServerChangeProtectionOpts{
+		Delete:  hcloud.Ptr(true),
+		Rebuild: hcloud.Ptr(true),
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	_, _, err = client.Server.DeleteWithResult(ctx, result.Server)
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeProtected))
+
+	_, _, err = client.Server.RebuildWithResult(ctx, result.Server, hcloud.ServerRebuildOpts{Image: &hcloud.Image{Name: "ubuntu-24.04"}})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeProtected))
+}
+
+func TestServerNetworks(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	network, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+		Name:    "network",
+		IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+		Subnets: []hcloud.NetworkSubnet{{
+			Type:        hcloud.NetworkSubnetTypeCloud,
+			IPRange:     mustParseCIDR(t, "10.0.1.0/24"),
+			NetworkZone: hcloud.NetworkZoneEUCentral,
+		}},
+	})
+	require.NoError(t, err)
+
+	result, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+		Networks:   []*hcloud.Network{network},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Server.PrivateNet, 1)
+	assert.Equal(t, "10.0.1.1", result.Server.PrivateNet[0].IP.String())
+
+	network, _, err = client.Network.GetByID(ctx, network.ID)
+	require.NoError(t, err)
+	require.Len(t, network.Servers, 1)
+	assert.Equal(t, result.Server.ID, network.Servers[0].ID)
+
+	action, _, err := client.Server.DetachFromNetwork(ctx, result.Server, hcloud.ServerDetachFromNetworkOpts{Network: network})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	server, _, err := client.Server.GetByID(ctx, result.Server.ID)
+	require.NoError(t, err)
+	assert.Empty(t, server.PrivateNet)
+}
@@ -0,0 +1,84 @@
+package fakeapi
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// locations is the static list of locations known by the fake.
+var locations = []schema.Location{
+	{ID: 1, Name: "fsn1", Description: "Falkenstein DC Park 1", Country: "DE", City: "Falkenstein", NetworkZone: "eu-central"},
+	{ID: 2, Name: "nbg1", Description: "Nuremberg DC Park 1", Country: "DE", City: "Nuremberg", NetworkZone: "eu-central"},
+	{ID: 3, Name: "hel1", Description: "Helsinki DC Park 1", Country: "FI", City: "Helsinki", NetworkZone: "eu-central"},
+	{ID: 4, Name: "ash", Description: "Ashburn, VA", Country: "US", City: "Ashburn, VA", NetworkZone: "us-east"},
+	{ID: 5, Name: "hil", Description: "Hillsboro, OR", Country: "US", City: "Hillsboro, OR", NetworkZone: "us-west"},
+	{ID: 6, Name: "sin", Description: "Singapore", Country: "SG", City: "Singapore", NetworkZone: "ap-southeast"},
+}
+
+const defaultLocation = "fsn1"
+
+// findLocation returns the location with the given name. Datacenter names (e.g.
+// fsn1-dc14) are resolved to their location.
+func findLocation(name string) (schema.Location, error) {
+	if name == "" {
+		name = defaultLocation
+	}
+	name, _, _ = strings.Cut(name, "-dc")
+
+	for _, location := range locations {
+		if location.Name == name {
+			return location, nil
+		}
+	}
+	return schema.Location{}, invalidInput("location", fmt.Sprintf("unknown location '%s'", name))
+}
+
+// findLocationByIDOrName returns the location with the given ID or name.
+func findLocationByIDOrName(value schema.IDOrName) (schema.Location, error) {
+	for _, location := range locations {
+		if value.ID != 0 && location.ID == value.ID {
+			return location, nil
+		}
+	}
+	if value.ID != 0 {
+		return schema.Location{}, invalidInput("location", fmt.Sprintf("unknown location '%d'", value.ID))
+	}
+	return findLocation(value.Name)
+}
+
+// findLocationByNetworkZone returns the first location of the network zone.
+func findLocationByNetworkZone(networkZone string) (schema.Location, error) {
+	for _, location := range locations {
+		if location.NetworkZone == networkZone {
+			return location, nil
+		}
+	}
+	return schema.Location{}, invalidInput("network_zone", fmt.Sprintf("unknown network zone '%s'", networkZone))
+}
+
+// typeID returns a stable ID for a resource type (server types, load balancer types,
+// images) that is only known by its name.
+func typeID(value schema.IDOrName) (int64, string) {
+	if value.ID != 0 {
+		return value.ID, fmt.Sprintf("%d", value.ID)
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(value.Name))
+	return int64(hash.Sum32()), value.Name
+}
+
+// nextPublicIPv4 returns a new public IPv4 address from the documentation range.
+func (h *Handler) nextPublicIPv4() string {
+	h.lastIP++
+	return fmt.Sprintf("203.0.%d.%d", 113+h.lastIP/254, 1+h.lastIP%254)
+}
+
+// nextPublicIPv6 returns a new public IPv6 network from the documentation range.
+func (h *Handler) nextPublicIPv6() string {
+	h.lastIP++
+	return fmt.Sprintf("2001:db8:%x::/64", h.lastIP)
+}
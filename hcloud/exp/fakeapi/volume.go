@@ -0,0 +1,229 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func (h *Handler) registerVolumeRoutes() {
+	h.route("GET /volumes", h.listVolumes)
+	h.route("POST /volumes", h.createVolume)
+	h.route("GET /volumes/{id}", h.getVolume)
+	h.route("PUT /volumes/{id}", h.updateVolume)
+	h.route("DELETE /volumes/{id}", h.deleteVolume)
+
+	h.route("POST /volumes/{id}/actions/attach", resourceAction(h, h.volumes, "volume", h.volumeAttach))
+	h.route("POST /volumes/{id}/actions/detach", resourceAction(h, h.volumes, "volume", h.volumeDetach))
+	h.route("POST /volumes/{id}/actions/resize", resourceAction(h, h.volumes, "volume", h.volumeResize))
+	h.route("POST /volumes/{id}/actions/change_protection", resourceAction(h, h.volumes, "volume", h.volumeChangeProtection))
+}
+
+func (h *Handler) listVolumes(r *http.Request) (int, any, error) {
+	volumes, err := filterList(r, sortedValues(h.volumes), listFilter[schema.Volume]{
+		name:   func(o *schema.Volume) string { return o.Name },
+		labels: func(o *schema.Volume) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]schema.Volume, 0, len(volumes))
+	for _, volume := range volumes {
+		result = append(result, *volume)
+	}
+	return listResponse(r, "volumes", result)
+}
+
+func (h *Handler) getVolume(r *http.Request) (int, any, error) {
+	volume, err := getByID(r, h.volumes, "volume")
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.VolumeGetResponse{Volume: *volume}, nil
+}
+
+func (h *Handler) volumeNameUsed(name string) bool {
+	for _, volume := range h.volumes {
+		if volume.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) createVolume(r *http.Request) (int, any, error) {
+	var req schema.VolumeCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case req.Size < 10:
+		return 0, nil, invalidInput("size", "size must be at least 10 GB")
+	case req.Server == nil && req.Location == nil:
+		return 0, nil, invalidInput("location", "one of location or server is required")
+	case h.volumeNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	var location schema.Location
+	var server *schema.Server
+	if req.Server != nil {
+		var ok bool
+		server, ok = h.servers[*req.Server]
+		if !ok {
+			return 0, nil, notFound("server")
+		}
+		location = server.Location
+	} else {
+		var err error
+		location, err = findLocationByIDOrName(*req.Location)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	volume := &schema.Volume{
+		ID:       h.nextID(),
+		Name:     req.Name,
+		Status:   "creating",
+		Location: location,
+		Size:     req.Size,
+		Format:   req.Format,
+		Labels:   labelsOrEmpty(req.Labels),
+		Created:  h.now(),
+	}
+	volume.LinuxDevice = fmt.Sprintf("/dev/disk/by-id/scsi-0HC_Volume_%d", volume.ID)
+	h.volumes[volume.ID] = volume
+
+	createAction := h.newAction("create_volume", []schema.ActionResourceReference{resourceRef("volume", volume.ID)}, func() {
+		volume.Status = "available"
+	})
+
+	nextActions := []schema.Action{}
+	if server != nil {
+		volume.Server = &server.ID
+		nextActions = append(nextActions, h.newAction("attach_volume", []schema.ActionResourceReference{
+			resourceRef("volume", volume.ID),
+			resourceRef("server", server.ID),
+		}, nil))
+	}
+
+	return http.StatusCreated, schema.VolumeCreateResponse{
+		Volume:      *volume,
+		Action:      &createAction,
+		NextActions: nextActions,
+	}, nil
+}
+
+func (h *Handler) updateVolume(r *http.Request) (int, any, error) {
+	volume, err := getByID(r, h.volumes, "volume")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.VolumeUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	if req.Name != "" && req.Name != volume.Name {
+		if h.volumeNameUsed(req.Name) {
+			return 0, nil, uniquenessError("name")
+		}
+		volume.Name = req.Name
+	}
+	if req.Labels != nil {
+		volume.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.VolumeUpdateResponse{Volume: *volume}, nil
+}
+
+func (h *Handler) deleteVolume(r *http.Request) (int, any, error) {
+	volume, err := getByID(r, h.volumes, "volume")
+	if err != nil {
+		return 0, nil, err
+	}
+	if volume.Protection.Delete {
+		return 0, nil, protectedError("volume")
+	}
+	if volume.Server != nil {
+		return 0, nil, lockedError("volume")
+	}
+	if err := h.checkNotLocked("volume", volume.ID); err != nil {
+		return 0, nil, err
+	}
+
+	delete(h.volumes, volume.ID)
+
+	return http.StatusNoContent, nil, nil
+}
+
+func (h *Handler) volumeAttach(r *http.Request, volume *schema.Volume) (schema.Action, error) {
+	var req schema.VolumeActionAttachVolumeRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+
+	server, ok := h.servers[req.Server]
+	if !ok {
+		return schema.Action{}, notFound("server")
+	}
+	if volume.Server != nil {
+		return schema.Action{}, conflictError("volume_already_attached", "volume is already attached to a server")
+	}
+	if server.Location.Name != volume.Location.Name {
+		return schema.Action{}, invalidInput("server", "server must be in the same location as the volume")
+	}
+
+	volume.Server = &server.ID
+
+	return h.newAction("attach_volume", []schema.ActionResourceReference{
+		resourceRef("volume", volume.ID),
+		resourceRef("server", server.ID),
+	}, nil), nil
+}
+
+func (h *Handler) volumeDetach(_ *http.Request, volume *schema.Volume) (schema.Action, error) {
+	if volume.Server == nil {
+		return schema.Action{}, invalidInput("server", "volume is not attached to a server")
+	}
+
+	resources := []schema.ActionResourceReference{
+		resourceRef("volume", volume.ID),
+		resourceRef("server", *volume.Server),
+	}
+	volume.Server = nil
+
+	return h.newAction("detach_volume", resources, nil), nil
+}
+
+func (h *Handler) volumeResize(r *http.Request, volume *schema.Volume) (schema.Action, error) {
+	var req schema.VolumeActionResizeVolumeRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.Size <= volume.Size {
+		return schema.Action{}, invalidInput("size", "size must be larger than the current size")
+	}
+
+	return h.newAction("resize_volume", []schema.ActionResourceReference{resourceRef("volume", volume.ID)}, func() {
+		volume.Size = req.Size
+	}), nil
+}
+
+func (h *Handler) volumeChangeProtection(r *http.Request, volume *schema.Volume) (schema.Action, error) {
+	var req schema.VolumeActionChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.Delete != nil {
+		volume.Protection.Delete = *req.Delete
+	}
+	return h.newAction("change_protection", []schema.ActionResourceReference{resourceRef("volume", volume.ID)}, nil), nil
+}
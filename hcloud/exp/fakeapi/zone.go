@@ -0,0 +1,713 @@
+package fakeapi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// assignedNameservers are the authoritative nameservers assigned to every zone.
+var assignedNameservers = []string{
+	"hydrogen.ns.hetzner.com.",
+	"oxygen.ns.hetzner.com.",
+	"helium.ns.hetzner.de.",
+}
+
+const defaultZoneTTL = 3600
+
+func (h *Handler) registerZoneRoutes() {
+	h.route("GET /zones", h.listZones)
+	h.route("POST /zones", h.createZone)
+	h.route("GET /zones/{id}", h.getZone)
+	h.route("PUT /zones/{id}", h.updateZone)
+	h.route("DELETE /zones/{id}", h.deleteZone)
+	h.route("GET /zones/{id}/zonefile", h.exportZonefile)
+
+	h.route("POST /zones/{id}/actions/import_zonefile", h.zoneAction(h.zoneImportZonefile))
+	h.route("POST /zones/{id}/actions/change_protection", h.zoneAction(h.zoneChangeProtection))
+	h.route("POST /zones/{id}/actions/change_ttl", h.zoneAction(h.zoneChangeTTL))
+	h.route("POST /zones/{id}/actions/change_primary_nameservers", h.zoneAction(h.zoneChangePrimaryNameservers))
+
+	h.route("GET /zones/{id}/rrsets", h.listRRSets)
+	h.route("POST /zones/{id}/rrsets", h.createRRSet)
+	h.route("GET /zones/{id}/rrsets/{name}/{type}", h.getRRSet)
+	h.route("PUT /zones/{id}/rrsets/{name}/{type}", h.updateRRSet)
+	h.route("DELETE /zones/{id}/rrsets/{name}/{type}", h.deleteRRSet)
+
+	h.route("POST /zones/{id}/rrsets/{name}/{type}/actions/change_protection", h.rrsetAction(h.rrsetChangeProtection))
+	h.route("POST /zones/{id}/rrsets/{name}/{type}/actions/change_ttl", h.rrsetAction(h.rrsetChangeTTL))
+	h.route("POST /zones/{id}/rrsets/{name}/{type}/actions/set_records", h.rrsetAction(h.rrsetSetRecords))
+	h.route("POST /zones/{id}/rrsets/{name}/{type}/actions/add_records", h.rrsetAction(h.rrsetAddRecords))
+	h.route("POST /zones/{id}/rrsets/{name}/{type}/actions/update_records", h.rrsetAction(h.rrsetUpdateRecords))
+	h.route("POST /zones/{id}/rrsets/{name}/{type}/actions/remove_records", h.rrsetAction(h.rrsetRemoveRecords))
+}
+
+// zoneByIDOrName returns the zone with the ID or name found in the request path.
+func (h *Handler) zoneByIDOrName(r *http.Request) (*schema.Zone, error) {
+	value := r.PathValue("id")
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if zone, ok := h.zones[id]; ok {
+			return zone, nil
+		}
+		return nil, notFound("zone")
+	}
+	for _, zone := range h.zones {
+		if zone.Name == value {
+			return zone, nil
+		}
+	}
+	return nil, notFound("zone")
+}
+
+// zoneAction is similar to [resourceAction], but zones may be referenced by ID or name.
+func (h *Handler) zoneAction(fn func(r *http.Request, zone *schema.Zone) (schema.Action, error)) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		zone, err := h.zoneByIDOrName(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := h.checkNotLocked("zone", zone.ID); err != nil {
+			return 0, nil, err
+		}
+
+		a, err := fn(r, zone)
+		if err != nil {
+			return 0, nil, err
+		}
+		return http.StatusCreated, schema.ActionGetResponse{Action: a}, nil
+	}
+}
+
+// renderZone returns the zone with the record count derived from its RRSets.
+func (h *Handler) renderZone(zone *schema.Zone) schema.Zone {
+	result := *zone
+	result.PrimaryNameservers = slices.Clone(zone.PrimaryNameservers)
+	result.RecordCount = 0
+	for _, rrset := range h.rrsets[zone.ID] {
+		result.RecordCount += len(rrset.Records)
+	}
+	return result
+}
+
+func (h *Handler) listZones(r *http.Request) (int, any, error) {
+	zones, err := filterList(r, sortedValues(h.zones), listFilter[schema.Zone]{
+		name:   func(o *schema.Zone) string { return o.Name },
+		labels: func(o *schema.Zone) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := r.URL.Query()
+	result := make([]schema.Zone, 0, len(zones))
+	for _, zone := range zones {
+		if query.Has("mode") && query.Get("mode") != zone.Mode {
+			continue
+		}
+		result = append(result, h.renderZone(zone))
+	}
+	return listResponse(r, "zones", result)
+}
+
+func (h *Handler) getZone(r *http.Request) (int, any, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.ZoneGetResponse{Zone: h.renderZone(zone)}, nil
+}
+
+func (h *Handler) zoneNameUsed(name string) bool {
+	for _, zone := range h.zones {
+		if zone.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) createZone(r *http.Request) (int, any, error) {
+	var req schema.ZoneCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	switch {
+	case req.Name == "":
+		return 0, nil, invalidInput("name", "name is required")
+	case !strings.Contains(req.Name, ".") || strings.HasSuffix(req.Name, "."):
+		return 0, nil, invalidInput("name", "name must be a valid domain name")
+	case req.Mode != "primary" && req.Mode != "secondary":
+		return 0, nil, invalidInput("mode", fmt.Sprintf("unknown mode '%s'", req.Mode))
+	case req.Mode == "secondary" && len(req.PrimaryNameservers) == 0:
+		return 0, nil, invalidInput("primary_nameservers", "primary_nameservers are required for secondary zones")
+	case req.Mode == "primary" && len(req.PrimaryNameservers) > 0:
+		return 0, nil, invalidInput("primary_nameservers", "primary_nameservers are only allowed for secondary zones")
+	case req.Zonefile != "" && len(req.RRSets) > 0:
+		return 0, nil, invalidInput("zonefile", "only one of zonefile or rrsets is allowed")
+	case h.zoneNameUsed(req.Name):
+		return 0, nil, uniquenessError("name")
+	}
+
+	zone := &schema.Zone{
+		Name:               req.Name,
+		Created:            h.now(),
+		TTL:                defaultZoneTTL,
+		Mode:               req.Mode,
+		PrimaryNameservers: []schema.ZonePrimaryNameserver{},
+		Labels:             labelsOrEmpty(req.Labels),
+		Registrar:          "other",
+		Status:             "ok",
+		AuthoritativeNameservers: schema.ZoneAuthoritativeNameservers{
+			Assigned:         slices.Clone(assignedNameservers),
+			Delegated:        []string{},
+			DelegationStatus: "unregistered",
+		},
+	}
+	if req.TTL != nil {
+		zone.TTL = *req.TTL
+	}
+	for _, ns := range req.PrimaryNameservers {
+		zone.PrimaryNameservers = append(zone.PrimaryNameservers, primaryNameserver(schema.ZoneChangePrimaryNameserversRequestPrimaryNameserver(ns)))
+	}
+
+	rrsets := map[string]*schema.ZoneRRSet{}
+	if req.Mode == "primary" {
+		for _, rrset := range defaultRRSets(zone) {
+			rrsets[rrset.ID] = rrset
+		}
+	}
+
+	requested := make([]schema.ZoneRRSetCreateRequest, 0, len(req.RRSets))
+	for _, rrset := range req.RRSets {
+		requested = append(requested, schema.ZoneRRSetCreateRequest{
+			Name:    rrset.Name,
+			Type:    rrset.Type,
+			TTL:     rrset.TTL,
+			Labels:  rrset.Labels,
+			Records: rrset.Records,
+		})
+	}
+	if req.Zonefile != "" {
+		parsed, err := parseZonefile(req.Zonefile, zone.Name)
+		if err != nil {
+			return 0, nil, err
+		}
+		requested = parsed
+	}
+	for _, rrsetReq := range requested {
+		rrset, err := newRRSet(0, rrsetReq)
+		if err != nil {
+			return 0, nil, err
+		}
+		rrsets[rrset.ID] = rrset
+	}
+
+	zone.ID = h.nextID()
+	for _, rrset := range rrsets {
+		rrset.Zone = zone.ID
+	}
+	h.zones[zone.ID] = zone
+	h.rrsets[zone.ID] = rrsets
+
+	a := h.newAction("create_zone", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil)
+
+	return http.StatusCreated, schema.ZoneCreateResponse{Zone: h.renderZone(zone), Action: a}, nil
+}
+
+func primaryNameserver(ns schema.ZoneChangePrimaryNameserversRequestPrimaryNameserver) schema.ZonePrimaryNameserver {
+	if ns.Port == 0 {
+		ns.Port = 53
+	}
+	return schema.ZonePrimaryNameserver(ns)
+}
+
+// defaultRRSets returns the SOA and NS RRSets created with every primary zone.
+func defaultRRSets(zone *schema.Zone) []*schema.ZoneRRSet {
+	soa := fmt.Sprintf("%s dns.hetzner.com. 2024010100 86400 10800 3600000 3600", assignedNameservers[0])
+
+	ns := make([]schema.ZoneRRSetRecord, 0, len(assignedNameservers))
+	for _, value := range assignedNameservers {
+		ns = append(ns, schema.ZoneRRSetRecord{Value: value})
+	}
+
+	return []*schema.ZoneRRSet{
+		{ID: "@/SOA", Name: "@", Type: "SOA", Labels: map[string]string{}, Records: []schema.ZoneRRSetRecord{{Value: soa}}, Zone: zone.ID},
+		{ID: "@/NS", Name: "@", Type: "NS", Labels: map[string]string{}, Records: ns, Zone: zone.ID},
+	}
+}
+
+func (h *Handler) updateZone(r *http.Request) (int, any, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.ZoneUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.Labels != nil {
+		zone.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.ZoneUpdateResponse{Zone: h.renderZone(zone)}, nil
+}
+
+func (h *Handler) deleteZone(r *http.Request) (int, any, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if zone.Protection.Delete {
+		return 0, nil, protectedError("zone")
+	}
+	if err := h.checkNotLocked("zone", zone.ID); err != nil {
+		return 0, nil, err
+	}
+
+	delete(h.zones, zone.ID)
+	delete(h.rrsets, zone.ID)
+
+	a := h.newAction("delete_zone", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil)
+	return http.StatusCreated, schema.ActionGetResponse{Action: a}, nil
+}
+
+func (h *Handler) exportZonefile(r *http.Request) (int, any, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if zone.Mode != "primary" {
+		return 0, nil, unprocessableError("incorrect_zone_mode", "zonefile export is only available for primary zones")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s.\n", zone.Name)
+	fmt.Fprintf(&b, "$TTL %d\n\n", zone.TTL)
+
+	rrsets := h.rrsets[zone.ID]
+	for _, id := range slices.Sorted(maps.Keys(rrsets)) {
+		rrset := rrsets[id]
+		for _, record := range rrset.Records {
+			b.WriteString(rrset.Name)
+			if rrset.TTL != nil {
+				fmt.Fprintf(&b, " %d", *rrset.TTL)
+			}
+			fmt.Fprintf(&b, " IN %s %s", rrset.Type, record.Value)
+			if record.Comment != "" {
+				fmt.Fprintf(&b, " ; %s", record.Comment)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return http.StatusOK, schema.ZoneExportZonefileResponse{Zonefile: b.String()}, nil
+}
+
+// parseZonefile parses a simple zone file, where every record is on a single line in
+// the form `<name> [<ttl>] [IN] <type> <value>`. Multi-line records are not supported.
+func parseZonefile(zonefile, zoneName string) ([]schema.ZoneRRSetCreateRequest, error) {
+	result := []schema.ZoneRRSetCreateRequest{}
+	index := map[string]int{}
+
+	for i, line := range strings.Split(zonefile, "\n") {
+		line, comment, _ := strings.Cut(line, ";")
+		comment = strings.TrimSpace(comment)
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "$ORIGIN":
+			if len(fields) != 2 || strings.TrimSuffix(fields[1], ".") != zoneName {
+				return nil, invalidInput("zonefile", fmt.Sprintf("line %d: $ORIGIN must match the zone name", i+1))
+			}
+			continue
+		case "$TTL":
+			continue
+		}
+		if strings.ContainsAny(line, "()") {
+			return nil, invalidInput("zonefile", fmt.Sprintf("line %d: multi-line records are not supported", i+1))
+		}
+
+		name := strings.TrimSuffix(strings.TrimSuffix(fields[0], "."), "."+zoneName)
+		if name == zoneName {
+			name = "@"
+		}
+		fields = fields[1:]
+
+		var ttl *int
+		if len(fields) > 0 {
+			if value, err := strconv.Atoi(fields[0]); err == nil {
+				ttl = &value
+				fields = fields[1:]
+			}
+		}
+		if len(fields) > 0 && fields[0] == "IN" {
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			return nil, invalidInput("zonefile", fmt.Sprintf("line %d: invalid record", i+1))
+		}
+
+		rrsetType := fields[0]
+		record := schema.ZoneRRSetRecord{Value: strings.Join(fields[1:], " "), Comment: comment}
+
+		key := name + "/" + rrsetType
+		if j, ok := index[key]; ok {
+			result[j].Records = append(result[j].Records, record)
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, schema.ZoneRRSetCreateRequest{
+			Name:    name,
+			Type:    rrsetType,
+			TTL:     ttl,
+			Records: []schema.ZoneRRSetRecord{record},
+		})
+	}
+
+	return result, nil
+}
+
+func (h *Handler) zoneImportZonefile(r *http.Request, zone *schema.Zone) (schema.Action, error) {
+	var req schema.ZoneImportZonefileRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if zone.Mode != "primary" {
+		return schema.Action{}, unprocessableError("incorrect_zone_mode", "zonefile import is only available for primary zones")
+	}
+
+	parsed, err := parseZonefile(req.Zonefile, zone.Name)
+	if err != nil {
+		return schema.Action{}, err
+	}
+
+	rrsets := map[string]*schema.ZoneRRSet{}
+	for _, rrsetReq := range parsed {
+		rrset, err := newRRSet(zone.ID, rrsetReq)
+		if err != nil {
+			return schema.Action{}, err
+		}
+		// Keep the labels and protection of the existing RRSets.
+		if existing, ok := h.rrsets[zone.ID][rrset.ID]; ok {
+			rrset.Labels = existing.Labels
+			rrset.Protection = existing.Protection
+		}
+		rrsets[rrset.ID] = rrset
+	}
+	h.rrsets[zone.ID] = rrsets
+
+	return h.newAction("import_zonefile", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil), nil
+}
+
+func (h *Handler) zoneChangeProtection(r *http.Request, zone *schema.Zone) (schema.Action, error) {
+	var req schema.ZoneChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.Delete != nil {
+		zone.Protection.Delete = *req.Delete
+	}
+	return h.newAction("change_protection", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil), nil
+}
+
+func (h *Handler) zoneChangeTTL(r *http.Request, zone *schema.Zone) (schema.Action, error) {
+	var req schema.ZoneChangeTTLRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if req.TTL < 60 {
+		return schema.Action{}, invalidInput("ttl", "ttl must be at least 60")
+	}
+	zone.TTL = req.TTL
+
+	return h.newAction("change_ttl", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil), nil
+}
+
+func (h *Handler) zoneChangePrimaryNameservers(r *http.Request, zone *schema.Zone) (schema.Action, error) {
+	var req schema.ZoneChangePrimaryNameserversRequest
+	if err := decodeBody(r, &req); err != nil {
+		return schema.Action{}, err
+	}
+	if zone.Mode != "secondary" {
+		return schema.Action{}, unprocessableError("incorrect_zone_mode", "primary nameservers can only be changed for secondary zones")
+	}
+	if len(req.PrimaryNameservers) == 0 {
+		return schema.Action{}, invalidInput("primary_nameservers", "primary_nameservers are required")
+	}
+
+	zone.PrimaryNameservers = make([]schema.ZonePrimaryNameserver, 0, len(req.PrimaryNameservers))
+	for _, ns := range req.PrimaryNameservers {
+		zone.PrimaryNameservers = append(zone.PrimaryNameservers, primaryNameserver(ns))
+	}
+
+	return h.newAction("change_primary_nameservers", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil), nil
+}
+
+// rrsetTypes are the RRSet types supported by the API.
+var rrsetTypes = []string{"A", "AAAA", "CAA", "CNAME", "DS", "HINFO", "HTTPS", "MX", "NS", "PTR", "RP", "SOA", "SRV", "SVCB", "TLSA", "TXT"}
+
+// newRRSet validates the request and returns a new RRSet.
+func newRRSet(zoneID int64, req schema.ZoneRRSetCreateRequest) (*schema.ZoneRRSet, error) {
+	switch {
+	case req.Name == "":
+		return nil, invalidInput("name", "name is required")
+	case req.Name != strings.ToLower(req.Name):
+		return nil, invalidInput("name", "name must be lowercase")
+	case !slices.Contains(rrsetTypes, req.Type):
+		return nil, invalidInput("type", fmt.Sprintf("unknown type '%s'", req.Type))
+	case len(req.Records) == 0:
+		return nil, invalidInput("records", "records are required")
+	}
+
+	return &schema.ZoneRRSet{
+		ID:      req.Name + "/" + req.Type,
+		Name:    req.Name,
+		Type:    req.Type,
+		TTL:     req.TTL,
+		Labels:  labelsOrEmpty(req.Labels),
+		Records: slices.Clone(req.Records),
+		Zone:    zoneID,
+	}, nil
+}
+
+// getRRSetFromPath returns the zone and the RRSet referenced in the request path.
+func (h *Handler) getRRSetFromPath(r *http.Request) (*schema.Zone, *schema.ZoneRRSet, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	rrset, ok := h.rrsets[zone.ID][r.PathValue("name")+"/"+r.PathValue("type")]
+	if !ok {
+		return nil, nil, notFound("rrset")
+	}
+	return zone, rrset, nil
+}
+
+// rrsetAction is similar to [resourceAction], for actions on RRSets. The actions
+// reference the zone of the RRSet.
+func (h *Handler) rrsetAction(fn func(r *http.Request, rrset *schema.ZoneRRSet) error) routeFunc {
+	return func(r *http.Request) (int, any, error) {
+		zone, rrset, err := h.getRRSetFromPath(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := h.checkNotLocked("zone", zone.ID); err != nil {
+			return 0, nil, err
+		}
+
+		if err := fn(r, rrset); err != nil {
+			return 0, nil, err
+		}
+
+		// Removing all the records of an RRSet deletes it.
+		if len(rrset.Records) == 0 {
+			delete(h.rrsets[zone.ID], rrset.ID)
+		}
+
+		command := strings.TrimPrefix(r.URL.Path[strings.LastIndex(r.URL.Path, "/"):], "/")
+		if command == "change_protection" {
+			command = "change_rrset_protection"
+		}
+		a := h.newAction(command, []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil)
+		return http.StatusCreated, schema.ActionGetResponse{Action: a}, nil
+	}
+}
+
+func (h *Handler) listRRSets(r *http.Request) (int, any, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rrsets := h.rrsets[zone.ID]
+	sorted := make([]*schema.ZoneRRSet, 0, len(rrsets))
+	for _, id := range slices.Sorted(maps.Keys(rrsets)) {
+		sorted = append(sorted, rrsets[id])
+	}
+
+	filtered, err := filterList(r, sorted, listFilter[schema.ZoneRRSet]{
+		name:   func(o *schema.ZoneRRSet) string { return o.Name },
+		labels: func(o *schema.ZoneRRSet) map[string]string { return o.Labels },
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query := r.URL.Query()
+	result := make([]schema.ZoneRRSet, 0, len(filtered))
+	for _, rrset := range filtered {
+		if query.Has("type") && !slices.Contains(query["type"], rrset.Type) {
+			continue
+		}
+		result = append(result, *rrset)
+	}
+	return listResponse(r, "rrsets", result)
+}
+
+func (h *Handler) getRRSet(r *http.Request) (int, any, error) {
+	_, rrset, err := h.getRRSetFromPath(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, schema.ZoneRRSetGetResponse{RRSet: *rrset}, nil
+}
+
+func (h *Handler) createRRSet(r *http.Request) (int, any, error) {
+	zone, err := h.zoneByIDOrName(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if zone.Mode != "primary" {
+		return 0, nil, unprocessableError("incorrect_zone_mode", "rrsets can only be created in primary zones")
+	}
+
+	var req schema.ZoneRRSetCreateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+
+	rrset, err := newRRSet(zone.ID, req)
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, ok := h.rrsets[zone.ID][rrset.ID]; ok {
+		return 0, nil, uniquenessError("name")
+	}
+	h.rrsets[zone.ID][rrset.ID] = rrset
+
+	a := h.newAction("create_rrset", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil)
+
+	return http.StatusCreated, schema.ZoneRRSetCreateResponse{RRSet: *rrset, Action: a}, nil
+}
+
+func (h *Handler) updateRRSet(r *http.Request) (int, any, error) {
+	_, rrset, err := h.getRRSetFromPath(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req schema.ZoneRRSetUpdateRequest
+	if err := decodeBody(r, &req); err != nil {
+		return 0, nil, err
+	}
+	if req.Labels != nil {
+		rrset.Labels = labelsOrEmpty(req.Labels)
+	}
+
+	return http.StatusOK, schema.ZoneRRSetUpdateResponse{RRSet: *rrset}, nil
+}
+
+func (h *Handler) deleteRRSet(r *http.Request) (int, any, error) {
+	zone, rrset, err := h.getRRSetFromPath(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if rrset.Protection.Change {
+		return 0, nil, protectedError("rrset")
+	}
+	if rrset.Type == "SOA" {
+		return 0, nil, unprocessableError("rrset_not_deletable", "the SOA rrset cannot be deleted")
+	}
+
+	delete(h.rrsets[zone.ID], rrset.ID)
+
+	a := h.newAction("delete_rrset", []schema.ActionResourceReference{resourceRef("zone", zone.ID)}, nil)
+	return http.StatusCreated, schema.ActionGetResponse{Action: a}, nil
+}
+
+func (h *Handler) rrsetChangeProtection(r *http.Request, rrset *schema.ZoneRRSet) error {
+	var req schema.ZoneRRSetChangeProtectionRequest
+	if err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	if req.Change != nil {
+		rrset.Protection.Change = *req.Change
+	}
+	return nil
+}
+
+func (h *Handler) rrsetChangeTTL(r *http.Request, rrset *schema.ZoneRRSet) error {
+	var req schema.ZoneRRSetChangeTTLRequest
+	if err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	if rrset.Protection.Change {
+		return protectedError("rrset")
+	}
+	rrset.TTL = req.TTL
+	return nil
+}
+
+func (h *Handler) rrsetSetRecords(r *http.Request, rrset *schema.ZoneRRSet) error {
+	var req schema.ZoneRRSetSetRecordsRequest
+	if err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	if rrset.Protection.Change {
+		return protectedError("rrset")
+	}
+	rrset.Records = slices.Clone(req.Records)
+	return nil
+}
+
+func (h *Handler) rrsetAddRecords(r *http.Request, rrset *schema.ZoneRRSet) error {
+	var req schema.ZoneRRSetAddRecordsRequest
+	if err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	if rrset.Protection.Change {
+		return protectedError("rrset")
+	}
+	for _, record := range req.Records {
+		if !slices.ContainsFunc(rrset.Records, func(o schema.ZoneRRSetRecord) bool { return o.Value == record.Value }) {
+			rrset.Records = append(rrset.Records, record)
+		}
+	}
+	if req.TTL != nil {
+		rrset.TTL = req.TTL
+	}
+	return nil
+}
+
+func (h *Handler) rrsetUpdateRecords(r *http.Request, rrset *schema.ZoneRRSet) error {
+	var req schema.ZoneRRSetUpdateRecordsRequest
+	if err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	if rrset.Protection.Change {
+		return protectedError("rrset")
+	}
+	for _, record := range req.Records {
+		index := slices.IndexFunc(rrset.Records, func(o schema.ZoneRRSetRecord) bool { return o.Value == record.Value })
+		if index < 0 {
+			return notFound("record")
+		}
+		rrset.Records[index].Comment = record.Comment
+	}
+	return nil
+}
+
+func (h *Handler) rrsetRemoveRecords(r *http.Request, rrset *schema.ZoneRRSet) error {
+	var req schema.ZoneRRSetRemoveRecordsRequest
+	if err := decodeBody(r, &req); err != nil {
+		return err
+	}
+	if rrset.Protection.Change {
+		return protectedError("rrset")
+	}
+	rrset.Records = slices.DeleteFunc(rrset.Records, func(o schema.ZoneRRSetRecord) bool {
+		return slices.ContainsFunc(req.Records, func(record schema.ZoneRRSetRecord) bool { return record.Value == o.Value })
+	})
+	return nil
+}
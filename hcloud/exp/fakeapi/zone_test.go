@@ -0,0 +1,88 @@
+package fakeapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestZoneRRSets(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	result, _, err := client.Zone.Create(ctx, hcloud.ZoneCreateOpts{
+		Name: "example.com",
+		Mode: hcloud.ZoneModePrimary,
+		RRSets: []hcloud.ZoneCreateOptsRRSet{
+			{Name: "www", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "203.0.113.1"}}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+	// Zones may be referenced by name.
+	zone, _, err := client.Zone.GetByName(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, result.Zone.ID, zone.ID)
+	assert.Equal(t, 5, zone.RecordCount)
+
+	rrsets, err := client.Zone.AllRRSetsWithOpts(ctx, zone, hcloud.ZoneRRSetListOpts{Type: []hcloud.ZoneRRSetType{hcloud.ZoneRRSetTypeA}})
+	require.NoError(t, err)
+	require.Len(t, rrsets, 1)
+	assert.Equal(t, "www/A", rrsets[0].ID)
+
+	action, _, err := client.Zone.AddRRSetRecords(ctx, rrsets[0], hcloud.ZoneRRSetAddRecordsOpts{
+		Records: []hcloud.ZoneRRSetRecord{{Value: "203.0.113.2", Comment: "second"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	rrset, _, err := client.Zone.GetRRSetByNameAndType(ctx, zone, "www", hcloud.ZoneRRSetTypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []hcloud.ZoneRRSetRecord{{Value: "203.0.113.1"}, {Value: "203.0.113.2", Comment: "second"}}, rrset.Records)
+
+	action, _, err = client.Zone.ChangeRRSetProtection(ctx, rrset, hcloud.ZoneRRSetChangeProtectionOpts{Change: hcloud.Ptr(true)})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	_, _, err = client.Zone.DeleteRRSet(ctx, rrset)
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeProtected))
+
+	export, _, err := client.Zone.ExportZonefile(ctx, zone)
+	require.NoError(t, err)
+	assert.Contains(t, export.Zonefile, "www IN A 203.0.113.2 ; second\n")
+}
+
+func TestZoneImportZonefile(t *testing.T) {
+	client := NewClient(t)
+	ctx := context.Background()
+
+	result, _, err := client.Zone.Create(ctx, hcloud.ZoneCreateOpts{Name: "example.com", Mode: hcloud.ZoneModePrimary})
+	require.NoError(t, err)
+
+	action, _, err := client.Zone.ImportZonefile(ctx, result.Zone, hcloud.ZoneImportZonefileOpts{Zonefile: `$ORIGIN example.com.
+$TTL 3600
+
+@ IN SOA hydrogen.ns.hetzner.com. dns.hetzner.com. 2024010100 86400 10800 3600000 3600
+@ IN NS hydrogen.ns.hetzner.com.
+mail 300 IN A 203.0.113.10 ; mail server
+mail 300 IN A 203.0.113.11
+`})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	rrset, _, err := client.Zone.GetRRSetByID(ctx, result.Zone, "mail/A")
+	require.NoError(t, err)
+	require.NotNil(t, rrset)
+	assert.Equal(t, 300, *rrset.TTL)
+	assert.Equal(t, []hcloud.ZoneRRSetRecord{{Value: "203.0.113.10", Comment: "mail server"}, {Value: "203.0.113.11"}}, rrset.Records)
+
+	_, _, err = client.Zone.ImportZonefile(ctx, result.Zone, hcloud.ZoneImportZonefileOpts{Zonefile: "$ORIGIN example.org.\n"})
+	require.Error(t, err)
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeInvalidInput))
+}
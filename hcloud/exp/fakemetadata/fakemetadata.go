@@ -0,0 +1,200 @@
+// Package fakemetadata implements a fake of the Hetzner Cloud Server Metadata service.
+//
+// The fake is plug-compatible with `metadata.WithEndpoint`:
+//
+//	server := fakemetadata.NewServer(t, fakemetadata.Metadata{Hostname: "my-server"})
+//	client := metadata.NewClient(metadata.WithEndpoint(server.Endpoint()))
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package fakemetadata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Path is the path of the metadata endpoint served by a [Server].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const Path = "/hetzner/v1/metadata"
+
+// Metadata holds the values served by a [Server].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Metadata struct {
+	Hostname         string
+	InstanceID       int64
+	PublicIPv4       string
+	PublicIPv6       string // CIDR notation, e.g. 2001:db8::1/64
+	Region           string
+	AvailabilityZone string
+	PublicKeys       []string
+	PrivateNetworks  []PrivateNetwork
+	UserData         string
+	VendorData       string
+}
+
+// PrivateNetwork holds the values of a private network attachment served by a [Server].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type PrivateNetwork struct {
+	IP           string   `yaml:"ip"`
+	AliasIPs     []string `yaml:"alias_ips"`
+	InterfaceNum int      `yaml:"interface_num"`
+	MACAddress   string   `yaml:"mac_address"`
+	NetworkID    int64    `yaml:"network_id"`
+	NetworkName  string   `yaml:"network_name"`
+	Network      string   `yaml:"network"`
+	Subnet       string   `yaml:"subnet"`
+	Gateway      string   `yaml:"gateway"`
+}
+
+// NewServer returns a new [Server] serving the metadata, the server is closed on the
+// test cleanup.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewServer(t *testing.T, metadata Metadata) *Server {
+	t.Helper()
+
+	s := &Server{metadata: metadata}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handler))
+	t.Cleanup(s.Server.Close)
+
+	return s
+}
+
+// Server embeds a [httptest.Server] that answers Hetzner Cloud Server Metadata
+// requests. The served [Metadata] may be updated during a test, for example to
+// simulate the attachment of a network.
+//
+// A Server must be created using the [NewServer] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	metadata    Metadata
+	unavailable bool
+}
+
+// Endpoint returns the metadata endpoint of the server.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Server) Endpoint() string {
+	return s.URL + Path
+}
+
+// Update modifies the served [Metadata].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Server) Update(fn func(metadata *Metadata)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.metadata)
+}
+
+// SetUnavailable configures the server to answer all requests with the
+// `503 Service Unavailable` status, to simulate an unreachable metadata service.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Server) SetUnavailable(unavailable bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unavailable = unavailable
+}
+
+func (s *Server) handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.Lock()
+	metadata, unavailable := s.metadata, s.unavailable
+	s.mu.Unlock()
+
+	if unavailable {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var body []byte
+	var err error
+
+	switch r.URL.Path {
+	case Path:
+		body, err = yaml.Marshal(rootMetadata(metadata))
+	case Path + "/hostname":
+		body = []byte(metadata.Hostname)
+	case Path + "/instance-id":
+		body = []byte(strconv.FormatInt(metadata.InstanceID, 10))
+	case Path + "/public-ipv4":
+		body = []byte(metadata.PublicIPv4)
+	case Path + "/region":
+		body = []byte(metadata.Region)
+	case Path + "/availability-zone":
+		body = []byte(metadata.AvailabilityZone)
+	case Path + "/public-keys":
+		body, err = json.Marshal(nonNil(metadata.PublicKeys))
+	case Path + "/private-networks":
+		body, err = yaml.Marshal(nonNil(metadata.PrivateNetworks))
+	case "/hetzner/v1/userdata":
+		body = []byte(metadata.UserData)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = w.Write(body)
+}
+
+func rootMetadata(metadata Metadata) map[string]any {
+	subnets := []map[string]any{{"type": "dhcp", "ipv4": true}}
+	if metadata.PublicIPv6 != "" {
+		subnets = append(subnets, map[string]any{
+			"type":    "static",
+			"address": metadata.PublicIPv6,
+			"gateway": "fe80::1",
+			"ipv6":    true,
+		})
+	}
+
+	return map[string]any{
+		"hostname":          metadata.Hostname,
+		"instance-id":       metadata.InstanceID,
+		"public-ipv4":       metadata.PublicIPv4,
+		"region":            metadata.Region,
+		"availability-zone": metadata.AvailabilityZone,
+		"public-keys":       nonNil(metadata.PublicKeys),
+		"private-networks":  nonNil(metadata.PrivateNetworks),
+		"vendor_data":       metadata.VendorData,
+		"network-config": map[string]any{
+			"version": 1,
+			"config": []map[string]any{{
+				"type":    "physical",
+				"name":    "eth0",
+				"subnets": subnets,
+			}},
+		},
+	}
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package fakemetadata
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(t, Metadata{
+		Hostname:   "my-server",
+		InstanceID: 42,
+		PrivateNetworks: []PrivateNetwork{
+			{IP: "10.0.0.2", AliasIPs: []string{"10.0.0.3"}, NetworkID: 1},
+		},
+		UserData: "#cloud-config\n",
+	})
+
+	status, body := get(t, server.Endpoint()+"/hostname")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "my-server", body)
+
+	_, body = get(t, server.Endpoint()+"/instance-id")
+	assert.Equal(t, "42", body)
+
+	_, body = get(t, server.Endpoint()+"/public-keys")
+	assert.Equal(t, "[]", body)
+
+	_, body = get(t, server.Endpoint()+"/private-networks")
+	assert.Contains(t, body, "alias_ips:\n    - 10.0.0.3\n")
+
+	_, body = get(t, server.URL+"/hetzner/v1/userdata")
+	assert.Equal(t, "#cloud-config\n", body)
+
+	status, _ = get(t, server.Endpoint()+"/unknown")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	server.Update(func(metadata *Metadata) {
+		metadata.Hostname = "renamed"
+	})
+
+	_, body = get(t, server.Endpoint()+"/hostname")
+	assert.Equal(t, "renamed", body)
+
+	server.SetUnavailable(true)
+	status, _ = get(t, server.Endpoint()+"/hostname")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
@@ -0,0 +1,216 @@
+// Package firewallutil converges a Firewall to a declarative specification.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package firewallutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Spec is the desired state of a [hcloud.Firewall].
+//
+// The order of the rules and resources is not significant.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Spec struct {
+	Rules   []hcloud.FirewallRule
+	ApplyTo []hcloud.FirewallResource
+}
+
+// SpecFromCreateOpts returns the [Spec] defined by the create options of a Firewall.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func SpecFromCreateOpts(opts hcloud.FirewallCreateOpts) Spec {
+	return Spec{Rules: opts.Rules, ApplyTo: opts.ApplyTo}
+}
+
+// Plan is the list of changes required to converge a [hcloud.Firewall] to a [Spec].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Plan struct {
+	Firewall *hcloud.Firewall
+
+	// Rules is the complete list of rules to set on the Firewall, nil if the rules are
+	// already up to date. The API only allows replacing all rules at once.
+	Rules []hcloud.FirewallRule
+	// AddRules and RemoveRules describe the difference between the current and desired
+	// rules.
+	AddRules    []hcloud.FirewallRule
+	RemoveRules []hcloud.FirewallRule
+
+	ApplyResources  []hcloud.FirewallResource
+	RemoveResources []hcloud.FirewallResource
+}
+
+// Empty reports whether the Firewall is already in the desired state.
+func (p Plan) Empty() bool {
+	return p.Rules == nil && len(p.ApplyResources) == 0 && len(p.RemoveResources) == 0
+}
+
+// Diff computes the [Plan] to converge the firewall to the spec.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Diff(firewall *hcloud.Firewall, spec Spec) Plan {
+	plan := Plan{Firewall: firewall}
+
+	plan.AddRules, plan.RemoveRules = diff(firewall.Rules, spec.Rules, ruleKey)
+	if len(plan.AddRules) > 0 || len(plan.RemoveRules) > 0 {
+		plan.Rules = spec.Rules
+		if plan.Rules == nil {
+			plan.Rules = []hcloud.FirewallRule{}
+		}
+	}
+
+	plan.ApplyResources, plan.RemoveResources = diff(firewall.AppliedTo, spec.ApplyTo, resourceKey)
+
+	return plan
+}
+
+// ReconcileOpts specifies options for [Reconcile].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type ReconcileOpts struct {
+	// DryRun only computes the plan, without applying it.
+	DryRun bool
+}
+
+// Reconcile fetches the current state of the firewall, computes the [Plan] to converge
+// it to the spec, and applies the plan unless [ReconcileOpts.DryRun] is set.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Reconcile(ctx context.Context, client *hcloud.Client, firewall *hcloud.Firewall, spec Spec, opts ReconcileOpts) (Plan, error) {
+	current, _, err := client.Firewall.GetByID(ctx, firewall.ID)
+	if err != nil {
+		return Plan{}, err
+	}
+	if current == nil {
+		return Plan{}, fmt.Errorf("firewall not found: %d", firewall.ID)
+	}
+
+	plan := Diff(current, spec)
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	return plan, Apply(ctx, client, plan)
+}
+
+// Apply applies the plan using the Firewall client, and waits for the resulting
+// actions to complete. The rules are set first, then the resources are removed, and
+// finally the new resources are applied.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Apply(ctx context.Context, client *hcloud.Client, plan Plan) error {
+	if plan.Rules != nil {
+		actions, _, err := client.Firewall.SetRules(ctx, plan.Firewall, hcloud.FirewallSetRulesOpts{Rules: plan.Rules})
+		if err != nil {
+			return fmt.Errorf("could not set firewall rules: %w", err)
+		}
+		if err := client.Action.WaitFor(ctx, actions...); err != nil {
+			return fmt.Errorf("could not set firewall rules: %w", err)
+		}
+	}
+
+	if len(plan.RemoveResources) > 0 {
+		actions, _, err := client.Firewall.RemoveResources(ctx, plan.Firewall, plan.RemoveResources)
+		if err != nil {
+			return fmt.Errorf("could not remove firewall resources: %w", err)
+		}
+		if err := client.Action.WaitFor(ctx, actions...); err != nil {
+			return fmt.Errorf("could not remove firewall resources: %w", err)
+		}
+	}
+
+	if len(plan.ApplyResources) > 0 {
+		actions, _, err := client.Firewall.ApplyResources(ctx, plan.Firewall, plan.ApplyResources)
+		if err != nil {
+			return fmt.Errorf("could not apply firewall resources: %w", err)
+		}
+		if err := client.Action.WaitFor(ctx, actions...); err != nil {
+			return fmt.Errorf("could not apply firewall resources: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// diff returns the desired items missing from the current items, and the current
+// items missing from the desired items. Items are compared using their key.
+func diff[T any](current, desired []T, key func(T) string) (add, remove []T) {
+	currentKeys := make(map[string]int, len(current))
+	for _, item := range current {
+		currentKeys[key(item)]++
+	}
+	desiredKeys := make(map[string]int, len(desired))
+	for _, item := range desired {
+		desiredKeys[key(item)]++
+	}
+
+	for _, item := range desired {
+		k := key(item)
+		if currentKeys[k] > 0 {
+			currentKeys[k]--
+			continue
+		}
+		add = append(add, item)
+	}
+	for _, item := range current {
+		k := key(item)
+		if desiredKeys[k] > 0 {
+			desiredKeys[k]--
+			continue
+		}
+		remove = append(remove, item)
+	}
+	return add, remove
+}
+
+// ruleKey returns a canonical representation of the rule, unset and empty fields are
+// equivalent, and the order of the IPs is not significant.
+func ruleKey(rule hcloud.FirewallRule) string {
+	ips := func(values []net.IPNet) string {
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			result = append(result, value.String())
+		}
+		slices.Sort(result)
+		return strings.Join(result, ",")
+	}
+	deref := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	return strings.Join([]string{
+		string(rule.Direction),
+		string(rule.Protocol),
+		deref(rule.Port),
+		ips(rule.SourceIPs),
+		ips(rule.DestinationIPs),
+		deref(rule.Description),
+	}, "|")
+}
+
+// resourceKey returns a canonical representation of the resource, the resources
+// matched by a label selector are ignored.
+func resourceKey(resource hcloud.FirewallResource) string {
+	switch resource.Type {
+	case hcloud.FirewallResourceTypeServer:
+		if resource.Server != nil {
+			return fmt.Sprintf("server|%d", resource.Server.ID)
+		}
+	case hcloud.FirewallResourceTypeLabelSelector:
+		if resource.LabelSelector != nil {
+			return "label_selector|" + resource.LabelSelector.Selector
+		}
+	}
+	return string(resource.Type)
+}
@@ -0,0 +1,152 @@
+package firewallutil
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func mustParseCIDR(t *testing.T, value string) net.IPNet {
+	t.Helper()
+
+	_, ipNet, err := net.ParseCIDR(value)
+	require.NoError(t, err)
+	return *ipNet
+}
+
+func TestDiff(t *testing.T) {
+	ssh := hcloud.FirewallRule{
+		Direction: hcloud.FirewallRuleDirectionIn,
+		Protocol:  hcloud.FirewallRuleProtocolTCP,
+		Port:      hcloud.Ptr("22"),
+		SourceIPs: []net.IPNet{mustParseCIDR(t, "10.0.0.0/8"), mustParseCIDR(t, "::/0")},
+	}
+	sshReordered := ssh
+	sshReordered.SourceIPs = []net.IPNet{mustParseCIDR(t, "::/0"), mustParseCIDR(t, "10.0.0.0/8")}
+	sshReordered.Description = hcloud.Ptr("")
+
+	http := hcloud.FirewallRule{
+		Direction: hcloud.FirewallRuleDirectionIn,
+		Protocol:  hcloud.FirewallRuleProtocolTCP,
+		Port:      hcloud.Ptr("80"),
+		SourceIPs: []net.IPNet{mustParseCIDR(t, "0.0.0.0/0")},
+	}
+
+	server1 := hcloud.FirewallResource{Type: hcloud.FirewallResourceTypeServer, Server: &hcloud.FirewallResourceServer{ID: 1}}
+	server2 := hcloud.FirewallResource{Type: hcloud.FirewallResourceTypeServer, Server: &hcloud.FirewallResourceServer{ID: 2}}
+	selector := hcloud.FirewallResource{Type: hcloud.FirewallResourceTypeLabelSelector, LabelSelector: &hcloud.FirewallResourceLabelSelector{Selector: "env=prod"}}
+	selectorApplied := selector
+	selectorApplied.AppliedToResources = []hcloud.FirewallResource{server2}
+
+	t.Run("up to date", func(t *testing.T) {
+		firewall := &hcloud.Firewall{
+			ID:        1,
+			Rules:     []hcloud.FirewallRule{http, ssh},
+			AppliedTo: []hcloud.FirewallResource{selectorApplied, server1},
+		}
+
+		plan := Diff(firewall, Spec{
+			Rules:   []hcloud.FirewallRule{sshReordered, http},
+			ApplyTo: []hcloud.FirewallResource{server1, selector},
+		})
+		assert.True(t, plan.Empty())
+		assert.Nil(t, plan.Rules)
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		firewall := &hcloud.Firewall{
+			ID:        1,
+			Rules:     []hcloud.FirewallRule{ssh},
+			AppliedTo: []hcloud.FirewallResource{server1},
+		}
+
+		plan := Diff(firewall, Spec{
+			Rules:   []hcloud.FirewallRule{http},
+			ApplyTo: []hcloud.FirewallResource{server2, selector},
+		})
+		assert.False(t, plan.Empty())
+		assert.Equal(t, []hcloud.FirewallRule{http}, plan.Rules)
+		assert.Equal(t, []hcloud.FirewallRule{http}, plan.AddRules)
+		assert.Equal(t, []hcloud.FirewallRule{ssh}, plan.RemoveRules)
+		assert.Equal(t, []hcloud.FirewallResource{server2, selector}, plan.ApplyResources)
+		assert.Equal(t, []hcloud.FirewallResource{server1}, plan.RemoveResources)
+	})
+
+	t.Run("remove all rules", func(t *testing.T) {
+		firewall := &hcloud.Firewall{
+			ID:    1,
+			Rules: []hcloud.FirewallRule{ssh},
+		}
+
+		plan := Diff(firewall, Spec{})
+		assert.False(t, plan.Empty())
+		assert.Equal(t, []hcloud.FirewallRule{}, plan.Rules)
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	serverResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, serverResult.Action))
+
+	result, _, err := client.Firewall.Create(ctx, hcloud.FirewallCreateOpts{
+		Name: "firewall",
+		Rules: []hcloud.FirewallRule{{
+			Direction: hcloud.FirewallRuleDirectionIn,
+			Protocol:  hcloud.FirewallRuleProtocolICMP,
+			SourceIPs: []net.IPNet{mustParseCIDR(t, "0.0.0.0/0")},
+		}},
+	})
+	require.NoError(t, err)
+
+	spec := SpecFromCreateOpts(hcloud.FirewallCreateOpts{
+		Rules: []hcloud.FirewallRule{{
+			Direction: hcloud.FirewallRuleDirectionIn,
+			Protocol:  hcloud.FirewallRuleProtocolTCP,
+			Port:      hcloud.Ptr("22"),
+			SourceIPs: []net.IPNet{mustParseCIDR(t, "0.0.0.0/0")},
+		}},
+		ApplyTo: []hcloud.FirewallResource{
+			{Type: hcloud.FirewallResourceTypeServer, Server: &hcloud.FirewallResourceServer{ID: serverResult.Server.ID}},
+		},
+	})
+
+	plan, err := Reconcile(ctx, client, result.Firewall, spec, ReconcileOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, plan.Empty())
+	assert.Len(t, plan.AddRules, 1)
+	assert.Len(t, plan.RemoveRules, 1)
+	assert.Len(t, plan.ApplyResources, 1)
+
+	firewall, _, err := client.Firewall.GetByID(ctx, result.Firewall.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.FirewallRuleProtocolICMP, firewall.Rules[0].Protocol)
+
+	plan, err = Reconcile(ctx, client, result.Firewall, spec, ReconcileOpts{})
+	require.NoError(t, err)
+	assert.False(t, plan.Empty())
+
+	firewall, _, err = client.Firewall.GetByID(ctx, result.Firewall.ID)
+	require.NoError(t, err)
+	require.Len(t, firewall.Rules, 1)
+	assert.Equal(t, hcloud.FirewallRuleProtocolTCP, firewall.Rules[0].Protocol)
+	require.Len(t, firewall.AppliedTo, 1)
+	assert.Equal(t, serverResult.Server.ID, firewall.AppliedTo[0].Server.ID)
+
+	plan, err = Reconcile(ctx, client, result.Firewall, spec, ReconcileOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+}
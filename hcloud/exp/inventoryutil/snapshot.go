@@ -0,0 +1,229 @@
+// Package inventoryutil exports the configuration of a project into a versioned JSON
+// snapshot, for audits and disaster recovery.
+//
+// A snapshot is built from the API using the `schema` package types, and may be
+// loaded back into `hcloud` types, for example to compare two snapshots offline:
+//
+//	snapshot, err := inventoryutil.Export(ctx, client)
+//	err = snapshot.Write(file)
+//
+//	snapshot, err := inventoryutil.Read(file)
+//	inventory := snapshot.Inventory()
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package inventoryutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// SnapshotVersion is the version of the snapshot format written by this package.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const SnapshotVersion = 1
+
+// Snapshot holds the configuration of a project at a point in time.
+//
+// The certificates only hold their metadata, the certificate content is not exported.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Snapshot struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+
+	Servers         []schema.Server         `json:"servers"`
+	Volumes         []schema.Volume         `json:"volumes"`
+	Networks        []schema.Network        `json:"networks"`
+	Firewalls       []schema.Firewall       `json:"firewalls"`
+	LoadBalancers   []schema.LoadBalancer   `json:"load_balancers"`
+	Certificates    []schema.Certificate    `json:"certificates"`
+	SSHKeys         []schema.SSHKey         `json:"ssh_keys"`
+	PrimaryIPs      []schema.PrimaryIP      `json:"primary_ips"`
+	FloatingIPs     []schema.FloatingIP     `json:"floating_ips"`
+	Zones           []schema.Zone           `json:"zones"`
+	ZoneRRSets      []schema.ZoneRRSet      `json:"zone_rrsets"`
+	PlacementGroups []schema.PlacementGroup `json:"placement_groups"`
+}
+
+// Export lists the resources of the project and returns them in a [Snapshot].
+//
+// The resources are listed one type after the other, the snapshot is therefore not
+// atomic if the project is modified during the export.
+//
+// The snapshot does not contain secrets: the certificates and the TSIG keys of the
+// zone primary nameservers are cleared.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Export(ctx context.Context, client *hcloud.Client) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Version: SnapshotVersion,
+		Created: time.Now().UTC(),
+	}
+
+	servers, err := client.Server.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	snapshot.Servers = convert(servers, hcloud.SchemaFromServer)
+
+	volumes, err := client.Volume.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list volumes: %w", err)
+	}
+	snapshot.Volumes = convert(volumes, hcloud.SchemaFromVolume)
+
+	networks, err := client.Network.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list networks: %w", err)
+	}
+	snapshot.Networks = convert(networks, hcloud.SchemaFromNetwork)
+
+	firewalls, err := client.Firewall.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list firewalls: %w", err)
+	}
+	snapshot.Firewalls = convert(firewalls, hcloud.SchemaFromFirewall)
+
+	loadBalancers, err := client.LoadBalancer.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list load balancers: %w", err)
+	}
+	snapshot.LoadBalancers = convert(loadBalancers, hcloud.SchemaFromLoadBalancer)
+
+	certificates, err := client.Certificate.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list certificates: %w", err)
+	}
+	snapshot.Certificates = convert(certificates, func(certificate *hcloud.Certificate) schema.Certificate {
+		result := hcloud.SchemaFromCertificate(certificate)
+		result.Certificate = ""
+		return result
+	})
+
+	sshKeys, err := client.SSHKey.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list ssh keys: %w", err)
+	}
+	snapshot.SSHKeys = convert(sshKeys, hcloud.SchemaFromSSHKey)
+
+	primaryIPs, err := client.PrimaryIP.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list primary ips: %w", err)
+	}
+	snapshot.PrimaryIPs = convert(primaryIPs, hcloud.SchemaFromPrimaryIP)
+
+	floatingIPs, err := client.FloatingIP.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list floating ips: %w", err)
+	}
+	snapshot.FloatingIPs = convert(floatingIPs, hcloud.SchemaFromFloatingIP)
+
+	zones, err := client.Zone.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list zones: %w", err)
+	}
+	snapshot.Zones = convert(zones, func(zone *hcloud.Zone) schema.Zone {
+		result := hcloud.SchemaFromZone(zone)
+		for i := range result.PrimaryNameservers {
+			result.PrimaryNameservers[i].TSIGKey = ""
+		}
+		return result
+	})
+	snapshot.ZoneRRSets = []schema.ZoneRRSet{}
+	for _, zone := range zones {
+		rrsets, err := client.Zone.AllRRSets(ctx, zone)
+		if err != nil {
+			return nil, fmt.Errorf("could not list rrsets of zone %s: %w", zone.Name, err)
+		}
+		snapshot.ZoneRRSets = append(snapshot.ZoneRRSets, convert(rrsets, hcloud.SchemaFromZoneRRSet)...)
+	}
+
+	placementGroups, err := client.PlacementGroup.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list placement groups: %w", err)
+	}
+	snapshot.PlacementGroups = convert(placementGroups, hcloud.SchemaFromPlacementGroup)
+
+	return snapshot, nil
+}
+
+// Write encodes the snapshot as indented JSON.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Read decodes a snapshot previously encoded with [Snapshot.Write]. An error is
+// returned if the version of the snapshot is not supported.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Read(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+// Inventory holds the resources of a [Snapshot] as `hcloud` types.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Inventory struct {
+	Created time.Time
+
+	Servers         []*hcloud.Server
+	Volumes         []*hcloud.Volume
+	Networks        []*hcloud.Network
+	Firewalls       []*hcloud.Firewall
+	LoadBalancers   []*hcloud.LoadBalancer
+	Certificates    []*hcloud.Certificate
+	SSHKeys         []*hcloud.SSHKey
+	PrimaryIPs      []*hcloud.PrimaryIP
+	FloatingIPs     []*hcloud.FloatingIP
+	Zones           []*hcloud.Zone
+	ZoneRRSets      []*hcloud.ZoneRRSet
+	PlacementGroups []*hcloud.PlacementGroup
+}
+
+// Inventory converts the resources of the snapshot to `hcloud` types.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Snapshot) Inventory() *Inventory {
+	return &Inventory{
+		Created: s.Created,
+
+		Servers:         convert(s.Servers, hcloud.ServerFromSchema),
+		Volumes:         convert(s.Volumes, hcloud.VolumeFromSchema),
+		Networks:        convert(s.Networks, hcloud.NetworkFromSchema),
+		Firewalls:       convert(s.Firewalls, hcloud.FirewallFromSchema),
+		LoadBalancers:   convert(s.LoadBalancers, hcloud.LoadBalancerFromSchema),
+		Certificates:    convert(s.Certificates, hcloud.CertificateFromSchema),
+		SSHKeys:         convert(s.SSHKeys, hcloud.SSHKeyFromSchema),
+		PrimaryIPs:      convert(s.PrimaryIPs, hcloud.PrimaryIPFromSchema),
+		FloatingIPs:     convert(s.FloatingIPs, hcloud.FloatingIPFromSchema),
+		Zones:           convert(s.Zones, hcloud.ZoneFromSchema),
+		ZoneRRSets:      convert(s.ZoneRRSets, hcloud.ZoneRRSetFromSchema),
+		PlacementGroups: convert(s.PlacementGroups, hcloud.PlacementGroupFromSchema),
+	}
+}
+
+func convert[I, O any](in []I, fn func(I) O) []O {
+	out := make([]O, 0, len(in))
+	for _, item := range in {
+		out = append(out, fn(item))
+	}
+	return out
+}
@@ -0,0 +1,223 @@
+package inventoryutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+// newTestClient returns a client using a [fakeapi.Server], answering from static
+// lists for the resources not supported by the fake.
+func newTestClient(t *testing.T) *hcloud.Client {
+	t.Helper()
+
+	writeJSON := func(w http.ResponseWriter, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	return fakeapi.NewClient(t,
+		fakeapi.WithHandlerFunc("GET /certificates", func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, schema.CertificateListResponse{Certificates: []schema.Certificate{{
+				ID:          1001,
+				Name:        "cert",
+				Type:        "uploaded",
+				Certificate: "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----",
+				DomainNames: []string{"example.com"},
+				Fingerprint: "03:c7:55:9b",
+			}}})
+		}),
+		fakeapi.WithHandlerFunc("GET /ssh_keys", func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, schema.SSHKeyListResponse{SSHKeys: []schema.SSHKey{{
+				ID:          1002,
+				Name:        "key",
+				Fingerprint: "b7:2f:30:a0",
+				PublicKey:   "ssh-ed25519 AAAA",
+			}}})
+		}),
+		fakeapi.WithHandlerFunc("GET /floating_ips", func(w http.ResponseWriter, _ *http.Request) {
+			writeJSON(w, schema.FloatingIPListResponse{FloatingIPs: []schema.FloatingIP{{
+				ID:           1003,
+				Name:         "floating",
+				Type:         "ipv4",
+				IP:           "131.232.99.1",
+				HomeLocation: schema.Location{Name: "fsn1"},
+			}}})
+		}),
+	)
+}
+
+func setupProject(t *testing.T, client *hcloud.Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, ipRange, _ := net.ParseCIDR("10.0.0.0/16")
+	_, subnetRange, _ := net.ParseCIDR("10.0.1.0/24")
+	_, routeDestination, _ := net.ParseCIDR("10.100.0.0/24")
+	network, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+		Name:    "network",
+		IPRange: ipRange,
+		Subnets: []hcloud.NetworkSubnet{{Type: hcloud.NetworkSubnetTypeCloud, IPRange: subnetRange, NetworkZone: hcloud.NetworkZoneEUCentral}},
+		Routes:  []hcloud.NetworkRoute{{Destination: routeDestination, Gateway: net.ParseIP("10.0.1.10")}},
+	})
+	require.NoError(t, err)
+
+	_, allIPs, _ := net.ParseCIDR("0.0.0.0/0")
+	_, _, err = client.Firewall.Create(ctx, hcloud.FirewallCreateOpts{
+		Name: "firewall",
+		Rules: []hcloud.FirewallRule{{
+			Direction: hcloud.FirewallRuleDirectionIn,
+			Protocol:  hcloud.FirewallRuleProtocolTCP,
+			Port:      hcloud.Ptr("22"),
+			SourceIPs: []net.IPNet{*allIPs},
+		}},
+	})
+	require.NoError(t, err)
+
+	_, _, err = client.PlacementGroup.Create(ctx, hcloud.PlacementGroupCreateOpts{Name: "pg", Type: hcloud.PlacementGroupTypeSpread})
+	require.NoError(t, err)
+
+	_, _, err = client.Volume.Create(ctx, hcloud.VolumeCreateOpts{Name: "data", Size: 10, Location: &hcloud.Location{Name: "fsn1"}})
+	require.NoError(t, err)
+
+	serverResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "web",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+		Networks:   []*hcloud.Network{network},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, serverResult.Action))
+
+	lbResult, _, err := client.LoadBalancer.Create(ctx, hcloud.LoadBalancerCreateOpts{
+		Name:             "lb",
+		LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb11"},
+		Location:         &hcloud.Location{Name: "fsn1"},
+		Services:         []hcloud.LoadBalancerCreateOptsService{{Protocol: hcloud.LoadBalancerServiceProtocolTCP, ListenPort: hcloud.Ptr(80), DestinationPort: hcloud.Ptr(80)}},
+		Targets:          []hcloud.LoadBalancerCreateOptsTarget{{Type: hcloud.LoadBalancerTargetTypeServer, Server: hcloud.LoadBalancerCreateOptsTargetServer{Server: serverResult.Server}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, lbResult.Action))
+
+	zoneResult, _, err := client.Zone.Create(ctx, hcloud.ZoneCreateOpts{
+		Name: "example.com",
+		Mode: hcloud.ZoneModePrimary,
+		RRSets: []hcloud.ZoneCreateOptsRRSet{
+			{Name: "www", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.35"}}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, zoneResult.Action))
+
+	zoneResult, _, err = client.Zone.Create(ctx, hcloud.ZoneCreateOpts{
+		Name: "example.org",
+		Mode: hcloud.ZoneModeSecondary,
+		PrimaryNameservers: []hcloud.ZoneCreateOptsPrimaryNameserver{
+			{Address: "203.0.113.10", Port: 53, TSIGAlgorithm: hcloud.ZoneTSIGAlgorithmHMACSHA256, TSIGKey: "secret-tsig-key"},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, zoneResult.Action))
+}
+
+func TestExport(t *testing.T) {
+	client := newTestClient(t)
+	setupProject(t, client)
+
+	snapshot, err := Export(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.Equal(t, SnapshotVersion, snapshot.Version)
+	assert.False(t, snapshot.Created.IsZero())
+
+	require.Len(t, snapshot.Servers, 1)
+	assert.Equal(t, "web", snapshot.Servers[0].Name)
+	require.Len(t, snapshot.Volumes, 1)
+	assert.Equal(t, "data", snapshot.Volumes[0].Name)
+	require.Len(t, snapshot.Networks, 1)
+	assert.Len(t, snapshot.Networks[0].Subnets, 1)
+	assert.Len(t, snapshot.Networks[0].Routes, 1)
+	require.Len(t, snapshot.Firewalls, 1)
+	assert.Len(t, snapshot.Firewalls[0].Rules, 1)
+	require.Len(t, snapshot.LoadBalancers, 1)
+	assert.Len(t, snapshot.LoadBalancers[0].Services, 1)
+	assert.Len(t, snapshot.LoadBalancers[0].Targets, 1)
+	require.Len(t, snapshot.Certificates, 1)
+	assert.Equal(t, "cert", snapshot.Certificates[0].Name)
+	assert.Empty(t, snapshot.Certificates[0].Certificate)
+	assert.Equal(t, []string{"example.com"}, snapshot.Certificates[0].DomainNames)
+	require.Len(t, snapshot.SSHKeys, 1)
+	assert.Equal(t, "key", snapshot.SSHKeys[0].Name)
+	assert.NotEmpty(t, snapshot.PrimaryIPs)
+	require.Len(t, snapshot.FloatingIPs, 1)
+	assert.Equal(t, "floating", snapshot.FloatingIPs[0].Name)
+	require.Len(t, snapshot.Zones, 2)
+	assert.Equal(t, "example.com", snapshot.Zones[0].Name)
+	assert.True(t, slices.ContainsFunc(snapshot.ZoneRRSets, func(rrset schema.ZoneRRSet) bool {
+		return rrset.Name == "www" && rrset.Type == "A" && rrset.Zone == snapshot.Zones[0].ID
+	}))
+	assert.Equal(t, "example.org", snapshot.Zones[1].Name)
+	require.Len(t, snapshot.Zones[1].PrimaryNameservers, 1)
+	assert.Equal(t, "203.0.113.10", snapshot.Zones[1].PrimaryNameservers[0].Address)
+	assert.Empty(t, snapshot.Zones[1].PrimaryNameservers[0].TSIGKey)
+	require.Len(t, snapshot.PlacementGroups, 1)
+	assert.Equal(t, "pg", snapshot.PlacementGroups[0].Name)
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	client := newTestClient(t)
+	setupProject(t, client)
+
+	snapshot, err := Export(context.Background(), client)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, snapshot.Write(buf))
+
+	loaded, err := Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.Servers, loaded.Servers)
+	assert.Equal(t, snapshot.Networks, loaded.Networks)
+	assert.Equal(t, snapshot.ZoneRRSets, loaded.ZoneRRSets)
+
+	inventory := loaded.Inventory()
+	assert.True(t, snapshot.Created.Equal(inventory.Created))
+	require.Len(t, inventory.Servers, 1)
+	assert.Equal(t, "web", inventory.Servers[0].Name)
+	require.Len(t, inventory.Networks, 1)
+	assert.Equal(t, "10.0.1.0/24", inventory.Networks[0].Subnets[0].IPRange.String())
+	assert.Equal(t, "10.100.0.0/24", inventory.Networks[0].Routes[0].Destination.String())
+	require.Len(t, inventory.LoadBalancers, 1)
+	assert.Equal(t, inventory.Servers[0].ID, inventory.LoadBalancers[0].Targets[0].Server.Server.ID)
+	require.Len(t, inventory.Certificates, 1)
+	assert.Equal(t, "cert", inventory.Certificates[0].Name)
+	require.Len(t, inventory.Zones, 2)
+	for _, rrset := range inventory.ZoneRRSets {
+		assert.Contains(t, []int64{inventory.Zones[0].ID, inventory.Zones[1].ID}, rrset.Zone.ID)
+	}
+	assert.Len(t, inventory.PlacementGroups, 1)
+}
+
+func TestRead(t *testing.T) {
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := Read(strings.NewReader(`{"version": 2}`))
+		assert.EqualError(t, err, "unsupported snapshot version: 2")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := Read(strings.NewReader(`{`))
+		assert.ErrorContains(t, err, "invalid snapshot: ")
+	})
+}
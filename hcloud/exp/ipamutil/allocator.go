@@ -0,0 +1,236 @@
+// Package ipamutil allocates subnets and private IPs of networks before calling the
+// API, to avoid `ip_not_available`, `no_subnet_available` and `networks_overlap`
+// errors:
+//
+//	allocator, err := ipamutil.Load(ctx, client, network)
+//	subnet, err := allocator.NextSubnet(hcloud.NetworkZoneEUCentral, 24)
+//	ip, err := allocator.NextIP(hcloud.NetworkZoneEUCentral)
+//
+// The allocations are only tracked in memory, concurrent allocations by other
+// clients are not detected until the API is called.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package ipamutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Allocator allocates the subnets and private IPs of a network.
+//
+// An Allocator must be created using the [NewAllocator] or [Load] functions.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Allocator struct {
+	network  *hcloud.Network
+	ipRange  netip.Prefix
+	subnets  []hcloud.NetworkSubnet
+	used     map[netip.Addr]struct{}
+	networks []*hcloud.Network
+}
+
+// NewAllocator returns a new [Allocator] for the network.
+//
+// The IPs and alias IPs of the servers and load balancers attached to the network are
+// considered in use, the servers and load balancers not attached to the network are
+// ignored. The networks are the other networks of the project, used to detect
+// overlapping IP ranges.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewAllocator(
+	network *hcloud.Network,
+	servers []*hcloud.Server,
+	loadBalancers []*hcloud.LoadBalancer,
+	networks []*hcloud.Network,
+) (*Allocator, error) {
+	ipRange, err := prefixFromIPNet(network.IPRange)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip range of network %d: %w", network.ID, err)
+	}
+
+	a := &Allocator{
+		network: network,
+		ipRange: ipRange,
+		subnets: slices.Clone(network.Subnets),
+		used:    make(map[netip.Addr]struct{}),
+	}
+
+	for _, server := range servers {
+		for _, privateNet := range server.PrivateNet {
+			if privateNet.Network == nil || privateNet.Network.ID != network.ID {
+				continue
+			}
+			a.use(privateNet.IP)
+			for _, alias := range privateNet.Aliases {
+				a.use(alias)
+			}
+		}
+	}
+	for _, loadBalancer := range loadBalancers {
+		for _, privateNet := range loadBalancer.PrivateNet {
+			if privateNet.Network == nil || privateNet.Network.ID != network.ID {
+				continue
+			}
+			a.use(privateNet.IP)
+		}
+	}
+	for _, other := range networks {
+		if other.ID != network.ID {
+			a.networks = append(a.networks, other)
+		}
+	}
+
+	return a, nil
+}
+
+// Load fetches the servers, load balancers and networks of the project, and returns
+// a new [Allocator] for the network.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Load(ctx context.Context, client *hcloud.Client, network *hcloud.Network) (*Allocator, error) {
+	servers, err := client.Server.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	loadBalancers, err := client.LoadBalancer.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list load balancers: %w", err)
+	}
+	networks, err := client.Network.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list networks: %w", err)
+	}
+	return NewAllocator(network, servers, loadBalancers, networks)
+}
+
+func (a *Allocator) use(ip net.IP) {
+	if addr, ok := netip.AddrFromSlice(ip); ok {
+		a.used[addr.Unmap()] = struct{}{}
+	}
+}
+
+// NextSubnet returns the first free cloud subnet of the given prefix length in the IP
+// range of the network. The subnet is reserved, and may be added to the network with
+// [hcloud.NetworkClient.AddSubnet].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (a *Allocator) NextSubnet(zone hcloud.NetworkZone, prefixLen int) (hcloud.NetworkSubnet, error) {
+	if prefixLen < a.ipRange.Bits() || prefixLen > 30 {
+		return hcloud.NetworkSubnet{}, fmt.Errorf("invalid prefix length %d for ip range %s", prefixLen, a.ipRange)
+	}
+
+	used := make([]netip.Prefix, 0, len(a.subnets))
+	for _, subnet := range a.subnets {
+		if prefix, err := prefixFromIPNet(subnet.IPRange); err == nil {
+			used = append(used, prefix)
+		}
+	}
+
+	prefix, ok := nextFreePrefix(a.ipRange, prefixLen, used)
+	if !ok {
+		return hcloud.NetworkSubnet{}, fmt.Errorf("no /%d subnet available in ip range %s", prefixLen, a.ipRange)
+	}
+
+	subnet := hcloud.NetworkSubnet{
+		Type:        hcloud.NetworkSubnetTypeCloud,
+		IPRange:     ipNetFromPrefix(prefix),
+		NetworkZone: zone,
+	}
+	a.subnets = append(a.subnets, subnet)
+	return subnet, nil
+}
+
+// NextIP returns the first free host IP of the subnets of the network in the zone.
+// The IP is reserved, and may be used to attach a server or a load balancer to the
+// network.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (a *Allocator) NextIP(zone hcloud.NetworkZone) (net.IP, error) {
+	ips, err := a.NextIPs(zone, 1)
+	if err != nil {
+		return nil, err
+	}
+	return ips[0], nil
+}
+
+// NextIPs returns the first count free host IPs of the subnets of the network in the
+// zone, for example to be used as alias IPs. The IPs are reserved.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (a *Allocator) NextIPs(zone hcloud.NetworkZone, count int) ([]net.IP, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("invalid ip count %d", count)
+	}
+
+	result := make([]netip.Addr, 0, count)
+	reserve := func(addr netip.Addr) {
+		result = append(result, addr)
+		a.used[addr] = struct{}{}
+	}
+
+	for _, subnet := range a.subnets {
+		if len(result) == count {
+			break
+		}
+		if subnet.NetworkZone != zone || subnet.Type == hcloud.NetworkSubnetTypeVSwitch {
+			continue
+		}
+		prefix, err := prefixFromIPNet(subnet.IPRange)
+		if err != nil {
+			continue
+		}
+
+		reserved := a.reservedIPs(prefix, subnet.Gateway)
+		for addr := prefix.Addr().Next(); prefix.Contains(addr) && len(result) < count; addr = addr.Next() {
+			if _, ok := a.used[addr]; ok {
+				continue
+			}
+			if slices.Contains(reserved, addr) {
+				continue
+			}
+			reserve(addr)
+		}
+	}
+
+	if len(result) < count {
+		// Release the partial allocation.
+		for _, addr := range result {
+			delete(a.used, addr)
+		}
+		return nil, fmt.Errorf("no %d ips available in network %d zone %s", count, a.network.ID, zone)
+	}
+
+	ips := make([]net.IP, 0, len(result))
+	for _, addr := range result {
+		ips = append(ips, net.IP(addr.AsSlice()))
+	}
+	return ips, nil
+}
+
+// reservedIPs returns the IPs of the subnet that can not be assigned: the gateway of
+// the network, the first IP and gateway of the subnet, and the broadcast address.
+func (a *Allocator) reservedIPs(subnet netip.Prefix, gateway net.IP) []netip.Addr {
+	result := []netip.Addr{
+		a.ipRange.Addr().Next(),
+		subnet.Addr().Next(),
+		lastAddr(subnet),
+	}
+	if addr, ok := netip.AddrFromSlice(gateway); ok {
+		result = append(result, addr.Unmap())
+	}
+	return result
+}
+
+// Overlaps returns the other networks of the project whose IP range overlaps with the
+// given IP range.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (a *Allocator) Overlaps(ipRange *net.IPNet) []*hcloud.Network {
+	return OverlappingNetworks(ipRange, a.networks)
+}
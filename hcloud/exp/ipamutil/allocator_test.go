@@ -0,0 +1,159 @@
+package ipamutil
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func ipStrings(ips []net.IP) []string {
+	result := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		result = append(result, ip.String())
+	}
+	return result
+}
+
+func TestAllocator(t *testing.T) {
+	network := &hcloud.Network{
+		ID:      1,
+		IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+		Subnets: []hcloud.NetworkSubnet{
+			{Type: hcloud.NetworkSubnetTypeCloud, IPRange: mustParseCIDR(t, "10.0.0.0/24"), NetworkZone: hcloud.NetworkZoneEUCentral, Gateway: net.ParseIP("10.0.0.1")},
+			{Type: hcloud.NetworkSubnetTypeVSwitch, IPRange: mustParseCIDR(t, "10.0.2.0/24"), NetworkZone: hcloud.NetworkZoneEUCentral},
+		},
+	}
+	servers := []*hcloud.Server{
+		{ID: 1, PrivateNet: []hcloud.ServerPrivateNet{{
+			Network: &hcloud.Network{ID: 1},
+			IP:      net.ParseIP("10.0.0.2"),
+			Aliases: []net.IP{net.ParseIP("10.0.0.3"), net.ParseIP("10.0.0.5")},
+		}}},
+		{ID: 2, PrivateNet: []hcloud.ServerPrivateNet{{
+			Network: &hcloud.Network{ID: 2},
+			IP:      net.ParseIP("10.0.0.4"),
+		}}},
+	}
+	loadBalancers := []*hcloud.LoadBalancer{
+		{ID: 1, PrivateNet: []hcloud.LoadBalancerPrivateNet{{Network: &hcloud.Network{ID: 1}, IP: net.ParseIP("10.0.0.6")}}},
+	}
+	networks := []*hcloud.Network{
+		network,
+		{ID: 2, IPRange: mustParseCIDR(t, "10.1.0.0/16")},
+	}
+
+	allocator, err := NewAllocator(network, servers, loadBalancers, networks)
+	require.NoError(t, err)
+
+	t.Run("ips", func(t *testing.T) {
+		ip, err := allocator.NextIP(hcloud.NetworkZoneEUCentral)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.4", ip.String())
+
+		ips, err := allocator.NextIPs(hcloud.NetworkZoneEUCentral, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.7", "10.0.0.8"}, ipStrings(ips))
+
+		_, err = allocator.NextIP(hcloud.NetworkZoneUSEast)
+		assert.EqualError(t, err, "no 1 ips available in network 1 zone us-east")
+
+		// The remaining 246 hosts of the subnet, without the broadcast address.
+		_, err = allocator.NextIPs(hcloud.NetworkZoneEUCentral, 247)
+		require.EqualError(t, err, "no 247 ips available in network 1 zone eu-central")
+		ips, err = allocator.NextIPs(hcloud.NetworkZoneEUCentral, 246)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.0.254", ips[len(ips)-1].String())
+	})
+
+	t.Run("subnets", func(t *testing.T) {
+		subnet, err := allocator.NextSubnet(hcloud.NetworkZoneUSEast, 24)
+		require.NoError(t, err)
+		assert.Equal(t, hcloud.NetworkSubnetTypeCloud, subnet.Type)
+		assert.Equal(t, hcloud.NetworkZoneUSEast, subnet.NetworkZone)
+		assert.Equal(t, "10.0.1.0/24", subnet.IPRange.String())
+
+		subnet, err = allocator.NextSubnet(hcloud.NetworkZoneUSEast, 23)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.4.0/23", subnet.IPRange.String())
+
+		subnet, err = allocator.NextSubnet(hcloud.NetworkZoneUSEast, 24)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.3.0/24", subnet.IPRange.String())
+
+		// The allocated subnet is used to allocate IPs.
+		ip, err := allocator.NextIP(hcloud.NetworkZoneUSEast)
+		require.NoError(t, err)
+		assert.Equal(t, "10.0.1.2", ip.String())
+
+		_, err = allocator.NextSubnet(hcloud.NetworkZoneUSEast, 15)
+		assert.EqualError(t, err, "invalid prefix length 15 for ip range 10.0.0.0/16")
+	})
+
+	t.Run("overlaps", func(t *testing.T) {
+		assert.Equal(t, []*hcloud.Network{networks[1]}, allocator.Overlaps(mustParseCIDR(t, "10.0.0.0/8")))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	network, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+		Name:    "network",
+		IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+		Subnets: []hcloud.NetworkSubnet{{Type: hcloud.NetworkSubnetTypeCloud, IPRange: mustParseCIDR(t, "10.0.1.0/24"), NetworkZone: hcloud.NetworkZoneEUCentral}},
+	})
+	require.NoError(t, err)
+
+	result, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "web",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+		Networks:   []*hcloud.Network{network},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+	webServer, _, err := client.Server.GetByID(ctx, result.Server.ID)
+	require.NoError(t, err)
+	require.Len(t, webServer.PrivateNet, 1)
+
+	allocator, err := Load(ctx, client, network)
+	require.NoError(t, err)
+
+	ip, err := allocator.NextIP(hcloud.NetworkZoneEUCentral)
+	require.NoError(t, err)
+	assert.NotEqual(t, webServer.PrivateNet[0].IP.String(), ip.String())
+
+	aliasIPs, err := allocator.NextIPs(hcloud.NetworkZoneEUCentral, 2)
+	require.NoError(t, err)
+
+	// The allocated IPs are accepted by the API.
+	action, _, err := client.Server.ChangeAliasIPs(ctx, webServer, hcloud.ServerChangeAliasIPsOpts{
+		Network:  network,
+		AliasIPs: aliasIPs,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	result, _, err = client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "db",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+	action, _, err = client.Server.AttachToNetwork(ctx, result.Server, hcloud.ServerAttachToNetworkOpts{
+		Network: network,
+		IP:      ip,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+}
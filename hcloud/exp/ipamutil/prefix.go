@@ -0,0 +1,123 @@
+package ipamutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// OverlappingNetworks returns the networks whose IP range overlaps with the given IP
+// range.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func OverlappingNetworks(ipRange *net.IPNet, networks []*hcloud.Network) []*hcloud.Network {
+	prefix, err := prefixFromIPNet(ipRange)
+	if err != nil {
+		return nil
+	}
+
+	var result []*hcloud.Network
+	for _, network := range networks {
+		other, err := prefixFromIPNet(network.IPRange)
+		if err != nil {
+			continue
+		}
+		if prefix.Overlaps(other) {
+			result = append(result, network)
+		}
+	}
+	return result
+}
+
+// NextNetworkRange returns the first IP range of the given prefix length in within,
+// that does not overlap with the IP range of the networks. For example to create a
+// new network in 10.0.0.0/8 that does not overlap with the existing networks.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NextNetworkRange(within *net.IPNet, prefixLen int, networks []*hcloud.Network) (*net.IPNet, error) {
+	withinPrefix, err := prefixFromIPNet(within)
+	if err != nil {
+		return nil, err
+	}
+	if prefixLen < withinPrefix.Bits() || prefixLen > 32 {
+		return nil, fmt.Errorf("invalid prefix length %d for ip range %s", prefixLen, withinPrefix)
+	}
+
+	used := make([]netip.Prefix, 0, len(networks))
+	for _, network := range networks {
+		if prefix, err := prefixFromIPNet(network.IPRange); err == nil {
+			used = append(used, prefix)
+		}
+	}
+
+	prefix, ok := nextFreePrefix(withinPrefix, prefixLen, used)
+	if !ok {
+		return nil, fmt.Errorf("no /%d ip range available in %s", prefixLen, withinPrefix)
+	}
+	return ipNetFromPrefix(prefix), nil
+}
+
+// nextFreePrefix returns the first prefix of the given length in within that does
+// not overlap with the used prefixes.
+func nextFreePrefix(within netip.Prefix, bits int, used []netip.Prefix) (netip.Prefix, bool) {
+	size := uint64(1) << (32 - bits)
+	start := uint64(addrToUint32(within.Addr()))
+	end := start + uint64(1)<<(32-within.Bits())
+
+	for candidate := start; candidate+size <= end; {
+		prefix := netip.PrefixFrom(uint32ToAddr(uint32(candidate)), bits)
+		idx := slices.IndexFunc(used, prefix.Overlaps)
+		if idx < 0 {
+			return prefix, true
+		}
+		// Skip to the first aligned candidate after the overlapping prefix.
+		usedEnd := uint64(addrToUint32(lastAddr(used[idx]))) + 1
+		candidate = max(candidate+size, (usedEnd+size-1)/size*size)
+	}
+	return netip.Prefix{}, false
+}
+
+// prefixFromIPNet converts an IPv4 network to a masked prefix.
+func prefixFromIPNet(ipNet *net.IPNet) (netip.Prefix, error) {
+	if ipNet == nil {
+		return netip.Prefix{}, errors.New("missing ip range")
+	}
+	addr, ok := netip.AddrFromSlice(ipNet.IP)
+	if !ok || !addr.Unmap().Is4() {
+		return netip.Prefix{}, fmt.Errorf("unsupported ip range %s: only IPv4 is supported", ipNet)
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		return netip.Prefix{}, fmt.Errorf("unsupported ip range %s: only IPv4 is supported", ipNet)
+	}
+	return netip.PrefixFrom(addr.Unmap(), ones).Masked(), nil
+}
+
+func ipNetFromPrefix(prefix netip.Prefix) *net.IPNet {
+	return &net.IPNet{
+		IP:   net.IP(prefix.Addr().AsSlice()),
+		Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+	}
+}
+
+// lastAddr returns the last address of an IPv4 prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	hostBits := uint32(1)<<(32-prefix.Bits()) - 1
+	return uint32ToAddr(addrToUint32(prefix.Masked().Addr()) | hostBits)
+}
+
+func addrToUint32(addr netip.Addr) uint32 {
+	b := addr.As4()
+	return binary.BigEndian.Uint32(b[:])
+}
+
+func uint32ToAddr(v uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return netip.AddrFrom4(b)
+}
@@ -0,0 +1,55 @@
+package ipamutil
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func mustParseCIDR(t *testing.T, s string) *net.IPNet {
+	t.Helper()
+	_, ipNet, err := net.ParseCIDR(s)
+	require.NoError(t, err)
+	return ipNet
+}
+
+func TestOverlappingNetworks(t *testing.T) {
+	networks := []*hcloud.Network{
+		{ID: 1, IPRange: mustParseCIDR(t, "10.0.0.0/16")},
+		{ID: 2, IPRange: mustParseCIDR(t, "10.1.0.0/16")},
+		{ID: 3, IPRange: mustParseCIDR(t, "192.168.0.0/24")},
+	}
+
+	assert.Equal(t, []*hcloud.Network{networks[0], networks[1]}, OverlappingNetworks(mustParseCIDR(t, "10.0.0.0/15"), networks))
+	assert.Equal(t, []*hcloud.Network{networks[2]}, OverlappingNetworks(mustParseCIDR(t, "192.168.0.128/25"), networks))
+	assert.Empty(t, OverlappingNetworks(mustParseCIDR(t, "172.16.0.0/12"), networks))
+}
+
+func TestNextNetworkRange(t *testing.T) {
+	networks := []*hcloud.Network{
+		{ID: 1, IPRange: mustParseCIDR(t, "10.0.0.0/16")},
+		{ID: 2, IPRange: mustParseCIDR(t, "10.1.128.0/17")},
+		{ID: 3, IPRange: mustParseCIDR(t, "10.3.0.0/16")},
+	}
+
+	ipRange, err := NextNetworkRange(mustParseCIDR(t, "10.0.0.0/8"), 16, networks)
+	require.NoError(t, err)
+	assert.Equal(t, "10.2.0.0/16", ipRange.String())
+
+	ipRange, err = NextNetworkRange(mustParseCIDR(t, "10.0.0.0/8"), 17, networks)
+	require.NoError(t, err)
+	assert.Equal(t, "10.1.0.0/17", ipRange.String())
+
+	_, err = NextNetworkRange(mustParseCIDR(t, "10.0.0.0/15"), 16, networks)
+	assert.EqualError(t, err, "no /16 ip range available in 10.0.0.0/15")
+
+	_, err = NextNetworkRange(mustParseCIDR(t, "10.0.0.0/16"), 8, networks)
+	assert.EqualError(t, err, "invalid prefix length 8 for ip range 10.0.0.0/16")
+
+	_, err = NextNetworkRange(mustParseCIDR(t, "fd00::/8"), 16, networks)
+	assert.EqualError(t, err, "unsupported ip range fd00::/8: only IPv4 is supported")
+}
@@ -0,0 +1,310 @@
+package labelutil
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Operator is the operator of a label selector [Requirement].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Operator string
+
+const (
+	// OperatorEquals selects resources having the label key set to the value (`k=v`
+	// or `k==v`).
+	OperatorEquals Operator = "="
+	// OperatorNotEquals selects resources not having the label key set to the value
+	// (`k!=v`), including the resources without the label key.
+	OperatorNotEquals Operator = "!="
+	// OperatorIn selects resources having the label key set to one of the values
+	// (`k in (v1,v2)`).
+	OperatorIn Operator = "in"
+	// OperatorNotIn selects resources not having the label key set to one of the
+	// values (`k notin (v1,v2)`), including the resources without the label key.
+	OperatorNotIn Operator = "notin"
+	// OperatorExists selects resources having the label key (`k`).
+	OperatorExists Operator = "exists"
+	// OperatorNotExists selects resources not having the label key (`!k`).
+	OperatorNotExists Operator = "!"
+)
+
+// Requirement is a single condition of a label selector.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	// Values holds a single value for [OperatorEquals] and [OperatorNotEquals], the
+	// sorted set of values for [OperatorIn] and [OperatorNotIn], and is empty
+	// otherwise.
+	Values []string
+}
+
+// String returns the canonical representation of the requirement.
+func (r Requirement) String() string {
+	switch r.Operator {
+	case OperatorEquals, OperatorNotEquals:
+		value := ""
+		if len(r.Values) > 0 {
+			value = r.Values[0]
+		}
+		return r.Key + string(r.Operator) + value
+	case OperatorIn, OperatorNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ","))
+	case OperatorExists:
+		return r.Key
+	case OperatorNotExists:
+		return "!" + r.Key
+	default:
+		return ""
+	}
+}
+
+// Matches reports whether the labels satisfy the requirement.
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+
+	switch r.Operator {
+	case OperatorEquals:
+		return ok && len(r.Values) > 0 && value == r.Values[0]
+	case OperatorNotEquals:
+		return !ok || len(r.Values) == 0 || value != r.Values[0]
+	case OperatorIn:
+		return ok && slices.Contains(r.Values, value)
+	case OperatorNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case OperatorExists:
+		return ok
+	case OperatorNotExists:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// Requirements is a parsed label selector, all the requirements must be satisfied
+// for a resource to be selected. An empty Requirements selects every resource.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Requirements []Requirement
+
+// String returns the canonical representation of the label selector, the
+// requirements are sorted.
+func (r Requirements) String() string {
+	parts := make([]string, 0, len(r))
+	for _, requirement := range r {
+		parts = append(parts, requirement.String())
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ",")
+}
+
+// Matches reports whether the labels satisfy all the requirements.
+func (r Requirements) Matches(labels map[string]string) bool {
+	for _, requirement := range r {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseSelector parses a [label selector](https://docs.hetzner.cloud/reference/cloud#label-selector)
+// into its requirements. The label keys and values are validated using
+// [hcloud.ValidateResourceLabels].
+//
+//	requirements, err := labelutil.ParseSelector("env in (prod,staging),!deprecated")
+//	if requirements.Matches(server.Labels) { ... }
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ParseSelector(selector string) (Requirements, error) {
+	p := &selectorParser{input: selector}
+
+	requirements, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+	return requirements, nil
+}
+
+// Filter returns the items whose labels, as returned by labelsFunc, satisfy the
+// requirements. This predicts which resources a label selector (e.g.
+// [hcloud.LoadBalancerTargetLabelSelector]) matches:
+//
+//	targets := labelutil.Filter(servers, requirements, func(s *hcloud.Server) map[string]string { return s.Labels })
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Filter[T any](items []T, requirements Requirements, labelsFunc func(T) map[string]string) []T {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		if requirements.Matches(labelsFunc(item)) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) parse() (Requirements, error) {
+	requirements := Requirements{}
+
+	p.skipSpaces()
+	if p.eof() {
+		return requirements, nil
+	}
+
+	for {
+		requirement, err := p.parseRequirement()
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+
+		p.skipSpaces()
+		if p.eof() {
+			return requirements, nil
+		}
+		if !p.consume(",") {
+			return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos)
+		}
+	}
+}
+
+func (p *selectorParser) parseRequirement() (Requirement, error) {
+	p.skipSpaces()
+
+	if p.consume("!") {
+		p.skipSpaces()
+		key, err := p.parseKey()
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: OperatorNotExists}, nil
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return Requirement{}, err
+	}
+
+	p.skipSpaces()
+	switch {
+	case p.eof() || p.peek(","):
+		return Requirement{Key: key, Operator: OperatorExists}, nil
+
+	case p.consume("=="), p.consume("="):
+		value, err := p.parseValue(key)
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: OperatorEquals, Values: []string{value}}, nil
+
+	case p.consume("!="):
+		value, err := p.parseValue(key)
+		if err != nil {
+			return Requirement{}, err
+		}
+		return Requirement{Key: key, Operator: OperatorNotEquals, Values: []string{value}}, nil
+	}
+
+	start := p.pos
+	operator := Operator(p.parseWord())
+	if operator != OperatorIn && operator != OperatorNotIn {
+		return Requirement{}, fmt.Errorf("unknown operator %q at position %d", operator, start)
+	}
+
+	values, err := p.parseValueSet(key)
+	if err != nil {
+		return Requirement{}, err
+	}
+	return Requirement{Key: key, Operator: operator, Values: values}, nil
+}
+
+func (p *selectorParser) parseKey() (string, error) {
+	start := p.pos
+	key := p.parseWord()
+	if key == "" {
+		return "", fmt.Errorf("missing label key at position %d", start)
+	}
+	if _, err := hcloud.ValidateResourceLabels(map[string]any{key: ""}); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (p *selectorParser) parseValue(key string) (string, error) {
+	p.skipSpaces()
+	value := p.parseWord()
+	if _, err := hcloud.ValidateResourceLabels(map[string]any{key: value}); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+func (p *selectorParser) parseValueSet(key string) ([]string, error) {
+	p.skipSpaces()
+	if !p.consume("(") {
+		return nil, fmt.Errorf("missing '(' at position %d", p.pos)
+	}
+
+	values := []string{}
+	for {
+		value, err := p.parseValue(key)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+
+		p.skipSpaces()
+		if p.consume(")") {
+			break
+		}
+		if !p.consume(",") {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos)
+		}
+	}
+
+	slices.Sort(values)
+	return values, nil
+}
+
+// parseWord reads the characters until a whitespace, an operator or a separator.
+func (p *selectorParser) parseWord() string {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(" \t\n,=!()", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *selectorParser) skipSpaces() {
+	for !p.eof() && strings.ContainsRune(" \t\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *selectorParser) peek(s string) bool {
+	return strings.HasPrefix(p.input[p.pos:], s)
+}
+
+func (p *selectorParser) consume(s string) bool {
+	if p.peek(s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.input)
+}
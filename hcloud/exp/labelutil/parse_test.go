@@ -0,0 +1,141 @@
+package labelutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector  string
+		want      Requirements
+		canonical string
+	}{
+		{
+			selector:  "",
+			want:      Requirements{},
+			canonical: "",
+		},
+		{
+			selector:  "env=prod",
+			want:      Requirements{{Key: "env", Operator: OperatorEquals, Values: []string{"prod"}}},
+			canonical: "env=prod",
+		},
+		{
+			selector:  " env == prod ",
+			want:      Requirements{{Key: "env", Operator: OperatorEquals, Values: []string{"prod"}}},
+			canonical: "env=prod",
+		},
+		{
+			selector:  "env=",
+			want:      Requirements{{Key: "env", Operator: OperatorEquals, Values: []string{""}}},
+			canonical: "env=",
+		},
+		{
+			selector: "example.com/tier!=db,env in (staging, prod,prod),role notin(lb),managed,!deprecated",
+			want: Requirements{
+				{Key: "example.com/tier", Operator: OperatorNotEquals, Values: []string{"db"}},
+				{Key: "env", Operator: OperatorIn, Values: []string{"prod", "staging"}},
+				{Key: "role", Operator: OperatorNotIn, Values: []string{"lb"}},
+				{Key: "managed", Operator: OperatorExists},
+				{Key: "deprecated", Operator: OperatorNotExists},
+			},
+			canonical: "!deprecated,env in (prod,staging),example.com/tier!=db,managed,role notin (lb)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.canonical, got.String())
+
+			// The canonical form is stable
+			reparsed, err := ParseSelector(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, tt.canonical, reparsed.String())
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		selector string
+		err      string
+	}{
+		{selector: ",", err: `invalid label selector ",": missing label key at position 0`},
+		{selector: "env=prod,", err: `invalid label selector "env=prod,": missing label key at position 9`},
+		{selector: "env prod", err: `invalid label selector "env prod": unknown operator "prod" at position 4`},
+		{selector: "env in prod", err: `invalid label selector "env in prod": missing '(' at position 7`},
+		{selector: "env in (prod", err: `invalid label selector "env in (prod": missing ')' at position 12`},
+		{selector: "env=prod)", err: `invalid label selector "env=prod)": unexpected ')' at position 8`},
+		{selector: "-env=prod", err: `invalid label selector "-env=prod": label key '-env' is not correctly formatted`},
+		{selector: "env=prod-", err: `invalid label selector "env=prod-": label value 'prod-' (key: env) is not correctly formatted`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			_, err := ParseSelector(tt.selector)
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRequirementsMatches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "role": "web", "managed": ""}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=prod", want: true},
+		{selector: "env=staging", want: false},
+		{selector: "env!=staging", want: true},
+		{selector: "tier!=db", want: true},
+		{selector: "env in (prod,staging)", want: true},
+		{selector: "tier in (db)", want: false},
+		{selector: "role notin (lb,db)", want: true},
+		{selector: "role notin (web)", want: false},
+		{selector: "tier notin (db)", want: true},
+		{selector: "managed", want: true},
+		{selector: "!managed", want: false},
+		{selector: "!tier", want: true},
+		{selector: "managed=", want: true},
+		{selector: "env=prod,role=lb", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			requirements, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, requirements.Matches(labels))
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	servers := []*hcloud.Server{
+		{ID: 1, Labels: map[string]string{"env": "prod", "role": "web"}},
+		{ID: 2, Labels: map[string]string{"env": "prod", "role": "db"}},
+		{ID: 3, Labels: map[string]string{"env": "staging", "role": "web"}},
+		{ID: 4},
+	}
+
+	requirements, err := ParseSelector("env=prod,role!=db")
+	require.NoError(t, err)
+
+	got := Filter(servers, requirements, func(s *hcloud.Server) map[string]string { return s.Labels })
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(1), got[0].ID)
+
+	// Round trip with the equality selector builder
+	requirements, err = ParseSelector(Selector(map[string]string{"env": "staging", "role": "web"}))
+	require.NoError(t, err)
+
+	got = Filter(servers, requirements, func(s *hcloud.Server) map[string]string { return s.Labels })
+	require.Len(t, got, 1)
+	assert.Equal(t, int64(3), got[0].ID)
+}
@@ -0,0 +1,164 @@
+package loadbalancerutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// rollbackTimeout bounds the rollback of a failed plan, which is not canceled with the
+// context of [Apply].
+const rollbackTimeout = 10 * time.Minute
+
+// ReconcileOpts specifies options for [Reconcile].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type ReconcileOpts struct {
+	// DryRun only computes the plan, without applying it.
+	DryRun bool
+}
+
+// Reconcile fetches the current state of the Load Balancer, computes the [Plan] to
+// converge it to the spec, and applies the plan unless [ReconcileOpts.DryRun] is set.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Reconcile(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, spec Spec, opts ReconcileOpts) (Plan, error) {
+	if err := spec.validate(); err != nil {
+		return Plan{}, err
+	}
+
+	current, _, err := client.LoadBalancer.GetByID(ctx, loadBalancer.ID)
+	if err != nil {
+		return Plan{}, err
+	}
+	if current == nil {
+		return Plan{}, fmt.Errorf("load balancer not found: %d", loadBalancer.ID)
+	}
+
+	plan := Diff(current, spec)
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	return plan, Apply(ctx, client, plan)
+}
+
+// Apply applies the operations of the plan in order using the Load Balancer client,
+// and waits for the action of each operation to complete, as the Load Balancer is
+// locked during an action.
+//
+// When an operation fails, the operations already applied are rolled back in reverse
+// order, and the returned error holds the error of the failed operation, joined with
+// the errors of the rollback. The rollback is done even if the context is done, and
+// is bounded by its own timeout.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Apply(ctx context.Context, client *hcloud.Client, plan Plan) error {
+	for i, op := range plan.Operations {
+		if err := applyOperation(ctx, client, plan.LoadBalancer, op); err != nil {
+			err = fmt.Errorf("could not %s: %w", op, err)
+
+			rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+			defer cancel()
+			return errors.Join(err, rollback(rollbackCtx, client, plan.LoadBalancer, plan.Operations[:i]))
+		}
+	}
+	return nil
+}
+
+// rollback reverts the applied operations in reverse order. All operations are
+// attempted, even if some of them fail.
+func rollback(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, applied []*Operation) error {
+	errs := []error{}
+	for i := len(applied) - 1; i >= 0; i-- {
+		op := applied[i]
+		if op.rollback == nil {
+			continue
+		}
+		if err := applyOperation(ctx, client, loadBalancer, op.rollback); err != nil {
+			errs = append(errs, fmt.Errorf("could not roll back %s: %w", op, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func applyOperation(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, op *Operation) error {
+	var action *hcloud.Action
+	var err error
+
+	switch op.Type {
+	case OperationChangeType:
+		action, _, err = client.LoadBalancer.ChangeType(ctx, loadBalancer, hcloud.LoadBalancerChangeTypeOpts{
+			LoadBalancerType: op.LoadBalancerType,
+		})
+	case OperationChangeAlgorithm:
+		action, _, err = client.LoadBalancer.ChangeAlgorithm(ctx, loadBalancer, hcloud.LoadBalancerChangeAlgorithmOpts{
+			Type: op.Algorithm,
+		})
+	case OperationAttachToNetwork:
+		action, _, err = client.LoadBalancer.AttachToNetwork(ctx, loadBalancer, hcloud.LoadBalancerAttachToNetworkOpts{
+			Network: op.Network,
+			IP:      op.IP,
+		})
+	case OperationDetachFromNetwork:
+		action, _, err = client.LoadBalancer.DetachFromNetwork(ctx, loadBalancer, hcloud.LoadBalancerDetachFromNetworkOpts{
+			Network: op.Network,
+		})
+	case OperationEnablePublicInterface:
+		action, _, err = client.LoadBalancer.EnablePublicInterface(ctx, loadBalancer)
+	case OperationDisablePublicInterface:
+		action, _, err = client.LoadBalancer.DisablePublicInterface(ctx, loadBalancer)
+	case OperationDeleteService:
+		action, _, err = client.LoadBalancer.DeleteService(ctx, loadBalancer, op.ListenPort)
+	case OperationUpdateService:
+		action, _, err = client.LoadBalancer.UpdateService(ctx, loadBalancer, op.ListenPort, updateServiceOpts(op.Service))
+	case OperationAddService:
+		action, _, err = client.LoadBalancer.AddService(ctx, loadBalancer, addServiceOpts(op.Service))
+	case OperationRemoveTarget:
+		action, _, err = removeTarget(ctx, client, loadBalancer, op.Target)
+	case OperationAddTarget:
+		action, _, err = addTarget(ctx, client, loadBalancer, op.Target)
+	default:
+		return fmt.Errorf("unknown operation type: %s", op.Type)
+	}
+	if err != nil {
+		return err
+	}
+	return client.Action.WaitFor(ctx, action)
+}
+
+func addTarget(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, target hcloud.LoadBalancerCreateOptsTarget) (*hcloud.Action, *hcloud.Response, error) {
+	switch target.Type {
+	case hcloud.LoadBalancerTargetTypeServer:
+		return client.LoadBalancer.AddServerTarget(ctx, loadBalancer, hcloud.LoadBalancerAddServerTargetOpts{
+			Server:       target.Server.Server,
+			UsePrivateIP: target.UsePrivateIP,
+		})
+	case hcloud.LoadBalancerTargetTypeLabelSelector:
+		return client.LoadBalancer.AddLabelSelectorTarget(ctx, loadBalancer, hcloud.LoadBalancerAddLabelSelectorTargetOpts{
+			Selector:     target.LabelSelector.Selector,
+			UsePrivateIP: target.UsePrivateIP,
+		})
+	case hcloud.LoadBalancerTargetTypeIP:
+		return client.LoadBalancer.AddIPTarget(ctx, loadBalancer, hcloud.LoadBalancerAddIPTargetOpts{
+			IP: net.ParseIP(target.IP.IP),
+		})
+	}
+	return nil, nil, fmt.Errorf("unknown target type: %s", target.Type)
+}
+
+func removeTarget(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, target hcloud.LoadBalancerCreateOptsTarget) (*hcloud.Action, *hcloud.Response, error) {
+	switch target.Type {
+	case hcloud.LoadBalancerTargetTypeServer:
+		return client.LoadBalancer.RemoveServerTarget(ctx, loadBalancer, target.Server.Server)
+	case hcloud.LoadBalancerTargetTypeLabelSelector:
+		return client.LoadBalancer.RemoveLabelSelectorTarget(ctx, loadBalancer, target.LabelSelector.Selector)
+	case hcloud.LoadBalancerTargetTypeIP:
+		return client.LoadBalancer.RemoveIPTarget(ctx, loadBalancer, net.ParseIP(target.IP.IP))
+	}
+	return nil, nil, fmt.Errorf("unknown target type: %s", target.Type)
+}
@@ -0,0 +1,175 @@
+package loadbalancerutil
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func createLoadBalancer(t *testing.T, client *hcloud.Client) *hcloud.LoadBalancer {
+	t.Helper()
+	ctx := context.Background()
+
+	result, _, err := client.LoadBalancer.Create(ctx, hcloud.LoadBalancerCreateOpts{
+		Name:             "lb",
+		LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb11"},
+		Location:         &hcloud.Location{Name: "fsn1"},
+		Services: []hcloud.LoadBalancerCreateOptsService{
+			{Protocol: hcloud.LoadBalancerServiceProtocolHTTP},
+			{Protocol: hcloud.LoadBalancerServiceProtocolTCP, ListenPort: hcloud.Ptr(22), DestinationPort: hcloud.Ptr(22)},
+		},
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			{Type: hcloud.LoadBalancerTargetTypeLabelSelector, LabelSelector: hcloud.LoadBalancerCreateOptsTargetLabelSelector{Selector: "role=web"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+	return result.LoadBalancer
+}
+
+func TestReconcile(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	loadBalancer := createLoadBalancer(t, client)
+
+	spec := SpecFromCreateOpts(hcloud.LoadBalancerCreateOpts{
+		LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb21"},
+		Algorithm:        &hcloud.LoadBalancerAlgorithm{Type: hcloud.LoadBalancerAlgorithmTypeLeastConnections},
+		Services: []hcloud.LoadBalancerCreateOptsService{
+			{
+				Protocol:        hcloud.LoadBalancerServiceProtocolHTTP,
+				DestinationPort: hcloud.Ptr(8080),
+			},
+			{
+				Protocol:        hcloud.LoadBalancerServiceProtocolTCP,
+				ListenPort:      hcloud.Ptr(5432),
+				DestinationPort: hcloud.Ptr(5432),
+			},
+		},
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			{Type: hcloud.LoadBalancerTargetTypeIP, IP: hcloud.LoadBalancerCreateOptsTargetIP{IP: "203.0.113.1"}},
+		},
+	})
+
+	plan, err := Reconcile(ctx, client, loadBalancer, spec, ReconcileOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []OperationType{
+		OperationChangeType,
+		OperationChangeAlgorithm,
+		OperationDeleteService,
+		OperationUpdateService,
+		OperationAddService,
+		OperationRemoveTarget,
+		OperationAddTarget,
+	}, operationTypes(plan))
+
+	plan, err = Reconcile(ctx, client, loadBalancer, spec, ReconcileOpts{})
+	require.NoError(t, err)
+	assert.False(t, plan.Empty())
+
+	loadBalancer, _, err = client.LoadBalancer.GetByID(ctx, loadBalancer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "lb21", loadBalancer.LoadBalancerType.Name)
+	assert.Equal(t, hcloud.LoadBalancerAlgorithmTypeLeastConnections, loadBalancer.Algorithm.Type)
+	require.Len(t, loadBalancer.Services, 2)
+	assert.ElementsMatch(t, []int{80, 5432}, []int{loadBalancer.Services[0].ListenPort, loadBalancer.Services[1].ListenPort})
+	require.Len(t, loadBalancer.Targets, 1)
+	assert.Equal(t, "203.0.113.1", loadBalancer.Targets[0].IP.IP)
+
+	plan, err = Reconcile(ctx, client, loadBalancer, spec, ReconcileOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+
+	_, err = Reconcile(ctx, client, &hcloud.LoadBalancer{ID: 42}, spec, ReconcileOpts{})
+	require.EqualError(t, err, "load balancer not found: 42")
+
+	_, err = Reconcile(ctx, client, loadBalancer, Spec{
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			{Type: hcloud.LoadBalancerTargetTypeServer, Server: hcloud.LoadBalancerCreateOptsTargetServer{Server: &hcloud.Server{Name: "web"}}},
+		},
+	}, ReconcileOpts{DryRun: true})
+	require.EqualError(t, err, "server targets must reference the server by ID")
+}
+
+func TestApplyRollback(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	loadBalancer := createLoadBalancer(t, client)
+
+	spec := Spec{
+		Algorithm: &hcloud.LoadBalancerAlgorithm{Type: hcloud.LoadBalancerAlgorithmTypeLeastConnections},
+		Services: []hcloud.LoadBalancerCreateOptsService{
+			{Protocol: hcloud.LoadBalancerServiceProtocolHTTP},
+		},
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			// The server does not exist, adding the target fails.
+			{Type: hcloud.LoadBalancerTargetTypeServer, Server: hcloud.LoadBalancerCreateOptsTargetServer{Server: &hcloud.Server{ID: 42}}},
+		},
+	}
+
+	plan, err := Reconcile(ctx, client, loadBalancer, spec, ReconcileOpts{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "could not add target server 42")
+	assert.Equal(t, []OperationType{
+		OperationChangeAlgorithm,
+		OperationDeleteService,
+		OperationRemoveTarget,
+		OperationAddTarget,
+	}, operationTypes(plan))
+
+	loadBalancer, _, err = client.LoadBalancer.GetByID(ctx, loadBalancer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.LoadBalancerAlgorithmTypeRoundRobin, loadBalancer.Algorithm.Type)
+	require.Len(t, loadBalancer.Services, 2)
+	assert.ElementsMatch(t, []int{80, 22}, []int{loadBalancer.Services[0].ListenPort, loadBalancer.Services[1].ListenPort})
+	require.Len(t, loadBalancer.Targets, 1)
+	assert.Equal(t, "role=web", loadBalancer.Targets[0].LabelSelector.Selector)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestApplyRollbackCanceled(t *testing.T) {
+	server := fakeapi.NewServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The context is canceled when the target is added, the rollback must still run.
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/actions/add_target") && ctx.Err() == nil {
+			cancel()
+			return nil, context.Canceled
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client := server.Client(hcloud.WithHTTPClient(&http.Client{Transport: transport}))
+
+	loadBalancer := createLoadBalancer(t, client)
+
+	_, err := Reconcile(ctx, client, loadBalancer, Spec{
+		Algorithm: &hcloud.LoadBalancerAlgorithm{Type: hcloud.LoadBalancerAlgorithmTypeLeastConnections},
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			{Type: hcloud.LoadBalancerTargetTypeIP, IP: hcloud.LoadBalancerCreateOptsTargetIP{IP: "203.0.113.1"}},
+		},
+	}, ReconcileOpts{})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.NotContains(t, err.Error(), "could not roll back")
+
+	loadBalancer, _, err = client.LoadBalancer.GetByID(context.Background(), loadBalancer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.LoadBalancerAlgorithmTypeRoundRobin, loadBalancer.Algorithm.Type)
+	require.Len(t, loadBalancer.Targets, 1)
+	assert.Equal(t, "role=web", loadBalancer.Targets[0].LabelSelector.Selector)
+}
@@ -0,0 +1,294 @@
+package loadbalancerutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// TargetHealth is the health status of a target for a service of a Load Balancer.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type TargetHealth struct {
+	// Target is a server or IP target, the label selector targets are expanded to the
+	// server targets they match.
+	Target hcloud.LoadBalancerTarget
+	// LabelSelector is the selector of the label selector target the target was
+	// expanded from, empty otherwise.
+	LabelSelector string
+	ListenPort    int
+	Status        hcloud.LoadBalancerTargetHealthStatusStatus
+}
+
+func (h TargetHealth) String() string {
+	name := string(h.Target.Type)
+	switch {
+	case h.Target.Server != nil && h.Target.Server.Server != nil:
+		name = fmt.Sprintf("server %d", h.Target.Server.Server.ID)
+	case h.Target.IP != nil:
+		name = "ip " + h.Target.IP.IP
+	}
+	if h.LabelSelector != "" {
+		name += " (label_selector " + h.LabelSelector + ")"
+	}
+	return fmt.Sprintf("%s port %d", name, h.ListenPort)
+}
+
+// HealthReport is the health status of the targets of a Load Balancer, for every
+// service.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type HealthReport struct {
+	LoadBalancer *hcloud.LoadBalancer
+	Targets      []TargetHealth
+}
+
+// Healthy reports whether there is at least one target, and all targets are healthy
+// for every service.
+func (r HealthReport) Healthy() bool {
+	return len(r.Targets) > 0 && len(r.Unhealthy()) == 0
+}
+
+// Unhealthy returns the targets not reported healthy, including the targets whose
+// health status is unknown.
+func (r HealthReport) Unhealthy() []TargetHealth {
+	var result []TargetHealth
+	for _, target := range r.Targets {
+		if target.Status != hcloud.LoadBalancerTargetHealthStatusStatusHealthy {
+			result = append(result, target)
+		}
+	}
+	return result
+}
+
+// Health returns the [HealthReport] of the targets of the Load Balancer.
+//
+// When the selector is not empty, only the targets expanded from the label selector
+// target with the same selector are reported, for example to wait for the servers of
+// a new deployment. A target without health status for a service is reported with the
+// [hcloud.LoadBalancerTargetHealthStatusStatusUnknown] status.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Health(loadBalancer *hcloud.LoadBalancer, selector string) HealthReport {
+	report := HealthReport{LoadBalancer: loadBalancer}
+
+	add := func(target hcloud.LoadBalancerTarget, labelSelector string) {
+		for _, service := range loadBalancer.Services {
+			status := hcloud.LoadBalancerTargetHealthStatusStatusUnknown
+			for _, health := range target.HealthStatus {
+				if health.ListenPort == service.ListenPort {
+					status = health.Status
+					break
+				}
+			}
+			report.Targets = append(report.Targets, TargetHealth{
+				Target:        target,
+				LabelSelector: labelSelector,
+				ListenPort:    service.ListenPort,
+				Status:        status,
+			})
+		}
+	}
+
+	for _, target := range loadBalancer.Targets {
+		if target.Type == hcloud.LoadBalancerTargetTypeLabelSelector {
+			if target.LabelSelector == nil || (selector != "" && target.LabelSelector.Selector != selector) {
+				continue
+			}
+			for _, expanded := range target.Targets {
+				add(expanded, target.LabelSelector.Selector)
+			}
+			continue
+		}
+		if selector == "" {
+			add(target, "")
+		}
+	}
+	return report
+}
+
+// HealthOpts defines the options of [WaitForTargetsHealthy] and [WatchTargetsHealth].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type HealthOpts struct {
+	// BackoffFunc is used between the polls of the Load Balancer, defaults to a
+	// constant backoff of one second. In [WatchTargetsHealth], it is given the number
+	// of consecutive failed polls, so the delay is reset after a successful poll.
+	BackoffFunc hcloud.BackoffFunc
+	// Timeout is the maximum duration to wait for the targets to be healthy, no
+	// timeout is applied besides the deadline of the context if zero.
+	Timeout time.Duration
+}
+
+func (o HealthOpts) backoff(retries int) time.Duration {
+	if o.BackoffFunc == nil {
+		return time.Second
+	}
+	return o.BackoffFunc(retries)
+}
+
+// UnhealthyError is returned by [WaitForTargetsHealthy] when the targets are not
+// healthy before the deadline.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type UnhealthyError struct {
+	// Report is the last [HealthReport] of the Load Balancer.
+	Report HealthReport
+	Err    error
+}
+
+func (e *UnhealthyError) Error() string {
+	unhealthy := e.Report.Unhealthy()
+	if len(unhealthy) == 0 {
+		return fmt.Sprintf("load balancer %d has no targets: %s", e.Report.LoadBalancer.ID, e.Err)
+	}
+
+	targets := make([]string, 0, len(unhealthy))
+	for _, target := range unhealthy {
+		targets = append(targets, fmt.Sprintf("%s is %s", target, target.Status))
+	}
+	return fmt.Sprintf("load balancer %d targets are not healthy: %s: %s",
+		e.Report.LoadBalancer.ID, strings.Join(targets, ", "), e.Err)
+}
+
+func (e *UnhealthyError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForTargetsHealthy polls the Load Balancer until all targets selected by the
+// selector are healthy for every service, see [Health]. When the context is done or
+// the timeout is reached, an [UnhealthyError] holding the last report is returned.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func WaitForTargetsHealthy(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, selector string, opts HealthOpts) (HealthReport, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	report := HealthReport{LoadBalancer: loadBalancer}
+	retries := 0
+	for {
+		current, err := fetchHealth(ctx, client, loadBalancer, selector)
+		if err != nil {
+			if ctx.Err() != nil {
+				// The deadline was reached during the poll, report the last known health.
+				return report, &UnhealthyError{Report: report, Err: ctx.Err()}
+			}
+			return current, err
+		}
+		report = current
+		if report.Healthy() {
+			return report, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return report, &UnhealthyError{Report: report, Err: ctx.Err()}
+		case <-time.After(opts.backoff(retries)):
+			retries++
+		}
+	}
+}
+
+func fetchHealth(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, selector string) (HealthReport, error) {
+	result, _, err := client.LoadBalancer.GetByID(ctx, loadBalancer.ID)
+	if err != nil {
+		return HealthReport{LoadBalancer: loadBalancer}, err
+	}
+	if result == nil {
+		return HealthReport{LoadBalancer: loadBalancer}, fmt.Errorf("load balancer not found: %d", loadBalancer.ID)
+	}
+	return Health(result, selector), nil
+}
+
+// HealthEvent is a transition of the health status of a target, or a failed poll of
+// the Load Balancer when Err is set.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type HealthEvent struct {
+	Time time.Time
+	// Target holds the new health status of the target, its status is empty when the
+	// target was removed.
+	Target TargetHealth
+	// Previous is the previous health status of the target, empty when the target was
+	// added or on the first poll.
+	Previous hcloud.LoadBalancerTargetHealthStatusStatus
+	Err      error
+}
+
+// WatchTargetsHealth polls the Load Balancer until the context is done, and sends a
+// [HealthEvent] for every transition of the health status of the targets selected by
+// the selector. The first poll sends an event for every target. The [HealthOpts.Timeout]
+// is ignored.
+//
+// The channel is closed when the context is done, the events must be consumed to not
+// block the polling.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func WatchTargetsHealth(ctx context.Context, client *hcloud.Client, loadBalancer *hcloud.LoadBalancer, selector string, opts HealthOpts) <-chan HealthEvent {
+	events := make(chan HealthEvent)
+
+	go func() {
+		defer close(events)
+
+		send := func(event HealthEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		previous := map[string]TargetHealth{}
+		retries := 0
+		for {
+			report, err := fetchHealth(ctx, client, loadBalancer, selector)
+			now := time.Now()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !send(HealthEvent{Time: now, Err: err}) {
+					return
+				}
+				retries++
+			} else {
+				retries = 0
+				current := make(map[string]TargetHealth, len(report.Targets))
+				for _, target := range report.Targets {
+					key := target.String()
+					current[key] = target
+					if before, ok := previous[key]; !ok || before.Status != target.Status {
+						if !send(HealthEvent{Time: now, Target: target, Previous: before.Status}) {
+							return
+						}
+					}
+				}
+				for key, before := range previous {
+					if _, ok := current[key]; !ok {
+						removed := before
+						removed.Status = ""
+						if !send(HealthEvent{Time: now, Target: removed, Previous: before.Status}) {
+							return
+						}
+					}
+				}
+				previous = current
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(opts.backoff(retries)):
+			}
+		}
+	}()
+
+	return events
+}
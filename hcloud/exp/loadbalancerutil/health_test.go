@@ -0,0 +1,147 @@
+package loadbalancerutil
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func TestHealth(t *testing.T) {
+	healthy := hcloud.LoadBalancerTargetHealthStatusStatusHealthy
+	unhealthy := hcloud.LoadBalancerTargetHealthStatusStatusUnhealthy
+
+	loadBalancer := &hcloud.LoadBalancer{
+		ID: 1,
+		Services: []hcloud.LoadBalancerService{
+			{ListenPort: 80},
+			{ListenPort: 443},
+		},
+		Targets: []hcloud.LoadBalancerTarget{
+			{
+				Type: hcloud.LoadBalancerTargetTypeIP,
+				IP:   &hcloud.LoadBalancerTargetIP{IP: "203.0.113.1"},
+				HealthStatus: []hcloud.LoadBalancerTargetHealthStatus{
+					{ListenPort: 80, Status: healthy},
+					{ListenPort: 443, Status: healthy},
+				},
+			},
+			{
+				Type:          hcloud.LoadBalancerTargetTypeLabelSelector,
+				LabelSelector: &hcloud.LoadBalancerTargetLabelSelector{Selector: "deployment=green"},
+				Targets: []hcloud.LoadBalancerTarget{
+					{
+						Type:   hcloud.LoadBalancerTargetTypeServer,
+						Server: &hcloud.LoadBalancerTargetServer{Server: &hcloud.Server{ID: 2}},
+						HealthStatus: []hcloud.LoadBalancerTargetHealthStatus{
+							{ListenPort: 80, Status: unhealthy},
+						},
+					},
+				},
+			},
+			{
+				Type:          hcloud.LoadBalancerTargetTypeLabelSelector,
+				LabelSelector: &hcloud.LoadBalancerTargetLabelSelector{Selector: "deployment=blue"},
+				Targets:       []hcloud.LoadBalancerTarget{},
+			},
+		},
+	}
+
+	report := Health(loadBalancer, "")
+	require.Len(t, report.Targets, 4)
+	assert.False(t, report.Healthy())
+
+	unhealthyTargets := report.Unhealthy()
+	require.Len(t, unhealthyTargets, 2)
+	assert.Equal(t, "server 2 (label_selector deployment=green) port 80", unhealthyTargets[0].String())
+	assert.Equal(t, unhealthy, unhealthyTargets[0].Status)
+	assert.Equal(t, 443, unhealthyTargets[1].ListenPort)
+	assert.Equal(t, hcloud.LoadBalancerTargetHealthStatusStatusUnknown, unhealthyTargets[1].Status)
+
+	report = Health(loadBalancer, "deployment=green")
+	require.Len(t, report.Targets, 2)
+	assert.Equal(t, "deployment=green", report.Targets[0].LabelSelector)
+
+	report = Health(loadBalancer, "deployment=blue")
+	assert.Empty(t, report.Targets)
+	assert.False(t, report.Healthy())
+}
+
+func TestWaitForTargetsHealthy(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	serverResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:             "server",
+		ServerType:       &hcloud.ServerType{Name: "cpx22"},
+		Image:            &hcloud.Image{Name: "debian-13"},
+		Labels:           map[string]string{"role": "web"},
+		StartAfterCreate: hcloud.Ptr(false),
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, serverResult.Action))
+
+	loadBalancer := createLoadBalancer(t, client)
+	opts := HealthOpts{BackoffFunc: hcloud.ConstantBackoff(time.Millisecond)}
+
+	// The server is off
+	report, err := WaitForTargetsHealthy(ctx, client, loadBalancer, "role=web", HealthOpts{
+		BackoffFunc: opts.BackoffFunc,
+		Timeout:     20 * time.Millisecond,
+	})
+	var unhealthyErr *UnhealthyError
+	require.ErrorAs(t, err, &unhealthyErr)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, unhealthyErr.Report.Unhealthy(), 2)
+	assert.Contains(t, err.Error(), fmt.Sprintf("server %d (label_selector role=web) port 80 is unhealthy", serverResult.Server.ID))
+	assert.False(t, report.Healthy())
+
+	// Every poll succeeds, the backoff is never increased.
+	var maxRetries atomic.Int64
+	watchOpts := HealthOpts{BackoffFunc: func(retries int) time.Duration {
+		if int64(retries) > maxRetries.Load() {
+			maxRetries.Store(int64(retries))
+		}
+		return time.Millisecond
+	}}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := WatchTargetsHealth(watchCtx, client, loadBalancer, "role=web", watchOpts)
+
+	for range 2 {
+		event := <-events
+		require.NoError(t, event.Err)
+		assert.Empty(t, event.Previous)
+		assert.Equal(t, hcloud.LoadBalancerTargetHealthStatusStatusUnhealthy, event.Target.Status)
+	}
+
+	action, _, err := client.Server.Poweron(ctx, serverResult.Server)
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	for range 2 {
+		event := <-events
+		require.NoError(t, event.Err)
+		assert.Equal(t, hcloud.LoadBalancerTargetHealthStatusStatusUnhealthy, event.Previous)
+		assert.Equal(t, hcloud.LoadBalancerTargetHealthStatusStatusHealthy, event.Target.Status)
+	}
+
+	cancel()
+	for range events {
+		// Drain the events until the channel is closed.
+	}
+	assert.Equal(t, int64(0), maxRetries.Load())
+
+	report, err = WaitForTargetsHealthy(ctx, client, loadBalancer, "role=web", opts)
+	require.NoError(t, err)
+	assert.True(t, report.Healthy())
+	assert.Len(t, report.Targets, 2)
+}
@@ -0,0 +1,390 @@
+package loadbalancerutil
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// OperationType is the type of an [Operation].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type OperationType string
+
+const (
+	OperationChangeType             OperationType = "change_type"
+	OperationAttachToNetwork        OperationType = "attach_to_network"
+	OperationEnablePublicInterface  OperationType = "enable_public_interface"
+	OperationChangeAlgorithm        OperationType = "change_algorithm"
+	OperationDeleteService          OperationType = "delete_service"
+	OperationUpdateService          OperationType = "update_service"
+	OperationAddService             OperationType = "add_service"
+	OperationRemoveTarget           OperationType = "remove_target"
+	OperationAddTarget              OperationType = "add_target"
+	OperationDisablePublicInterface OperationType = "disable_public_interface"
+	OperationDetachFromNetwork      OperationType = "detach_from_network"
+)
+
+// Operation is a single change of a [Plan].
+//
+// Only the fields related to the type of the operation are set.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Operation struct {
+	Type OperationType
+
+	LoadBalancerType *hcloud.LoadBalancerType
+	Algorithm        hcloud.LoadBalancerAlgorithmType
+	// ListenPort identifies the service of the service operations.
+	ListenPort int
+	Service    hcloud.LoadBalancerCreateOptsService
+	Target     hcloud.LoadBalancerCreateOptsTarget
+	Network    *hcloud.Network
+	// IP is the private IP of the Load Balancer in the network, an IP is assigned if
+	// empty.
+	IP net.IP
+
+	// rollback is the operation reverting this operation, nil if the operation has
+	// nothing to revert.
+	rollback *Operation
+}
+
+func (o *Operation) String() string {
+	switch o.Type {
+	case OperationChangeType:
+		return "change type to " + o.LoadBalancerType.Name
+	case OperationChangeAlgorithm:
+		return "change algorithm to " + string(o.Algorithm)
+	case OperationAttachToNetwork:
+		return fmt.Sprintf("attach to network %d", o.Network.ID)
+	case OperationDetachFromNetwork:
+		return fmt.Sprintf("detach from network %d", o.Network.ID)
+	case OperationEnablePublicInterface:
+		return "enable public interface"
+	case OperationDisablePublicInterface:
+		return "disable public interface"
+	case OperationDeleteService:
+		return fmt.Sprintf("delete service %d", o.ListenPort)
+	case OperationUpdateService:
+		return fmt.Sprintf("update service %d", o.ListenPort)
+	case OperationAddService:
+		return fmt.Sprintf("add service %d", o.ListenPort)
+	case OperationRemoveTarget:
+		return "remove target " + targetKey(o.Target)
+	case OperationAddTarget:
+		return "add target " + targetKey(o.Target)
+	}
+	return string(o.Type)
+}
+
+// Plan is the ordered list of operations required to converge a
+// [hcloud.LoadBalancer] to a [Spec].
+//
+// The operations are ordered so that the Load Balancer is attached to the networks
+// before targets using private IPs are added, and detached after they are removed.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Plan struct {
+	LoadBalancer *hcloud.LoadBalancer
+	Operations   []*Operation
+}
+
+// Empty reports whether the Load Balancer is already in the desired state.
+func (p Plan) Empty() bool {
+	return len(p.Operations) == 0
+}
+
+// Diff computes the [Plan] to converge the Load Balancer to the spec.
+//
+// Services are compared using their listen port, and only the fields set in the spec
+// are compared. The certificates of HTTPS services are compared using their ID or
+// name. Targets are compared using their type and server, label selector or IP, a
+// target whose use of the private IP changes is removed and added again. The server
+// targets must reference the server by ID, which is validated by [Reconcile].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Diff(loadBalancer *hcloud.LoadBalancer, spec Spec) Plan {
+	plan := Plan{LoadBalancer: loadBalancer}
+	add := func(op, rollback *Operation) {
+		op.rollback = rollback
+		plan.Operations = append(plan.Operations, op)
+	}
+
+	if spec.LoadBalancerType != nil && !sameLoadBalancerType(loadBalancer.LoadBalancerType, spec.LoadBalancerType) {
+		var rollback *Operation
+		if loadBalancer.LoadBalancerType != nil {
+			rollback = &Operation{Type: OperationChangeType, LoadBalancerType: loadBalancer.LoadBalancerType}
+		}
+		add(&Operation{Type: OperationChangeType, LoadBalancerType: spec.LoadBalancerType}, rollback)
+	}
+
+	// Networks
+	currentNetworks := make(map[int64]hcloud.LoadBalancerPrivateNet, len(loadBalancer.PrivateNet))
+	for _, privateNet := range loadBalancer.PrivateNet {
+		if privateNet.Network != nil {
+			currentNetworks[privateNet.Network.ID] = privateNet
+		}
+	}
+	desiredNetworks := make(map[int64]bool, len(spec.Networks))
+	for _, network := range spec.Networks {
+		if desiredNetworks[network.ID] {
+			continue
+		}
+		desiredNetworks[network.ID] = true
+		if _, ok := currentNetworks[network.ID]; !ok {
+			add(
+				&Operation{Type: OperationAttachToNetwork, Network: network},
+				&Operation{Type: OperationDetachFromNetwork, Network: network},
+			)
+		}
+	}
+
+	if spec.PublicInterface != nil && *spec.PublicInterface && !loadBalancer.PublicNet.Enabled {
+		add(
+			&Operation{Type: OperationEnablePublicInterface},
+			&Operation{Type: OperationDisablePublicInterface},
+		)
+	}
+
+	if spec.Algorithm != nil && spec.Algorithm.Type != "" && spec.Algorithm.Type != loadBalancer.Algorithm.Type {
+		add(
+			&Operation{Type: OperationChangeAlgorithm, Algorithm: spec.Algorithm.Type},
+			&Operation{Type: OperationChangeAlgorithm, Algorithm: loadBalancer.Algorithm.Type},
+		)
+	}
+
+	// Services
+	currentServices := make(map[int]hcloud.LoadBalancerService, len(loadBalancer.Services))
+	for _, service := range loadBalancer.Services {
+		currentServices[service.ListenPort] = service
+	}
+	desiredServices := make(map[int]hcloud.LoadBalancerCreateOptsService, len(spec.Services))
+	for _, service := range spec.Services {
+		desiredServices[serviceListenPort(service)] = service
+	}
+
+	for _, service := range loadBalancer.Services {
+		if _, ok := desiredServices[service.ListenPort]; !ok {
+			previous := serviceFromCurrent(service)
+			add(
+				&Operation{Type: OperationDeleteService, ListenPort: service.ListenPort},
+				&Operation{Type: OperationAddService, ListenPort: service.ListenPort, Service: previous},
+			)
+		}
+	}
+	for _, service := range spec.Services {
+		listenPort := serviceListenPort(service)
+		if current, ok := currentServices[listenPort]; ok && serviceChanged(current, service) {
+			add(
+				&Operation{Type: OperationUpdateService, ListenPort: listenPort, Service: service},
+				&Operation{Type: OperationUpdateService, ListenPort: listenPort, Service: serviceFromCurrent(current)},
+			)
+		}
+	}
+	for _, service := range spec.Services {
+		listenPort := serviceListenPort(service)
+		if _, ok := currentServices[listenPort]; !ok {
+			// Ignore duplicated listen ports in the spec.
+			currentServices[listenPort] = hcloud.LoadBalancerService{}
+			add(
+				&Operation{Type: OperationAddService, ListenPort: listenPort, Service: service},
+				&Operation{Type: OperationDeleteService, ListenPort: listenPort},
+			)
+		}
+	}
+
+	// Targets
+	currentTargets := make(map[string]hcloud.LoadBalancerTarget, len(loadBalancer.Targets))
+	for _, target := range loadBalancer.Targets {
+		currentTargets[targetKey(targetFromCurrent(target))] = target
+	}
+	desiredTargets := make(map[string]hcloud.LoadBalancerCreateOptsTarget, len(spec.Targets))
+	for _, target := range spec.Targets {
+		desiredTargets[targetKey(target)] = target
+	}
+
+	replaced := map[string]bool{}
+	for _, target := range loadBalancer.Targets {
+		previous := targetFromCurrent(target)
+		key := targetKey(previous)
+		desired, ok := desiredTargets[key]
+		if ok && (desired.UsePrivateIP == nil || *desired.UsePrivateIP == target.UsePrivateIP) {
+			continue
+		}
+		if ok {
+			replaced[key] = true
+		}
+		add(
+			&Operation{Type: OperationRemoveTarget, Target: previous},
+			&Operation{Type: OperationAddTarget, Target: previous},
+		)
+	}
+	for _, target := range spec.Targets {
+		key := targetKey(target)
+		if _, ok := currentTargets[key]; ok && !replaced[key] {
+			continue
+		}
+		// Ignore duplicated targets in the spec.
+		currentTargets[key] = hcloud.LoadBalancerTarget{}
+		delete(replaced, key)
+		add(
+			&Operation{Type: OperationAddTarget, Target: target},
+			&Operation{Type: OperationRemoveTarget, Target: target},
+		)
+	}
+
+	if spec.PublicInterface != nil && !*spec.PublicInterface && loadBalancer.PublicNet.Enabled {
+		add(
+			&Operation{Type: OperationDisablePublicInterface},
+			&Operation{Type: OperationEnablePublicInterface},
+		)
+	}
+
+	for _, privateNet := range loadBalancer.PrivateNet {
+		if privateNet.Network == nil || desiredNetworks[privateNet.Network.ID] {
+			continue
+		}
+		add(
+			&Operation{Type: OperationDetachFromNetwork, Network: privateNet.Network},
+			&Operation{Type: OperationAttachToNetwork, Network: privateNet.Network, IP: privateNet.IP},
+		)
+	}
+
+	return plan
+}
+
+func sameLoadBalancerType(current, desired *hcloud.LoadBalancerType) bool {
+	if current == nil {
+		return false
+	}
+	if desired.ID != 0 && current.ID != 0 {
+		return desired.ID == current.ID
+	}
+	return desired.Name == current.Name
+}
+
+func serviceListenPort(service hcloud.LoadBalancerCreateOptsService) int {
+	if service.ListenPort != nil {
+		return *service.ListenPort
+	}
+	// The API defaults the listen port based on the protocol.
+	switch service.Protocol {
+	case hcloud.LoadBalancerServiceProtocolHTTP:
+		return 80
+	case hcloud.LoadBalancerServiceProtocolHTTPS:
+		return 443
+	}
+	return 0
+}
+
+// serviceChanged reports whether the fields set in the desired service differ from
+// the current service.
+func serviceChanged(current hcloud.LoadBalancerService, desired hcloud.LoadBalancerCreateOptsService) bool {
+	if desired.Protocol != "" && desired.Protocol != current.Protocol {
+		return true
+	}
+	if changed(current.DestinationPort, desired.DestinationPort) ||
+		changed(current.Proxyprotocol, desired.Proxyprotocol) {
+		return true
+	}
+
+	if http := desired.HTTP; http != nil {
+		if changed(current.HTTP.CookieName, http.CookieName) ||
+			changed(current.HTTP.CookieLifetime, http.CookieLifetime) ||
+			changed(current.HTTP.RedirectHTTP, http.RedirectHTTP) ||
+			changed(current.HTTP.StickySessions, http.StickySessions) ||
+			changed(current.HTTP.TimeoutIdle, http.TimeoutIdle) {
+			return true
+		}
+		if http.Certificates != nil && !sameCertificates(current.HTTP.Certificates, http.Certificates) {
+			return true
+		}
+	}
+
+	if healthCheck := desired.HealthCheck; healthCheck != nil {
+		if healthCheck.Protocol != "" && healthCheck.Protocol != current.HealthCheck.Protocol {
+			return true
+		}
+		if changed(current.HealthCheck.Port, healthCheck.Port) ||
+			changed(current.HealthCheck.Interval, healthCheck.Interval) ||
+			changed(current.HealthCheck.Timeout, healthCheck.Timeout) ||
+			changed(current.HealthCheck.Retries, healthCheck.Retries) {
+			return true
+		}
+
+		if http := healthCheck.HTTP; http != nil {
+			currentHTTP := current.HealthCheck.HTTP
+			if currentHTTP == nil {
+				currentHTTP = &hcloud.LoadBalancerServiceHealthCheckHTTP{}
+			}
+			if changed(currentHTTP.Domain, http.Domain) ||
+				changed(currentHTTP.Path, http.Path) ||
+				changed(currentHTTP.Response, http.Response) ||
+				changed(currentHTTP.TLS, http.TLS) {
+				return true
+			}
+			if http.StatusCodes != nil && !sameSet(currentHTTP.StatusCodes, http.StatusCodes) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// changed reports whether the desired value is set and differs from the current
+// value.
+func changed[T comparable](current T, desired *T) bool {
+	return desired != nil && *desired != current
+}
+
+func sameCertificates(current, desired []*hcloud.Certificate) bool {
+	key := func(certificate *hcloud.Certificate) string {
+		if certificate.ID != 0 {
+			return strconv.FormatInt(certificate.ID, 10)
+		}
+		return "name|" + certificate.Name
+	}
+	currentKeys := make([]string, 0, len(current))
+	for _, certificate := range current {
+		currentKeys = append(currentKeys, key(certificate))
+		// Match the desired certificates referenced by name.
+		if certificate.ID != 0 && certificate.Name != "" {
+			currentKeys = append(currentKeys, "name|"+certificate.Name)
+		}
+	}
+	if len(desired) != len(current) {
+		return false
+	}
+	for _, certificate := range desired {
+		if !slices.Contains(currentKeys, key(certificate)) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSet(current, desired []string) bool {
+	a, b := slices.Clone(current), slices.Clone(desired)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// targetKey returns a canonical representation of the target identity.
+func targetKey(target hcloud.LoadBalancerCreateOptsTarget) string {
+	switch target.Type {
+	case hcloud.LoadBalancerTargetTypeServer:
+		if target.Server.Server != nil {
+			return fmt.Sprintf("server %d", target.Server.Server.ID)
+		}
+	case hcloud.LoadBalancerTargetTypeLabelSelector:
+		return "label_selector " + target.LabelSelector.Selector
+	case hcloud.LoadBalancerTargetTypeIP:
+		return "ip " + target.IP.IP
+	}
+	return string(target.Type)
+}
@@ -0,0 +1,195 @@
+package loadbalancerutil
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func operationTypes(plan Plan) []OperationType {
+	result := make([]OperationType, 0, len(plan.Operations))
+	for _, op := range plan.Operations {
+		result = append(result, op.Type)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	server := &hcloud.Server{ID: 1}
+	network := &hcloud.Network{ID: 10}
+	certificate := &hcloud.Certificate{ID: 5, Name: "example.com"}
+
+	loadBalancer := &hcloud.LoadBalancer{
+		ID:               1,
+		LoadBalancerType: &hcloud.LoadBalancerType{ID: 1, Name: "lb11"},
+		Algorithm:        hcloud.LoadBalancerAlgorithm{Type: hcloud.LoadBalancerAlgorithmTypeRoundRobin},
+		PublicNet:        hcloud.LoadBalancerPublicNet{Enabled: true},
+		PrivateNet: []hcloud.LoadBalancerPrivateNet{
+			{Network: network, IP: net.ParseIP("10.0.0.2")},
+		},
+		Services: []hcloud.LoadBalancerService{
+			{
+				Protocol:        hcloud.LoadBalancerServiceProtocolHTTPS,
+				ListenPort:      443,
+				DestinationPort: 80,
+				HTTP: hcloud.LoadBalancerServiceHTTP{
+					Certificates: []*hcloud.Certificate{certificate},
+					TimeoutIdle:  30 * time.Second,
+				},
+				HealthCheck: hcloud.LoadBalancerServiceHealthCheck{
+					Protocol: hcloud.LoadBalancerServiceProtocolHTTP,
+					Port:     80,
+					Interval: 15 * time.Second,
+					Timeout:  10 * time.Second,
+					Retries:  3,
+					HTTP: &hcloud.LoadBalancerServiceHealthCheckHTTP{
+						Path:        "/",
+						StatusCodes: []string{"2??", "3??"},
+					},
+				},
+			},
+			{
+				Protocol:        hcloud.LoadBalancerServiceProtocolTCP,
+				ListenPort:      22,
+				DestinationPort: 22,
+			},
+		},
+		Targets: []hcloud.LoadBalancerTarget{
+			{
+				Type:   hcloud.LoadBalancerTargetTypeServer,
+				Server: &hcloud.LoadBalancerTargetServer{Server: server},
+			},
+			{
+				Type:          hcloud.LoadBalancerTargetTypeLabelSelector,
+				LabelSelector: &hcloud.LoadBalancerTargetLabelSelector{Selector: "role=web"},
+				UsePrivateIP:  true,
+			},
+		},
+	}
+
+	https := hcloud.LoadBalancerCreateOptsService{
+		Protocol:   hcloud.LoadBalancerServiceProtocolHTTPS,
+		ListenPort: hcloud.Ptr(443),
+		HTTP: &hcloud.LoadBalancerCreateOptsServiceHTTP{
+			Certificates: []*hcloud.Certificate{{Name: "example.com"}},
+		},
+		HealthCheck: &hcloud.LoadBalancerCreateOptsServiceHealthCheck{
+			Retries: hcloud.Ptr(3),
+			HTTP: &hcloud.LoadBalancerCreateOptsServiceHealthCheckHTTP{
+				StatusCodes: []string{"3??", "2??"},
+			},
+		},
+	}
+	ssh := hcloud.LoadBalancerCreateOptsService{
+		Protocol:   hcloud.LoadBalancerServiceProtocolTCP,
+		ListenPort: hcloud.Ptr(22),
+	}
+	serverTarget := hcloud.LoadBalancerCreateOptsTarget{
+		Type:   hcloud.LoadBalancerTargetTypeServer,
+		Server: hcloud.LoadBalancerCreateOptsTargetServer{Server: server},
+	}
+	selectorTarget := hcloud.LoadBalancerCreateOptsTarget{
+		Type:          hcloud.LoadBalancerTargetTypeLabelSelector,
+		LabelSelector: hcloud.LoadBalancerCreateOptsTargetLabelSelector{Selector: "role=web"},
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		plan := Diff(loadBalancer, Spec{
+			LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb11"},
+			Algorithm:        &hcloud.LoadBalancerAlgorithm{Type: hcloud.LoadBalancerAlgorithmTypeRoundRobin},
+			PublicInterface:  hcloud.Ptr(true),
+			Services:         []hcloud.LoadBalancerCreateOptsService{ssh, https},
+			Targets:          []hcloud.LoadBalancerCreateOptsTarget{selectorTarget, serverTarget},
+			Networks:         []*hcloud.Network{network},
+		})
+		assert.True(t, plan.Empty())
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		httpsChanged := https
+		httpsChanged.HealthCheck = &hcloud.LoadBalancerCreateOptsServiceHealthCheck{
+			HTTP: &hcloud.LoadBalancerCreateOptsServiceHealthCheckHTTP{Path: hcloud.Ptr("/healthz")},
+		}
+		http := hcloud.LoadBalancerCreateOptsService{Protocol: hcloud.LoadBalancerServiceProtocolHTTP}
+		selectorTargetPublic := selectorTarget
+		selectorTargetPublic.UsePrivateIP = hcloud.Ptr(false)
+		ipTarget := hcloud.LoadBalancerCreateOptsTarget{
+			Type: hcloud.LoadBalancerTargetTypeIP,
+			IP:   hcloud.LoadBalancerCreateOptsTargetIP{IP: "203.0.113.1"},
+		}
+		otherNetwork := &hcloud.Network{ID: 11}
+
+		plan := Diff(loadBalancer, Spec{
+			LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb21"},
+			Algorithm:        &hcloud.LoadBalancerAlgorithm{Type: hcloud.LoadBalancerAlgorithmTypeLeastConnections},
+			PublicInterface:  hcloud.Ptr(false),
+			Services:         []hcloud.LoadBalancerCreateOptsService{httpsChanged, http},
+			Targets:          []hcloud.LoadBalancerCreateOptsTarget{selectorTargetPublic, ipTarget},
+			Networks:         []*hcloud.Network{otherNetwork},
+		})
+		assert.Equal(t, []OperationType{
+			OperationChangeType,
+			OperationAttachToNetwork,
+			OperationChangeAlgorithm,
+			OperationDeleteService,
+			OperationUpdateService,
+			OperationAddService,
+			OperationRemoveTarget,
+			OperationRemoveTarget,
+			OperationAddTarget,
+			OperationAddTarget,
+			OperationDisablePublicInterface,
+			OperationDetachFromNetwork,
+		}, operationTypes(plan))
+
+		ops := plan.Operations
+		assert.Equal(t, "change type to lb21", ops[0].String())
+		assert.Equal(t, "lb11", ops[0].rollback.LoadBalancerType.Name)
+		assert.Equal(t, otherNetwork, ops[1].Network)
+		assert.Equal(t, hcloud.LoadBalancerAlgorithmTypeRoundRobin, ops[2].rollback.Algorithm)
+		assert.Equal(t, 22, ops[3].ListenPort)
+		assert.Equal(t, OperationAddService, ops[3].rollback.Type)
+		assert.Equal(t, 443, ops[4].ListenPort)
+		assert.Equal(t, "/", *ops[4].rollback.Service.HealthCheck.HTTP.Path)
+		assert.Equal(t, "add service 80", ops[5].String())
+		assert.Equal(t, "remove target server 1", ops[6].String())
+		assert.Equal(t, "remove target label_selector role=web", ops[7].String())
+		assert.Equal(t, "add target label_selector role=web", ops[8].String())
+		assert.False(t, *ops[8].Target.UsePrivateIP)
+		assert.Equal(t, "add target ip 203.0.113.1", ops[9].String())
+		assert.Equal(t, "detach from network 10", ops[11].String())
+		assert.Equal(t, net.ParseIP("10.0.0.2"), ops[11].rollback.IP)
+	})
+
+	t.Run("certificate changed", func(t *testing.T) {
+		httpsChanged := https
+		httpsChanged.HTTP = &hcloud.LoadBalancerCreateOptsServiceHTTP{
+			Certificates: []*hcloud.Certificate{{ID: 6}},
+		}
+
+		plan := Diff(loadBalancer, Spec{
+			Services: []hcloud.LoadBalancerCreateOptsService{ssh, httpsChanged},
+			Targets:  []hcloud.LoadBalancerCreateOptsTarget{selectorTarget, serverTarget},
+			Networks: []*hcloud.Network{network},
+		})
+		assert.Equal(t, []OperationType{OperationUpdateService}, operationTypes(plan))
+	})
+
+	t.Run("from create opts", func(t *testing.T) {
+		spec := SpecFromCreateOpts(hcloud.LoadBalancerCreateOpts{
+			LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb11"},
+			Network:          network,
+			Services:         []hcloud.LoadBalancerCreateOptsService{https, ssh},
+			Targets:          []hcloud.LoadBalancerCreateOptsTarget{serverTarget, selectorTarget},
+		})
+		require.Len(t, spec.Networks, 1)
+
+		plan := Diff(loadBalancer, spec)
+		assert.True(t, plan.Empty())
+	})
+}
@@ -0,0 +1,210 @@
+// Package loadbalancerutil converges a Load Balancer to a declarative specification.
+//
+// The specification is built on [hcloud.LoadBalancerCreateOpts], [Diff] computes the
+// ordered operations to converge a Load Balancer to it, and [Apply] executes them:
+//
+//	spec := loadbalancerutil.SpecFromCreateOpts(opts)
+//	plan, err := loadbalancerutil.Reconcile(ctx, client, loadBalancer, spec, loadbalancerutil.ReconcileOpts{})
+//
+// The health of the targets can be awaited with [WaitForTargetsHealthy], or watched
+// with [WatchTargetsHealth]:
+//
+//	report, err := loadbalancerutil.WaitForTargetsHealthy(ctx, client, loadBalancer, "deployment=green", loadbalancerutil.HealthOpts{})
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package loadbalancerutil
+
+import (
+	"errors"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Spec is the desired state of a [hcloud.LoadBalancer].
+//
+// The nil fields are left unchanged, except the services, targets and networks which
+// describe the complete desired list. The order of the services, targets and networks
+// is not significant.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Spec struct {
+	LoadBalancerType *hcloud.LoadBalancerType
+	Algorithm        *hcloud.LoadBalancerAlgorithm
+	PublicInterface  *bool
+
+	// Services are identified by their listen port. The unset fields of a service are
+	// left unchanged.
+	Services []hcloud.LoadBalancerCreateOptsService
+	// Targets are identified by their type and server, label selector or IP. The
+	// servers must be referenced by their ID.
+	Targets  []hcloud.LoadBalancerCreateOptsTarget
+	Networks []*hcloud.Network
+}
+
+// SpecFromCreateOpts returns the [Spec] defined by the create options of a Load
+// Balancer.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func SpecFromCreateOpts(opts hcloud.LoadBalancerCreateOpts) Spec {
+	spec := Spec{
+		LoadBalancerType: opts.LoadBalancerType,
+		Algorithm:        opts.Algorithm,
+		PublicInterface:  opts.PublicInterface,
+		Services:         opts.Services,
+		Targets:          opts.Targets,
+	}
+	if opts.Network != nil {
+		spec.Networks = []*hcloud.Network{opts.Network}
+	}
+	return spec
+}
+
+// validate returns an error if the spec can not be compared with the current state of
+// a Load Balancer.
+func (s Spec) validate() error {
+	for _, target := range s.Targets {
+		if target.Type == hcloud.LoadBalancerTargetTypeServer && (target.Server.Server == nil || target.Server.Server.ID == 0) {
+			return errors.New("server targets must reference the server by ID")
+		}
+	}
+	return nil
+}
+
+// serviceFromCurrent returns the options defining the current service, used to
+// restore it.
+func serviceFromCurrent(service hcloud.LoadBalancerService) hcloud.LoadBalancerCreateOptsService {
+	result := hcloud.LoadBalancerCreateOptsService{
+		Protocol:        service.Protocol,
+		ListenPort:      hcloud.Ptr(service.ListenPort),
+		DestinationPort: hcloud.Ptr(service.DestinationPort),
+		Proxyprotocol:   hcloud.Ptr(service.Proxyprotocol),
+		HealthCheck: &hcloud.LoadBalancerCreateOptsServiceHealthCheck{
+			Protocol: service.HealthCheck.Protocol,
+			Port:     hcloud.Ptr(service.HealthCheck.Port),
+			Interval: hcloud.Ptr(service.HealthCheck.Interval),
+			Timeout:  hcloud.Ptr(service.HealthCheck.Timeout),
+			Retries:  hcloud.Ptr(service.HealthCheck.Retries),
+		},
+	}
+	if service.Protocol != hcloud.LoadBalancerServiceProtocolTCP {
+		result.HTTP = &hcloud.LoadBalancerCreateOptsServiceHTTP{
+			CookieName:     hcloud.Ptr(service.HTTP.CookieName),
+			CookieLifetime: hcloud.Ptr(service.HTTP.CookieLifetime),
+			Certificates:   service.HTTP.Certificates,
+			RedirectHTTP:   hcloud.Ptr(service.HTTP.RedirectHTTP),
+			StickySessions: hcloud.Ptr(service.HTTP.StickySessions),
+			TimeoutIdle:    hcloud.Ptr(service.HTTP.TimeoutIdle),
+		}
+	}
+	if http := service.HealthCheck.HTTP; http != nil {
+		result.HealthCheck.HTTP = &hcloud.LoadBalancerCreateOptsServiceHealthCheckHTTP{
+			Domain:      hcloud.Ptr(http.Domain),
+			Path:        hcloud.Ptr(http.Path),
+			Response:    hcloud.Ptr(http.Response),
+			StatusCodes: http.StatusCodes,
+			TLS:         hcloud.Ptr(http.TLS),
+		}
+	}
+	return result
+}
+
+func addServiceOpts(service hcloud.LoadBalancerCreateOptsService) hcloud.LoadBalancerAddServiceOpts {
+	result := hcloud.LoadBalancerAddServiceOpts{
+		Protocol:        service.Protocol,
+		ListenPort:      service.ListenPort,
+		DestinationPort: service.DestinationPort,
+		Proxyprotocol:   service.Proxyprotocol,
+	}
+	if http := service.HTTP; http != nil {
+		result.HTTP = &hcloud.LoadBalancerAddServiceOptsHTTP{
+			CookieName:     http.CookieName,
+			CookieLifetime: http.CookieLifetime,
+			Certificates:   http.Certificates,
+			RedirectHTTP:   http.RedirectHTTP,
+			StickySessions: http.StickySessions,
+			TimeoutIdle:    http.TimeoutIdle,
+		}
+	}
+	if healthCheck := service.HealthCheck; healthCheck != nil {
+		result.HealthCheck = &hcloud.LoadBalancerAddServiceOptsHealthCheck{
+			Protocol: healthCheck.Protocol,
+			Port:     healthCheck.Port,
+			Interval: healthCheck.Interval,
+			Timeout:  healthCheck.Timeout,
+			Retries:  healthCheck.Retries,
+		}
+		if http := healthCheck.HTTP; http != nil {
+			result.HealthCheck.HTTP = &hcloud.LoadBalancerAddServiceOptsHealthCheckHTTP{
+				Domain:      http.Domain,
+				Path:        http.Path,
+				Response:    http.Response,
+				StatusCodes: http.StatusCodes,
+				TLS:         http.TLS,
+			}
+		}
+	}
+	return result
+}
+
+func updateServiceOpts(service hcloud.LoadBalancerCreateOptsService) hcloud.LoadBalancerUpdateServiceOpts {
+	result := hcloud.LoadBalancerUpdateServiceOpts{
+		Protocol:        service.Protocol,
+		DestinationPort: service.DestinationPort,
+		Proxyprotocol:   service.Proxyprotocol,
+	}
+	if http := service.HTTP; http != nil {
+		result.HTTP = &hcloud.LoadBalancerUpdateServiceOptsHTTP{
+			CookieName:     http.CookieName,
+			CookieLifetime: http.CookieLifetime,
+			Certificates:   http.Certificates,
+			RedirectHTTP:   http.RedirectHTTP,
+			StickySessions: http.StickySessions,
+			TimeoutIdle:    http.TimeoutIdle,
+		}
+	}
+	if healthCheck := service.HealthCheck; healthCheck != nil {
+		result.HealthCheck = &hcloud.LoadBalancerUpdateServiceOptsHealthCheck{
+			Protocol: healthCheck.Protocol,
+			Port:     healthCheck.Port,
+			Interval: healthCheck.Interval,
+			Timeout:  healthCheck.Timeout,
+			Retries:  healthCheck.Retries,
+		}
+		if http := healthCheck.HTTP; http != nil {
+			result.HealthCheck.HTTP = &hcloud.LoadBalancerUpdateServiceOptsHealthCheckHTTP{
+				Domain:      http.Domain,
+				Path:        http.Path,
+				Response:    http.Response,
+				StatusCodes: http.StatusCodes,
+				TLS:         http.TLS,
+			}
+		}
+	}
+	return result
+}
+
+// targetFromCurrent returns the options defining the current target, used to restore
+// it.
+func targetFromCurrent(target hcloud.LoadBalancerTarget) hcloud.LoadBalancerCreateOptsTarget {
+	result := hcloud.LoadBalancerCreateOptsTarget{
+		Type:         target.Type,
+		UsePrivateIP: hcloud.Ptr(target.UsePrivateIP),
+	}
+	switch target.Type {
+	case hcloud.LoadBalancerTargetTypeServer:
+		if target.Server != nil {
+			result.Server.Server = target.Server.Server
+		}
+	case hcloud.LoadBalancerTargetTypeLabelSelector:
+		if target.LabelSelector != nil {
+			result.LabelSelector.Selector = target.LabelSelector.Selector
+		}
+	case hcloud.LoadBalancerTargetTypeIP:
+		if target.IP != nil {
+			result.IP.IP = target.IP.IP
+		}
+		// IP targets do not support private IPs.
+		result.UsePrivateIP = nil
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package metricsutil
+
+import (
+	"math"
+	"slices"
+)
+
+// values returns the values of the series, without the missing values.
+func (s *Series) values() []float64 {
+	result := make([]float64, 0, len(s.Points))
+	for _, point := range s.Points {
+		if !math.IsNaN(point.Value) {
+			result = append(result, point.Value)
+		}
+	}
+	return result
+}
+
+// Min returns the minimum value of the series, or NaN if the series has no values.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Min() float64 {
+	values := s.values()
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return slices.Min(values)
+}
+
+// Max returns the maximum value of the series, or NaN if the series has no values.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Max() float64 {
+	values := s.values()
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	return slices.Max(values)
+}
+
+// Avg returns the average value of the series, or NaN if the series has no values.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Avg() float64 {
+	values := s.values()
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of the values of the series,
+// interpolated linearly between the closest ranks. Returns NaN if the series has no
+// values or p is out of range.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Percentile(p float64) float64 {
+	values := s.values()
+	if len(values) == 0 || p < 0 || p > 100 {
+		return math.NaN()
+	}
+	slices.Sort(values)
+
+	rank := p / 100 * float64(len(values)-1)
+	lower, upper := int(math.Floor(rank)), int(math.Ceil(rank))
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+// Rate returns the per-second rate of change between the first and the last value of
+// the series, or NaN if the series has less than two values.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Rate() float64 {
+	var first, last *Point
+	for i := range s.Points {
+		if math.IsNaN(s.Points[i].Value) {
+			continue
+		}
+		if first == nil {
+			first = &s.Points[i]
+		}
+		last = &s.Points[i]
+	}
+	if first == nil || first == last {
+		return math.NaN()
+	}
+	return (last.Value - first.Value) / last.Time.Sub(first.Time).Seconds()
+}
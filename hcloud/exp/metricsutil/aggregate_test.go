@@ -0,0 +1,43 @@
+package metricsutil
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSeries(start time.Time, step time.Duration, values ...float64) *Series {
+	series := &Series{Name: "test", Step: step}
+	for i, value := range values {
+		series.Points = append(series.Points, Point{Time: start.Add(time.Duration(i) * step), Value: value})
+	}
+	return series
+}
+
+func TestSeriesAggregates(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	series := newTestSeries(start, 10*time.Second, 4, 1, math.NaN(), 3, 2, 10)
+
+	assert.InDelta(t, 1.0, series.Min(), 0)
+	assert.InDelta(t, 10.0, series.Max(), 0)
+	assert.InDelta(t, 4.0, series.Avg(), 1e-9)
+	assert.InDelta(t, 1.0, series.Percentile(0), 0)
+	assert.InDelta(t, 3.0, series.Percentile(50), 0)
+	assert.InDelta(t, 7.0, series.Percentile(87.5), 1e-9)
+	assert.InDelta(t, 10.0, series.Percentile(100), 0)
+	assert.True(t, math.IsNaN(series.Percentile(101)))
+	// (10 - 4) / 50s
+	assert.InDelta(t, 0.12, series.Rate(), 1e-9)
+}
+
+func TestSeriesAggregatesEmpty(t *testing.T) {
+	series := newTestSeries(time.Unix(1700000000, 0), time.Second, math.NaN())
+
+	assert.True(t, math.IsNaN(series.Min()))
+	assert.True(t, math.IsNaN(series.Max()))
+	assert.True(t, math.IsNaN(series.Avg()))
+	assert.True(t, math.IsNaN(series.Percentile(50)))
+	assert.True(t, math.IsNaN(series.Rate()))
+}
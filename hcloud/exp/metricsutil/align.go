@@ -0,0 +1,82 @@
+package metricsutil
+
+import (
+	"math"
+	"time"
+)
+
+// Resample returns a copy of the series with count points separated by step, from
+// start. The value of a point is the average of the values of the series in
+// [Time, Time+step), or NaN if there is none. The copy has no points if step or
+// count are not positive.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Resample(start time.Time, step time.Duration, count int) *Series {
+	result := &Series{Name: s.Name, Step: step, Points: []Point{}}
+	if step <= 0 || count <= 0 {
+		return result
+	}
+
+	sums := make([]float64, count)
+	counts := make([]int, count)
+	for _, point := range s.Points {
+		if math.IsNaN(point.Value) || point.Time.Before(start) {
+			continue
+		}
+		i := int(point.Time.Sub(start) / step)
+		if i >= count {
+			continue
+		}
+		sums[i] += point.Value
+		counts[i]++
+	}
+
+	for i := range count {
+		value := math.NaN()
+		if counts[i] > 0 {
+			value = sums[i] / float64(counts[i])
+		}
+		result.Points = append(result.Points, Point{Time: start.Add(time.Duration(i) * step), Value: value})
+	}
+	return result
+}
+
+// Align resamples the series to a common step and time range, so their points can be
+// compared one by one, e.g. the metrics of several servers. The common step is the
+// largest step of the series, and the common time range is the range covered by all
+// the series.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Align(series ...*Series) []*Series {
+	var (
+		step       time.Duration
+		start, end time.Time
+		empty      = len(series) == 0
+	)
+	for i, s := range series {
+		step = max(step, s.Step)
+		if len(s.Points) == 0 {
+			empty = true
+			continue
+		}
+		first, last := s.Points[0].Time, s.Points[len(s.Points)-1].Time
+		if i == 0 || first.After(start) {
+			start = first
+		}
+		if i == 0 || last.Before(end) {
+			end = last
+		}
+	}
+
+	count := 0
+	if step > 0 && !empty && !end.Before(start) {
+		start = start.Truncate(step)
+		count = int(end.Sub(start)/step) + 1
+	}
+
+	result := make([]*Series, 0, len(series))
+	for _, s := range series {
+		result = append(result, s.Resample(start, step, count))
+	}
+	return result
+}
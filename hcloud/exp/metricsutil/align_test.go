@@ -0,0 +1,54 @@
+package metricsutil
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func values(series *Series) []float64 {
+	result := make([]float64, 0, len(series.Points))
+	for _, point := range series.Points {
+		result = append(result, point.Value)
+	}
+	return result
+}
+
+func TestResample(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	series := newTestSeries(start, 10*time.Second, 1, 3, math.NaN(), math.NaN(), 5, 7)
+
+	result := series.Resample(start, 20*time.Second, 4)
+	assert.Equal(t, 20*time.Second, result.Step)
+	require.Len(t, result.Points, 4)
+	assert.Equal(t, start.Add(20*time.Second), result.Points[1].Time)
+	assert.InDelta(t, 2.0, result.Points[0].Value, 0)
+	assert.True(t, math.IsNaN(result.Points[1].Value))
+	assert.InDelta(t, 6.0, result.Points[2].Value, 0)
+	assert.True(t, math.IsNaN(result.Points[3].Value))
+
+	assert.Empty(t, series.Resample(start, 0, 4).Points)
+}
+
+func TestAlign(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	a := newTestSeries(start, 10*time.Second, 1, 2, 3, 4, 5, 6, 7, 8)
+	b := newTestSeries(start.Add(20*time.Second), 20*time.Second, 10, 20, 30, 40)
+
+	result := Align(a, b)
+	require.Len(t, result, 2)
+	for _, series := range result {
+		assert.Equal(t, 20*time.Second, series.Step)
+		require.Len(t, series.Points, 3)
+		assert.Equal(t, start.Add(20*time.Second), series.Points[0].Time)
+	}
+	assert.Equal(t, []float64{3.5, 5.5, 7.5}, values(result[0]))
+	assert.Equal(t, []float64{10, 20, 30}, values(result[1]))
+
+	result = Align(a, &Series{Step: time.Minute})
+	assert.Empty(t, result[0].Points)
+	assert.Empty(t, result[1].Points)
+}
@@ -0,0 +1,201 @@
+package metricsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// CollectorOpts defines the options of a [Collector].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type CollectorOpts struct {
+	// LabelSelector selects the servers to scrape, all servers are scraped if empty.
+	LabelSelector string
+	// Types are the types of metrics to scrape, defaults to all types.
+	Types []hcloud.ServerMetricType
+	// Interval is the duration between two scrapes, defaults to 1 minute.
+	Interval time.Duration
+	// Window is the range of metrics fetched by a scrape, defaults to 5 minutes. The
+	// last value of every series in the range is exposed.
+	Window time.Duration
+}
+
+type collectorSample struct {
+	serverID   int64
+	serverName string
+	series     string
+	point      Point
+}
+
+// Collector periodically scrapes the metrics of the servers matching a label
+// selector, and exposes the last value of every series as a [prometheus.Collector]:
+//
+//	collector := metricsutil.NewCollector(client, metricsutil.CollectorOpts{LabelSelector: "env=prod"})
+//	registry.MustRegister(collector)
+//	go collector.Run(ctx)
+//
+// A Collector must be created using the [NewCollector] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Collector struct {
+	client *hcloud.Client
+	opts   CollectorOpts
+
+	valueDesc   *prometheus.Desc
+	successDesc *prometheus.Desc
+
+	mu      sync.Mutex
+	samples []collectorSample
+	err     error
+	scraped bool
+}
+
+// NewCollector returns a new [Collector] scraping the servers with the client.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewCollector(client *hcloud.Client, opts CollectorOpts) *Collector {
+	if len(opts.Types) == 0 {
+		opts.Types = []hcloud.ServerMetricType{hcloud.ServerMetricCPU, hcloud.ServerMetricDisk, hcloud.ServerMetricNetwork}
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.Window <= 0 {
+		opts.Window = 5 * time.Minute
+	}
+
+	return &Collector{
+		client: client,
+		opts:   opts,
+		valueDesc: prometheus.NewDesc(
+			"hcloud_server_metric_value",
+			"Last value of a time series of the server metrics.",
+			[]string{"server_id", "server_name", "series"},
+			nil,
+		),
+		successDesc: prometheus.NewDesc(
+			"hcloud_server_metric_scrape_success",
+			"Whether the last scrape of the server metrics succeeded.",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Run scrapes the metrics every interval, until the context is done.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (c *Collector) Run(ctx context.Context) {
+	for {
+		_ = c.Scrape(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.opts.Interval):
+		}
+	}
+}
+
+// Scrape fetches the metrics of the servers once. The servers whose metrics could
+// not be fetched are not exposed until the next successful scrape.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (c *Collector) Scrape(ctx context.Context) error {
+	samples, err := c.scrape(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.samples, c.err, c.scraped = samples, err, true
+	return err
+}
+
+// Err returns the error of the last scrape, or nil if it succeeded.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (c *Collector) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *Collector) scrape(ctx context.Context) ([]collectorSample, error) {
+	servers, err := c.client.Server.AllWithOpts(ctx, hcloud.ServerListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: c.opts.LabelSelector},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+
+	end := time.Now()
+	start := end.Add(-c.opts.Window)
+
+	samples := []collectorSample{}
+	errs := []error{}
+	for _, server := range servers {
+		metrics, _, err := c.client.Server.GetMetrics(ctx, server, hcloud.ServerGetMetricsOpts{
+			Types: c.opts.Types,
+			Start: start,
+			End:   end,
+		})
+		if err == nil {
+			var series map[string]*Series
+			series, err = ServerSeries(metrics)
+			for name, s := range series {
+				if point, ok := s.Last(); ok {
+					samples = append(samples, collectorSample{
+						serverID:   server.ID,
+						serverName: server.Name,
+						series:     name,
+						point:      point,
+					})
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not get metrics of server %d: %w", server.ID, err))
+		}
+	}
+	return samples, errors.Join(errs...)
+}
+
+// Describe implements [prometheus.Collector].
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.valueDesc
+	ch <- c.successDesc
+}
+
+// Collect implements [prometheus.Collector].
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.scraped {
+		return
+	}
+
+	success := 0.0
+	if c.err == nil {
+		success = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.successDesc, prometheus.GaugeValue, success)
+
+	for _, sample := range c.samples {
+		ch <- prometheus.NewMetricWithTimestamp(sample.point.Time, prometheus.MustNewConstMetric(
+			c.valueDesc,
+			prometheus.GaugeValue,
+			sample.point.Value,
+			strconv.FormatInt(sample.serverID, 10), sample.serverName, sample.series,
+		))
+	}
+}
@@ -0,0 +1,114 @@
+package metricsutil
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/mockutil"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/schema"
+)
+
+func TestCollector(t *testing.T) {
+	metricsResponse := func(timeSeries map[string]schema.ServerTimeSeriesVals) schema.ServerGetMetricsResponse {
+		resp := schema.ServerGetMetricsResponse{}
+		resp.Metrics.Step = 60
+		resp.Metrics.TimeSeries = timeSeries
+		return resp
+	}
+
+	server := mockutil.NewServer(t, []mockutil.Request{
+		{
+			Method: "GET",
+			Want: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/servers", r.URL.Path)
+				assert.Equal(t, "env=prod", r.URL.Query().Get("label_selector"))
+			},
+			Status: 200,
+			JSON: schema.ServerListResponse{Servers: []schema.Server{
+				{ID: 1, Name: "web-1"},
+				{ID: 2, Name: "web-2"},
+			}},
+		},
+		{
+			Method: "GET",
+			Want: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/servers/1/metrics", r.URL.Path)
+				assert.Equal(t, []string{"cpu"}, r.URL.Query()["type"])
+			},
+			Status: 200,
+			JSON: metricsResponse(map[string]schema.ServerTimeSeriesVals{
+				"cpu": {Values: []any{[]any{1700000000.0, "12.5"}, []any{1700000060.0, "NaN"}}},
+			}),
+		},
+		{
+			Method: "GET",
+			Want: func(t *testing.T, r *http.Request) {
+				assert.Equal(t, "/servers/2/metrics", r.URL.Path)
+			},
+			Status: 500,
+			JSON:   schema.ErrorResponse{Error: schema.Error{Code: "server_error", Message: "Internal Server Error"}},
+		},
+	})
+
+	client := hcloud.NewClient(
+		hcloud.WithEndpoint(server.URL),
+		hcloud.WithToken("token"),
+		hcloud.WithRetryOpts(hcloud.RetryOpts{BackoffFunc: hcloud.ConstantBackoff(0), MaxRetries: 0}),
+	)
+
+	collector := NewCollector(client, CollectorOpts{
+		LabelSelector: "env=prod",
+		Types:         []hcloud.ServerMetricType{hcloud.ServerMetricCPU},
+	})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	// Nothing is exposed before the first scrape.
+	count, err := testutil.GatherAndCount(registry)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	err = collector.Scrape(context.Background())
+	require.ErrorContains(t, err, "could not get metrics of server 2: ")
+	assert.Equal(t, err, collector.Err())
+
+	err = testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP hcloud_server_metric_scrape_success Whether the last scrape of the server metrics succeeded.
+# TYPE hcloud_server_metric_scrape_success gauge
+hcloud_server_metric_scrape_success 0
+# HELP hcloud_server_metric_value Last value of a time series of the server metrics.
+# TYPE hcloud_server_metric_value gauge
+hcloud_server_metric_value{series="cpu",server_id="1",server_name="web-1"} 12.5 1700000000000
+`))
+	require.NoError(t, err)
+}
+
+func TestCollectorRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := mockutil.NewServer(t, []mockutil.Request{
+		{Method: "GET", Status: 200, JSON: schema.ServerListResponse{Servers: []schema.Server{}}},
+		{
+			Method: "GET",
+			Want:   func(*testing.T, *http.Request) { cancel() },
+			Status: 200,
+			JSON:   schema.ServerListResponse{Servers: []schema.Server{}},
+		},
+	})
+
+	client := hcloud.NewClient(hcloud.WithEndpoint(server.URL), hcloud.WithToken("token"))
+	collector := NewCollector(client, CollectorOpts{Interval: time.Millisecond})
+
+	collector.Run(ctx)
+}
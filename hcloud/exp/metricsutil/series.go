@@ -0,0 +1,107 @@
+// Package metricsutil converts the metrics of servers and load balancers to numeric
+// time series, computes aggregates over them and exposes them to Prometheus.
+//
+//	metrics, _, err := client.Server.GetMetrics(ctx, server, opts)
+//	series, err := metricsutil.ServerSeries(metrics)
+//
+//	cpu := series["cpu"]
+//	fmt.Println(cpu.Avg(), cpu.Percentile(95))
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package metricsutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// Point is a value of a [Series] at a point in time.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a numeric time series, ordered by time. Missing values are NaN.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Series struct {
+	Name   string
+	Step   time.Duration
+	Points []Point
+}
+
+// ServerSeries converts the time series of the server metrics, keyed by their name,
+// e.g. `cpu` or `network.0.bandwidth.in`.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ServerSeries(metrics *hcloud.ServerMetrics) (map[string]*Series, error) {
+	return convertSeries(metrics.TimeSeries, metrics.Step, func(value hcloud.ServerMetricsValue) (float64, string) {
+		return value.Timestamp, value.Value
+	})
+}
+
+// LoadBalancerSeries converts the time series of the load balancer metrics, keyed by
+// their name, e.g. `open_connections`.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func LoadBalancerSeries(metrics *hcloud.LoadBalancerMetrics) (map[string]*Series, error) {
+	return convertSeries(metrics.TimeSeries, metrics.Step, func(value hcloud.LoadBalancerMetricsValue) (float64, string) {
+		return value.Timestamp, value.Value
+	})
+}
+
+// convertSeries converts the time series of the metrics, the unpack function returns
+// the timestamp and the raw value of a metrics value.
+func convertSeries[V any](timeSeries map[string][]V, step float64, unpack func(V) (float64, string)) (map[string]*Series, error) {
+	result := make(map[string]*Series, len(timeSeries))
+	for name, values := range timeSeries {
+		series := newSeries(name, step, len(values))
+		for _, value := range values {
+			point, err := parsePoint(unpack(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of series %s: %w", name, err)
+			}
+			series.Points = append(series.Points, point)
+		}
+		result[name] = series
+	}
+	return result, nil
+}
+
+func newSeries(name string, step float64, size int) *Series {
+	return &Series{
+		Name:   name,
+		Step:   time.Duration(step * float64(time.Second)),
+		Points: make([]Point, 0, size),
+	}
+}
+
+func parsePoint(timestamp float64, value string) (Point, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return Point{}, err
+	}
+	sec, frac := math.Modf(timestamp)
+	return Point{
+		Time:  time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(),
+		Value: v,
+	}, nil
+}
+
+// Last returns the last point of the series with a value, and false if there is none.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (s *Series) Last() (Point, bool) {
+	for i := len(s.Points) - 1; i >= 0; i-- {
+		if !math.IsNaN(s.Points[i].Value) {
+			return s.Points[i], true
+		}
+	}
+	return Point{}, false
+}
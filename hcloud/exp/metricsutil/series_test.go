@@ -0,0 +1,62 @@
+package metricsutil
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestServerSeries(t *testing.T) {
+	metrics := &hcloud.ServerMetrics{
+		Step: 60,
+		TimeSeries: map[string][]hcloud.ServerMetricsValue{
+			"cpu": {
+				{Timestamp: 1435781470.622, Value: "42"},
+				{Timestamp: 1435781530, Value: "NaN"},
+			},
+		},
+	}
+
+	series, err := ServerSeries(metrics)
+	require.NoError(t, err)
+	require.Contains(t, series, "cpu")
+
+	cpu := series["cpu"]
+	assert.Equal(t, "cpu", cpu.Name)
+	assert.Equal(t, time.Minute, cpu.Step)
+	require.Len(t, cpu.Points, 2)
+	assert.Equal(t, time.Unix(1435781470, 622000000).UTC(), cpu.Points[0].Time.Round(time.Millisecond))
+	assert.InDelta(t, 42.0, cpu.Points[0].Value, 0)
+	assert.True(t, math.IsNaN(cpu.Points[1].Value))
+
+	last, ok := cpu.Last()
+	assert.True(t, ok)
+	assert.InDelta(t, 42.0, last.Value, 0)
+
+	metrics.TimeSeries["cpu"][0].Value = "invalid"
+	_, err = ServerSeries(metrics)
+	assert.ErrorContains(t, err, "invalid value of series cpu: ")
+}
+
+func TestLoadBalancerSeries(t *testing.T) {
+	metrics := &hcloud.LoadBalancerMetrics{
+		Step: 10,
+		TimeSeries: map[string][]hcloud.LoadBalancerMetricsValue{
+			"open_connections": {
+				{Timestamp: 1435781470, Value: "10"},
+				{Timestamp: 1435781480, Value: "20"},
+			},
+		},
+	}
+
+	series, err := LoadBalancerSeries(metrics)
+	require.NoError(t, err)
+	require.Contains(t, series, "open_connections")
+	assert.Equal(t, 10*time.Second, series["open_connections"].Step)
+	assert.Len(t, series["open_connections"].Points, 2)
+}
@@ -0,0 +1,304 @@
+// Package networkutil validates the routes of a Network and converges them to a
+// desired list of routes.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package networkutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// publicGateway is the gateway of the public network interface of the servers, it can
+// not be used in routes.
+var publicGateway = net.IPv4(172, 31, 1, 1)
+
+// RouteError is a validation error of a route.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type RouteError struct {
+	Route hcloud.NetworkRoute
+	Err   error
+}
+
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("invalid route %s: %s", routeKey(e.Route), e.Err)
+}
+
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateRoutes validates the routes against the subnets of the network. All the
+// invalid routes are reported, each as a [RouteError].
+//
+// A route is valid if its gateway is in a cloud or server subnet of the network, and
+// if its destination does not overlap with the subnets of the network or with the
+// destination of another route. The first IP of the network and the public gateway
+// 172.31.1.1 can not be used. When the routes are exposed to the vSwitch, the
+// destination must be outside of the IP range of the network.
+//
+// The default route 0.0.0.0/0, used to route the traffic through a NAT gateway, is
+// exempt from the destination checks, only its gateway is validated.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ValidateRoutes(network *hcloud.Network, routes []hcloud.NetworkRoute) error {
+	var networkGateway net.IP
+	if network.IPRange != nil {
+		networkGateway = firstIP(network.IPRange)
+	}
+
+	errs := []error{}
+	for i, route := range routes {
+		if err := validateRoute(network, networkGateway, route); err != nil {
+			errs = append(errs, &RouteError{Route: route, Err: err})
+			continue
+		}
+		for _, other := range routes[:i] {
+			if other.Destination == nil || isDefaultRoute(route) != isDefaultRoute(other) {
+				// The more specific routes take precedence over the default route.
+				continue
+			}
+			if overlaps(route.Destination, other.Destination) {
+				errs = append(errs, &RouteError{Route: route, Err: fmt.Errorf("destination overlaps with route %s", routeKey(other))})
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func validateRoute(network *hcloud.Network, networkGateway net.IP, route hcloud.NetworkRoute) error {
+	if route.Destination == nil {
+		return errors.New("missing destination")
+	}
+	if route.Gateway == nil {
+		return errors.New("missing gateway")
+	}
+	if route.Destination.IP.To4() == nil || route.Gateway.To4() == nil {
+		return errors.New("only IPv4 is supported")
+	}
+
+	if route.Gateway.Equal(networkGateway) || route.Gateway.Equal(publicGateway) {
+		return fmt.Errorf("gateway %s is reserved", route.Gateway)
+	}
+	if isDefaultRoute(route) {
+		// The default route contains every IP, only its gateway is validated.
+		return validateGateway(network, route)
+	}
+	if route.Destination.Contains(networkGateway) || route.Destination.Contains(publicGateway) {
+		return errors.New("destination contains a reserved ip")
+	}
+	if network.ExposeRoutesToVSwitch && network.IPRange != nil && overlaps(route.Destination, network.IPRange) {
+		return fmt.Errorf("destination overlaps with the ip range %s of the network, routes are exposed to the vSwitch", network.IPRange)
+	}
+
+	for _, subnet := range network.Subnets {
+		if subnet.IPRange != nil && overlaps(route.Destination, subnet.IPRange) {
+			return fmt.Errorf("destination overlaps with subnet %s", subnet.IPRange)
+		}
+	}
+	return validateGateway(network, route)
+}
+
+// validateGateway validates that the gateway of the route is in a cloud or server
+// subnet of the network.
+func validateGateway(network *hcloud.Network, route hcloud.NetworkRoute) error {
+	inSubnet := false
+	for _, subnet := range network.Subnets {
+		if subnet.IPRange == nil {
+			continue
+		}
+		if subnet.IPRange.Contains(route.Gateway) {
+			if subnet.Type == hcloud.NetworkSubnetTypeVSwitch {
+				return fmt.Errorf("gateway is behind the vSwitch subnet %s", subnet.IPRange)
+			}
+			inSubnet = true
+		}
+	}
+	if !inSubnet {
+		return errors.New("gateway is not in a subnet of the network")
+	}
+	return nil
+}
+
+// isDefaultRoute reports whether the destination of the route is 0.0.0.0/0.
+func isDefaultRoute(route hcloud.NetworkRoute) bool {
+	if route.Destination == nil {
+		return false
+	}
+	ones, _ := route.Destination.Mask.Size()
+	return ones == 0
+}
+
+// RoutePlan is the list of changes required to converge the routes of a
+// [hcloud.Network] to the desired routes.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type RoutePlan struct {
+	Network *hcloud.Network
+
+	// Delete holds the routes to delete, they are deleted before the routes are added.
+	Delete []hcloud.NetworkRoute
+	Add    []hcloud.NetworkRoute
+}
+
+// Empty reports whether the routes of the Network are already in the desired state.
+func (p RoutePlan) Empty() bool {
+	return len(p.Delete) == 0 && len(p.Add) == 0
+}
+
+// DiffRoutes computes the [RoutePlan] to converge the routes of the network to the
+// desired routes. The order of the routes is not significant.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func DiffRoutes(network *hcloud.Network, desired []hcloud.NetworkRoute) RoutePlan {
+	plan := RoutePlan{Network: network}
+
+	current := make(map[string]bool, len(network.Routes))
+	for _, route := range network.Routes {
+		current[routeKey(route)] = true
+	}
+	wanted := make(map[string]bool, len(desired))
+	for _, route := range desired {
+		wanted[routeKey(route)] = true
+	}
+
+	for _, route := range network.Routes {
+		if !wanted[routeKey(route)] {
+			plan.Delete = append(plan.Delete, route)
+		}
+	}
+	for _, route := range desired {
+		key := routeKey(route)
+		if !current[key] {
+			plan.Add = append(plan.Add, route)
+			current[key] = true
+		}
+	}
+	return plan
+}
+
+// RouteOperation is the type of a [RouteResult].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type RouteOperation string
+
+const (
+	RouteOperationAdd    RouteOperation = "add"
+	RouteOperationDelete RouteOperation = "delete"
+)
+
+// RouteResult is the result of an operation of a [RoutePlan].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type RouteResult struct {
+	Operation RouteOperation
+	Route     hcloud.NetworkRoute
+	Err       error
+}
+
+// RouteReport describes the changes done by [ReconcileRoutes].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type RouteReport struct {
+	Plan RoutePlan
+	// Results holds the result of each applied operation, in order. The operations
+	// following a failed operation are not applied.
+	Results []RouteResult
+}
+
+// ReconcileRoutesOpts specifies options for [ReconcileRoutes].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type ReconcileRoutesOpts struct {
+	// DryRun only validates the routes and computes the plan, without applying it.
+	DryRun bool
+}
+
+// ReconcileRoutes fetches the current state of the network, validates the desired
+// routes, computes the [RoutePlan] to converge the routes of the network and applies
+// the plan unless [ReconcileRoutesOpts.DryRun] is set.
+//
+// The operations are applied sequentially, waiting for the action of each operation,
+// as the network is locked during an action.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ReconcileRoutes(ctx context.Context, client *hcloud.Client, network *hcloud.Network, desired []hcloud.NetworkRoute, opts ReconcileRoutesOpts) (*RouteReport, error) {
+	current, _, err := client.Network.GetByID(ctx, network.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("network not found: %d", network.ID)
+	}
+
+	if err := ValidateRoutes(current, desired); err != nil {
+		return nil, err
+	}
+
+	report := &RouteReport{Plan: DiffRoutes(current, desired)}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	apply := func(operation RouteOperation, route hcloud.NetworkRoute) error {
+		var action *hcloud.Action
+		var err error
+		switch operation {
+		case RouteOperationDelete:
+			action, _, err = client.Network.DeleteRoute(ctx, current, hcloud.NetworkDeleteRouteOpts{Route: route})
+		case RouteOperationAdd:
+			action, _, err = client.Network.AddRoute(ctx, current, hcloud.NetworkAddRouteOpts{Route: route})
+		}
+		if err == nil {
+			err = client.Action.WaitFor(ctx, action)
+		}
+		report.Results = append(report.Results, RouteResult{Operation: operation, Route: route, Err: err})
+		if err != nil {
+			return fmt.Errorf("could not %s route %s: %w", operation, routeKey(route), err)
+		}
+		return nil
+	}
+
+	for _, route := range report.Plan.Delete {
+		if err := apply(RouteOperationDelete, route); err != nil {
+			return report, err
+		}
+	}
+	for _, route := range report.Plan.Add {
+		if err := apply(RouteOperationAdd, route); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// routeKey returns a canonical representation of the route.
+func routeKey(route hcloud.NetworkRoute) string {
+	destination := "<nil>"
+	if route.Destination != nil {
+		destination = route.Destination.String()
+	}
+	return destination + " via " + route.Gateway.String()
+}
+
+func overlaps(a, b *net.IPNet) bool {
+	return a.Contains(b.IP) || b.Contains(a.IP)
+}
+
+// firstIP returns the first host IP of the IP range.
+func firstIP(ipRange *net.IPNet) net.IP {
+	ip := ipRange.IP.Mask(ipRange.Mask).To4()
+	if ip == nil {
+		return nil
+	}
+	result := make(net.IP, len(ip))
+	copy(result, ip)
+	result[len(result)-1]++
+	return result
+}
@@ -0,0 +1,172 @@
+package networkutil
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func mustParseCIDR(t *testing.T, value string) *net.IPNet {
+	t.Helper()
+
+	_, ipNet, err := net.ParseCIDR(value)
+	require.NoError(t, err)
+	return ipNet
+}
+
+func route(t *testing.T, destination, gateway string) hcloud.NetworkRoute {
+	t.Helper()
+
+	return hcloud.NetworkRoute{Destination: mustParseCIDR(t, destination), Gateway: net.ParseIP(gateway)}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	network := &hcloud.Network{
+		ID:      1,
+		IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+		Subnets: []hcloud.NetworkSubnet{
+			{Type: hcloud.NetworkSubnetTypeCloud, IPRange: mustParseCIDR(t, "10.0.1.0/24")},
+			{Type: hcloud.NetworkSubnetTypeVSwitch, IPRange: mustParseCIDR(t, "10.0.2.0/24")},
+		},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, ValidateRoutes(network, []hcloud.NetworkRoute{
+			route(t, "10.100.0.0/24", "10.0.1.10"),
+			route(t, "10.0.3.0/24", "10.0.1.10"),
+			route(t, "192.168.0.0/16", "10.0.1.11"),
+		}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		testCases := []struct {
+			name  string
+			route hcloud.NetworkRoute
+			err   string
+		}{
+			{"missing destination", hcloud.NetworkRoute{Gateway: net.ParseIP("10.0.1.10")}, "invalid route <nil> via 10.0.1.10: missing destination"},
+			{"missing gateway", hcloud.NetworkRoute{Destination: mustParseCIDR(t, "10.100.0.0/24")}, "invalid route 10.100.0.0/24 via <nil>: missing gateway"},
+			{"network gateway", route(t, "10.100.0.0/24", "10.0.0.1"), "invalid route 10.100.0.0/24 via 10.0.0.1: gateway 10.0.0.1 is reserved"},
+			{"public gateway", route(t, "10.100.0.0/24", "172.31.1.1"), "invalid route 10.100.0.0/24 via 172.31.1.1: gateway 172.31.1.1 is reserved"},
+			{"reserved destination", route(t, "172.31.0.0/16", "10.0.1.10"), "invalid route 172.31.0.0/16 via 10.0.1.10: destination contains a reserved ip"},
+			{"subnet destination", route(t, "10.0.1.128/25", "10.0.1.10"), "invalid route 10.0.1.128/25 via 10.0.1.10: destination overlaps with subnet 10.0.1.0/24"},
+			{"vswitch gateway", route(t, "10.100.0.0/24", "10.0.2.10"), "invalid route 10.100.0.0/24 via 10.0.2.10: gateway is behind the vSwitch subnet 10.0.2.0/24"},
+			{"gateway outside subnets", route(t, "10.100.0.0/24", "10.0.5.10"), "invalid route 10.100.0.0/24 via 10.0.5.10: gateway is not in a subnet of the network"},
+		}
+		for _, tt := range testCases {
+			t.Run(tt.name, func(t *testing.T) {
+				err := ValidateRoutes(network, []hcloud.NetworkRoute{tt.route})
+				assert.EqualError(t, err, tt.err)
+
+				var routeErr *RouteError
+				assert.ErrorAs(t, err, &routeErr)
+			})
+		}
+	})
+
+	t.Run("overlapping destinations", func(t *testing.T) {
+		err := ValidateRoutes(network, []hcloud.NetworkRoute{
+			route(t, "10.100.0.0/16", "10.0.1.10"),
+			route(t, "10.100.5.0/24", "10.0.1.11"),
+			route(t, "192.168.0.0/16", "10.0.5.10"),
+		})
+		assert.EqualError(t, err, "invalid route 10.100.5.0/24 via 10.0.1.11: destination overlaps with route 10.100.0.0/16 via 10.0.1.10\n"+
+			"invalid route 192.168.0.0/16 via 10.0.5.10: gateway is not in a subnet of the network")
+	})
+
+	t.Run("default route", func(t *testing.T) {
+		assert.NoError(t, ValidateRoutes(network, []hcloud.NetworkRoute{
+			route(t, "0.0.0.0/0", "10.0.1.2"),
+			route(t, "10.100.0.0/24", "10.0.1.10"),
+		}))
+
+		exposed := *network
+		exposed.ExposeRoutesToVSwitch = true
+		assert.NoError(t, ValidateRoutes(&exposed, []hcloud.NetworkRoute{route(t, "0.0.0.0/0", "10.0.1.2")}))
+
+		err := ValidateRoutes(network, []hcloud.NetworkRoute{
+			route(t, "0.0.0.0/0", "10.0.1.2"),
+			route(t, "0.0.0.0/0", "10.0.1.3"),
+			route(t, "0.0.0.0/0", "10.0.5.2"),
+		})
+		assert.EqualError(t, err, "invalid route 0.0.0.0/0 via 10.0.1.3: destination overlaps with route 0.0.0.0/0 via 10.0.1.2\n"+
+			"invalid route 0.0.0.0/0 via 10.0.5.2: gateway is not in a subnet of the network")
+	})
+
+	t.Run("exposed to vswitch", func(t *testing.T) {
+		exposed := *network
+		exposed.ExposeRoutesToVSwitch = true
+
+		err := ValidateRoutes(&exposed, []hcloud.NetworkRoute{route(t, "10.0.3.0/24", "10.0.1.10")})
+		assert.EqualError(t, err, "invalid route 10.0.3.0/24 via 10.0.1.10: destination overlaps with the ip range 10.0.0.0/16 of the network, routes are exposed to the vSwitch")
+		assert.NoError(t, ValidateRoutes(&exposed, []hcloud.NetworkRoute{route(t, "10.100.0.0/24", "10.0.1.10")}))
+	})
+}
+
+func TestDiffRoutes(t *testing.T) {
+	network := &hcloud.Network{
+		ID: 1,
+		Routes: []hcloud.NetworkRoute{
+			route(t, "10.100.0.0/24", "10.0.1.10"),
+			route(t, "10.101.0.0/24", "10.0.1.10"),
+			route(t, "10.102.0.0/24", "10.0.1.10"),
+		},
+	}
+
+	plan := DiffRoutes(network, []hcloud.NetworkRoute{
+		route(t, "10.102.0.0/24", "10.0.1.10"),
+		route(t, "10.101.0.0/24", "10.0.1.11"),
+		route(t, "10.103.0.0/24", "10.0.1.10"),
+	})
+	assert.False(t, plan.Empty())
+	assert.Equal(t, []hcloud.NetworkRoute{route(t, "10.100.0.0/24", "10.0.1.10"), route(t, "10.101.0.0/24", "10.0.1.10")}, plan.Delete)
+	assert.Equal(t, []hcloud.NetworkRoute{route(t, "10.101.0.0/24", "10.0.1.11"), route(t, "10.103.0.0/24", "10.0.1.10")}, plan.Add)
+
+	assert.True(t, DiffRoutes(network, network.Routes).Empty())
+}
+
+func TestReconcileRoutes(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	network, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+		Name:    "network",
+		IPRange: mustParseCIDR(t, "10.0.0.0/16"),
+		Subnets: []hcloud.NetworkSubnet{{Type: hcloud.NetworkSubnetTypeCloud, IPRange: mustParseCIDR(t, "10.0.1.0/24"), NetworkZone: hcloud.NetworkZoneEUCentral}},
+		Routes:  []hcloud.NetworkRoute{route(t, "10.100.0.0/24", "10.0.1.10"), route(t, "10.101.0.0/24", "10.0.1.10")},
+	})
+	require.NoError(t, err)
+
+	desired := []hcloud.NetworkRoute{route(t, "10.101.0.0/24", "10.0.1.10"), route(t, "10.102.0.0/24", "10.0.1.11")}
+
+	report, err := ReconcileRoutes(ctx, client, network, desired, ReconcileRoutesOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, report.Plan.Delete, 1)
+	assert.Len(t, report.Plan.Add, 1)
+	assert.Empty(t, report.Results)
+
+	report, err = ReconcileRoutes(ctx, client, network, desired, ReconcileRoutesOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, []RouteResult{
+		{Operation: RouteOperationDelete, Route: route(t, "10.100.0.0/24", "10.0.1.10")},
+		{Operation: RouteOperationAdd, Route: route(t, "10.102.0.0/24", "10.0.1.11")},
+	}, report.Results)
+
+	network, _, err = client.Network.GetByID(ctx, network.ID)
+	require.NoError(t, err)
+	assert.True(t, DiffRoutes(network, desired).Empty())
+
+	report, err = ReconcileRoutes(ctx, client, network, desired, ReconcileRoutesOpts{})
+	require.NoError(t, err)
+	assert.True(t, report.Plan.Empty())
+
+	_, err = ReconcileRoutes(ctx, client, network, []hcloud.NetworkRoute{route(t, "10.100.0.0/24", "10.0.9.10")}, ReconcileRoutesOpts{})
+	var routeErr *RouteError
+	assert.ErrorAs(t, err, &routeErr)
+}
@@ -0,0 +1,231 @@
+// Package serverutil orchestrates multi-step Server lifecycle operations, waiting for
+// the actions and the server status of every step.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package serverutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/actionutil"
+)
+
+// DefaultShutdownTimeout is the duration given to a server to shut down gracefully,
+// before it is powered off.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const DefaultShutdownTimeout = time.Minute
+
+// DefaultRestoreTimeout is the maximum duration to restore the state of a server after
+// a failed operation.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+const DefaultRestoreTimeout = 10 * time.Minute
+
+// Opts defines the options of the lifecycle operations.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Opts struct {
+	// BackoffFunc is used between the polls of the server status, defaults to a
+	// constant backoff of one second.
+	BackoffFunc hcloud.BackoffFunc
+	// Progress is called every time an awaited action is updated, the step is
+	// identified by the [hcloud.Action.Command] (e.g. create_server, shutdown_server).
+	Progress func(update *hcloud.Action)
+	// ShutdownTimeout is the duration given to a server to shut down gracefully,
+	// defaults to [DefaultShutdownTimeout].
+	ShutdownTimeout time.Duration
+	// RestoreTimeout is the maximum duration to restore the state of a server after a
+	// failed operation, defaults to [DefaultRestoreTimeout].
+	RestoreTimeout time.Duration
+}
+
+func (o Opts) backoff(retries int) time.Duration {
+	if o.BackoffFunc == nil {
+		return time.Second
+	}
+	return o.BackoffFunc(retries)
+}
+
+func (o Opts) shutdownTimeout() time.Duration {
+	if o.ShutdownTimeout <= 0 {
+		return DefaultShutdownTimeout
+	}
+	return o.ShutdownTimeout
+}
+
+func (o Opts) restoreTimeout() time.Duration {
+	if o.RestoreTimeout <= 0 {
+		return DefaultRestoreTimeout
+	}
+	return o.RestoreTimeout
+}
+
+// CreateAndWait creates a server, waits for its create and next actions, and returns
+// the server once it reached its final status ([hcloud.ServerStatusRunning], or
+// [hcloud.ServerStatusOff] when [hcloud.ServerCreateOpts.StartAfterCreate] is false).
+//
+// The [hcloud.ServerCreateResult] is returned as well, for example to read the root
+// password.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func CreateAndWait(ctx context.Context, client *hcloud.Client, createOpts hcloud.ServerCreateOpts, opts Opts) (*hcloud.Server, hcloud.ServerCreateResult, error) {
+	result, _, err := client.Server.Create(ctx, createOpts)
+	if err != nil {
+		return nil, result, err
+	}
+
+	if err := waitForActions(ctx, client, opts, actionutil.AppendNext(result.Action, result.NextActions)...); err != nil {
+		return nil, result, err
+	}
+
+	status := hcloud.ServerStatusRunning
+	if createOpts.StartAfterCreate != nil && !*createOpts.StartAfterCreate {
+		status = hcloud.ServerStatusOff
+	}
+
+	server, err := waitForStatus(ctx, client, result.Server, status, opts)
+	if err != nil {
+		return nil, result, err
+	}
+	return server, result, nil
+}
+
+// ShutdownGracefully shuts down a server, and waits until its status is
+// [hcloud.ServerStatusOff]. If the server does not shut down within the
+// [Opts.ShutdownTimeout], for example because the operating system ignores the ACPI
+// signal, the server is powered off.
+//
+// Reports whether the server had to be powered off.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ShutdownGracefully(ctx context.Context, client *hcloud.Client, server *hcloud.Server, opts Opts) (*hcloud.Server, bool, error) {
+	action, _, err := client.Server.Shutdown(ctx, server)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := waitForActions(ctx, client, opts, action); err != nil {
+		return nil, false, err
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, opts.shutdownTimeout())
+	defer cancel()
+
+	result, err := waitForStatus(shutdownCtx, client, server, hcloud.ServerStatusOff, opts)
+	if err == nil {
+		return result, false, nil
+	}
+	if ctx.Err() != nil || !errors.Is(err, context.DeadlineExceeded) {
+		return nil, false, err
+	}
+
+	// The graceful shutdown timed out.
+	action, _, err = client.Server.Poweroff(ctx, server)
+	if err != nil {
+		return nil, true, err
+	}
+	if err := waitForActions(ctx, client, opts, action); err != nil {
+		return nil, true, err
+	}
+
+	result, err = waitForStatus(ctx, client, server, hcloud.ServerStatusOff, opts)
+	return result, true, err
+}
+
+// ChangeTypeSafely changes the type of a server. A running server is shut down
+// gracefully (see [ShutdownGracefully]) before the change, and powered on after the
+// change, restoring its previous state. If the change fails, a running server is
+// powered on as well, even if the context is done, within the [Opts.RestoreTimeout].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func ChangeTypeSafely(ctx context.Context, client *hcloud.Client, server *hcloud.Server, changeOpts hcloud.ServerChangeTypeOpts, opts Opts) (*hcloud.Server, error) {
+	current, _, err := client.Server.GetByID(ctx, server.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("server not found: %d", server.ID)
+	}
+
+	wasOff := current.Status == hcloud.ServerStatusOff
+	if !wasOff {
+		if _, _, err := ShutdownGracefully(ctx, client, current, opts); err != nil {
+			return nil, fmt.Errorf("could not stop server: %w", err)
+		}
+	}
+
+	action, _, err := client.Server.ChangeType(ctx, current, changeOpts)
+	if err == nil {
+		err = waitForActions(ctx, client, opts, action)
+	}
+	if err != nil {
+		if !wasOff {
+			// Restore the previous state of the server.
+			restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.restoreTimeout())
+			defer cancel()
+
+			_, startErr := startServer(restoreCtx, client, current, opts)
+			err = errors.Join(err, startErr)
+		}
+		return nil, err
+	}
+
+	if wasOff {
+		return waitForStatus(ctx, client, current, hcloud.ServerStatusOff, opts)
+	}
+	return startServer(ctx, client, current, opts)
+}
+
+// startServer powers on the server, and waits until it is running.
+func startServer(ctx context.Context, client *hcloud.Client, server *hcloud.Server, opts Opts) (*hcloud.Server, error) {
+	action, _, err := client.Server.Poweron(ctx, server)
+	if err != nil {
+		return nil, fmt.Errorf("could not start server: %w", err)
+	}
+	if err := waitForActions(ctx, client, opts, action); err != nil {
+		return nil, fmt.Errorf("could not start server: %w", err)
+	}
+	return waitForStatus(ctx, client, server, hcloud.ServerStatusRunning, opts)
+}
+
+// waitForActions waits until all actions succeed, and reports their updates to the
+// progress callback.
+func waitForActions(ctx context.Context, client *hcloud.Client, opts Opts, actions ...*hcloud.Action) error {
+	return client.Action.WaitForFunc(ctx, func(update *hcloud.Action) error {
+		if opts.Progress != nil {
+			opts.Progress(update)
+		}
+		if update.Status == hcloud.ActionStatusError {
+			return update.Error()
+		}
+		return nil
+	}, actions...)
+}
+
+// waitForStatus polls the server until it has the given status.
+func waitForStatus(ctx context.Context, client *hcloud.Client, server *hcloud.Server, status hcloud.ServerStatus, opts Opts) (*hcloud.Server, error) {
+	retries := 0
+	for {
+		result, _, err := client.Server.GetByID(ctx, server.ID)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return nil, fmt.Errorf("server not found: %d", server.ID)
+		}
+		if result.Status == status {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: server status is %s, expected %s", ctx.Err(), result.Status, status)
+		case <-time.After(opts.backoff(retries)):
+			retries++
+		}
+	}
+}
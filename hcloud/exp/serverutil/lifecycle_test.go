@@ -0,0 +1,169 @@
+package serverutil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/mockutil"
+)
+
+var testOpts = Opts{BackoffFunc: hcloud.ConstantBackoff(time.Millisecond)}
+
+func TestCreateAndWait(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	commands := []string{}
+	opts := testOpts
+	opts.Progress = func(update *hcloud.Action) { commands = append(commands, update.Command) }
+
+	server, result, err := CreateAndWait(ctx, client, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.ServerStatusRunning, server.Status)
+	assert.Equal(t, result.Server.ID, server.ID)
+	assert.NotEmpty(t, result.RootPassword)
+	assert.Contains(t, commands, "create_server")
+
+	stopped, _, err := CreateAndWait(ctx, client, hcloud.ServerCreateOpts{
+		Name:             "stopped",
+		ServerType:       &hcloud.ServerType{Name: "cpx22"},
+		Image:            &hcloud.Image{Name: "debian-13"},
+		StartAfterCreate: hcloud.Ptr(false),
+	}, testOpts)
+	require.NoError(t, err)
+	assert.Equal(t, hcloud.ServerStatusOff, stopped.Status)
+
+	_, _, err = CreateAndWait(ctx, client, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	}, testOpts)
+	require.True(t, hcloud.IsError(err, hcloud.ErrorCodeUniquenessError))
+}
+
+func TestChangeTypeSafely(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	server, _, err := CreateAndWait(ctx, client, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	}, testOpts)
+	require.NoError(t, err)
+
+	commands := []string{}
+	opts := testOpts
+	opts.Progress = func(update *hcloud.Action) {
+		if update.Status != hcloud.ActionStatusRunning {
+			commands = append(commands, update.Command)
+		}
+	}
+
+	server, err = ChangeTypeSafely(ctx, client, server, hcloud.ServerChangeTypeOpts{ServerType: &hcloud.ServerType{Name: "cpx32"}}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "cpx32", server.ServerType.Name)
+	assert.Equal(t, hcloud.ServerStatusRunning, server.Status)
+	assert.Equal(t, []string{"shutdown_server", "change_server_type", "start_server"}, commands)
+
+	// A stopped server stays stopped
+	_, _, err = ShutdownGracefully(ctx, client, server, testOpts)
+	require.NoError(t, err)
+
+	server, err = ChangeTypeSafely(ctx, client, server, hcloud.ServerChangeTypeOpts{ServerType: &hcloud.ServerType{Name: "cpx22"}}, testOpts)
+	require.NoError(t, err)
+	assert.Equal(t, "cpx22", server.ServerType.Name)
+	assert.Equal(t, hcloud.ServerStatusOff, server.Status)
+}
+
+func TestChangeTypeSafelyRestoresState(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	server, _, err := CreateAndWait(ctx, client, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	}, testOpts)
+	require.NoError(t, err)
+
+	// The server type is missing, the change fails.
+	_, err = ChangeTypeSafely(ctx, client, server, hcloud.ServerChangeTypeOpts{ServerType: &hcloud.ServerType{}}, testOpts)
+	require.Error(t, err)
+	assert.Equal(t, hcloud.ErrorCategoryInvalidInput, hcloud.ErrorCategoryOf(err))
+
+	server, _, err = client.Server.GetByID(ctx, server.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "cpx22", server.ServerType.Name)
+	assert.Equal(t, hcloud.ServerStatusRunning, server.Status)
+}
+
+func TestChangeTypeSafelyRestoreTimeout(t *testing.T) {
+	// The server is never powered on.
+	client := fakeapi.NewClient(t, fakeapi.WithHandlerFunc("POST /servers/1/actions/poweron", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"action":{"id":1000,"command":"start_server","status":"success"}}`))
+	}))
+	ctx := context.Background()
+
+	server, _, err := CreateAndWait(ctx, client, hcloud.ServerCreateOpts{
+		Name:       "server",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+	}, testOpts)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), server.ID)
+
+	opts := testOpts
+	opts.RestoreTimeout = 20 * time.Millisecond
+
+	_, err = ChangeTypeSafely(ctx, client, server, hcloud.ServerChangeTypeOpts{ServerType: &hcloud.ServerType{}}, opts)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, hcloud.ErrorCategoryInvalidInput, hcloud.ErrorCategoryOf(err))
+}
+
+func TestShutdownGracefullyFallback(t *testing.T) {
+	server := mockutil.NewServer(t, []mockutil.Request{
+		{
+			Method: "POST", Path: "/servers/1/actions/shutdown",
+			Status:  201,
+			JSONRaw: `{"action":{"id":1,"command":"shutdown_server","status":"success"}}`,
+		},
+		{
+			Method: "GET", Path: "/servers/1",
+			Status:  200,
+			JSONRaw: `{"server":{"id":1,"status":"running"}}`,
+		},
+		{
+			Method: "POST", Path: "/servers/1/actions/poweroff",
+			Status:  201,
+			JSONRaw: `{"action":{"id":2,"command":"stop_server","status":"success"}}`,
+		},
+		{
+			Method: "GET", Path: "/servers/1",
+			Status:  200,
+			JSONRaw: `{"server":{"id":1,"status":"off"}}`,
+		},
+	})
+	client := hcloud.NewClient(hcloud.WithEndpoint(server.URL), hcloud.WithToken("token"))
+
+	// The next poll of the server status exceeds the shutdown timeout.
+	opts := Opts{BackoffFunc: hcloud.ConstantBackoff(time.Minute), ShutdownTimeout: 10 * time.Millisecond}
+
+	result, forced, err := ShutdownGracefully(context.Background(), client, &hcloud.Server{ID: 1}, opts)
+	require.NoError(t, err)
+	assert.True(t, forced)
+	assert.Equal(t, hcloud.ServerStatusOff, result.Status)
+}
@@ -0,0 +1,176 @@
+package teardownutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// ExecuteOpts defines the options of [Execute].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type ExecuteOpts struct {
+	// DryRun reports the steps to the Progress callback without executing them.
+	DryRun bool
+	// Parallelism is the maximum number of steps executed concurrently, defaults to 1.
+	Parallelism int
+	// Progress is called after each step, with the error of the step.
+	Progress func(step *Step, err error)
+}
+
+// Execute runs the steps of the plan, phase after phase, and waits for the actions
+// of every step. The execution stops after the first phase with a failed step.
+//
+// Deleting a resource that no longer exists is not an error, for example a primary
+// IP deleted together with its server.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func Execute(ctx context.Context, client *hcloud.Client, plan *Plan, opts ExecuteOpts) error {
+	progress := func(step *Step, err error) {
+		if opts.Progress != nil {
+			opts.Progress(step, err)
+		}
+	}
+
+	if opts.DryRun {
+		for _, step := range plan.Steps() {
+			progress(step, nil)
+		}
+		return nil
+	}
+
+	parallelism := max(opts.Parallelism, 1)
+
+	for _, phase := range plan.Phases {
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			errs []error
+		)
+		sem := make(chan struct{}, parallelism)
+
+		for _, step := range phase {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return errors.Join(append(errs, ctx.Err())...)
+			}
+
+			wg.Go(func() {
+				defer func() { <-sem }()
+
+				err := executeStep(ctx, client, step)
+				if err != nil {
+					err = fmt.Errorf("%s: %w", step, err)
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+				progress(step, err)
+			})
+		}
+		wg.Wait()
+
+		if len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+	}
+	return nil
+}
+
+func executeStep(ctx context.Context, client *hcloud.Client, step *Step) error {
+	var action *hcloud.Action
+	var err error
+
+	switch step.Type {
+	case StepTypeDetachVolume:
+		action, _, err = client.Volume.Detach(ctx, &hcloud.Volume{ID: step.Resource.ID})
+	case StepTypeRemoveTarget:
+		action, _, err = client.LoadBalancer.RemoveServerTarget(ctx,
+			&hcloud.LoadBalancer{ID: step.Resource.ID},
+			&hcloud.Server{ID: step.Related.ID},
+		)
+	case StepTypeRemoveFirewall:
+		resource := hcloud.FirewallResource{
+			Type:          hcloud.FirewallResourceTypeLabelSelector,
+			LabelSelector: &hcloud.FirewallResourceLabelSelector{Selector: step.LabelSelector},
+		}
+		if step.Related != nil {
+			resource = hcloud.FirewallResource{
+				Type:   hcloud.FirewallResourceTypeServer,
+				Server: &hcloud.FirewallResourceServer{ID: step.Related.ID},
+			}
+		}
+		var actions []*hcloud.Action
+		actions, _, err = client.Firewall.RemoveResources(ctx, &hcloud.Firewall{ID: step.Resource.ID}, []hcloud.FirewallResource{resource})
+		if err != nil {
+			return err
+		}
+		return client.Action.WaitFor(ctx, actions...)
+	case StepTypeUnassignPrimaryIP:
+		action, _, err = client.PrimaryIP.Unassign(ctx, step.Resource.ID)
+	case StepTypeDetachNetwork:
+		network := &hcloud.Network{ID: step.Related.ID}
+		if step.Resource.Type == ResourceTypeLoadBalancer {
+			action, _, err = client.LoadBalancer.DetachFromNetwork(ctx,
+				&hcloud.LoadBalancer{ID: step.Resource.ID},
+				hcloud.LoadBalancerDetachFromNetworkOpts{Network: network},
+			)
+		} else {
+			action, _, err = client.Server.DetachFromNetwork(ctx,
+				&hcloud.Server{ID: step.Resource.ID},
+				hcloud.ServerDetachFromNetworkOpts{Network: network},
+			)
+		}
+	case StepTypeRemoveFromPlacementGroup:
+		action, _, err = client.Server.RemoveFromPlacementGroup(ctx, &hcloud.Server{ID: step.Resource.ID})
+	case StepTypeDelete:
+		action, err = deleteResource(ctx, client, step.Resource)
+		if hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown step type: %s", step.Type)
+	}
+	if err != nil {
+		return err
+	}
+	if action == nil {
+		return nil
+	}
+	return client.Action.WaitFor(ctx, action)
+}
+
+// deleteResource deletes the resource and returns the delete action, if any.
+func deleteResource(ctx context.Context, client *hcloud.Client, resource *Resource) (*hcloud.Action, error) {
+	var err error
+
+	switch resource.Type {
+	case ResourceTypeServer:
+		var result *hcloud.ServerDeleteResult
+		result, _, err = client.Server.DeleteWithResult(ctx, &hcloud.Server{ID: resource.ID})
+		if err != nil {
+			return nil, err
+		}
+		return result.Action, nil
+	case ResourceTypeVolume:
+		_, err = client.Volume.Delete(ctx, &hcloud.Volume{ID: resource.ID})
+	case ResourceTypeLoadBalancer:
+		_, err = client.LoadBalancer.Delete(ctx, &hcloud.LoadBalancer{ID: resource.ID})
+	case ResourceTypeFirewall:
+		_, err = client.Firewall.Delete(ctx, &hcloud.Firewall{ID: resource.ID})
+	case ResourceTypePrimaryIP:
+		_, err = client.PrimaryIP.Delete(ctx, &hcloud.PrimaryIP{ID: resource.ID})
+	case ResourceTypeNetwork:
+		_, err = client.Network.Delete(ctx, &hcloud.Network{ID: resource.ID})
+	case ResourceTypePlacementGroup:
+		_, err = client.PlacementGroup.Delete(ctx, &hcloud.PlacementGroup{ID: resource.ID})
+	default:
+		err = fmt.Errorf("unknown resource type: %s", resource.Type)
+	}
+	return nil, err
+}
@@ -0,0 +1,214 @@
+package teardownutil
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+// setupProject creates resources labeled with env=test, and related resources that
+// must be kept.
+func setupProject(t *testing.T, client *hcloud.Client) {
+	t.Helper()
+	ctx := context.Background()
+	labels := map[string]string{"env": "test"}
+
+	_, ipRange, _ := net.ParseCIDR("10.0.0.0/16")
+	_, subnetRange, _ := net.ParseCIDR("10.0.1.0/24")
+	network, _, err := client.Network.Create(ctx, hcloud.NetworkCreateOpts{
+		Name:    "network",
+		IPRange: ipRange,
+		Subnets: []hcloud.NetworkSubnet{{Type: hcloud.NetworkSubnetTypeCloud, IPRange: subnetRange, NetworkZone: hcloud.NetworkZoneEUCentral}},
+		Labels:  labels,
+	})
+	require.NoError(t, err)
+
+	pgResult, _, err := client.PlacementGroup.Create(ctx, hcloud.PlacementGroupCreateOpts{
+		Name: "pg", Type: hcloud.PlacementGroupTypeSpread, Labels: labels,
+	})
+	require.NoError(t, err)
+
+	firewallResult, _, err := client.Firewall.Create(ctx, hcloud.FirewallCreateOpts{Name: "firewall", Labels: labels})
+	require.NoError(t, err)
+
+	volumeResult, _, err := client.Volume.Create(ctx, hcloud.VolumeCreateOpts{
+		Name: "data", Size: 10, Location: &hcloud.Location{Name: "fsn1"}, Labels: labels,
+	})
+	require.NoError(t, err)
+
+	protectedResult, _, err := client.Volume.Create(ctx, hcloud.VolumeCreateOpts{
+		Name: "protected", Size: 10, Location: &hcloud.Location{Name: "fsn1"}, Labels: labels,
+	})
+	require.NoError(t, err)
+	action, _, err := client.Volume.ChangeProtection(ctx, protectedResult.Volume, hcloud.VolumeChangeProtectionOpts{Delete: hcloud.Ptr(true)})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, action))
+
+	webResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:           "web",
+		ServerType:     &hcloud.ServerType{Name: "cpx22"},
+		Image:          &hcloud.Image{Name: "debian-13"},
+		Labels:         labels,
+		Networks:       []*hcloud.Network{network},
+		Volumes:        []*hcloud.Volume{volumeResult.Volume},
+		Firewalls:      []*hcloud.ServerCreateFirewall{{Firewall: *firewallResult.Firewall}},
+		PlacementGroup: pgResult.PlacementGroup,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, webResult.Action))
+
+	keepResult, _, err := client.Server.Create(ctx, hcloud.ServerCreateOpts{
+		Name:       "keep",
+		ServerType: &hcloud.ServerType{Name: "cpx22"},
+		Image:      &hcloud.Image{Name: "debian-13"},
+		Networks:   []*hcloud.Network{network},
+		Firewalls:  []*hcloud.ServerCreateFirewall{{Firewall: *firewallResult.Firewall}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, keepResult.Action))
+
+	lbResult, _, err := client.LoadBalancer.Create(ctx, hcloud.LoadBalancerCreateOpts{
+		Name:             "lb",
+		LoadBalancerType: &hcloud.LoadBalancerType{Name: "lb11"},
+		Location:         &hcloud.Location{Name: "fsn1"},
+		Targets: []hcloud.LoadBalancerCreateOptsTarget{
+			{Type: hcloud.LoadBalancerTargetTypeServer, Server: hcloud.LoadBalancerCreateOptsTargetServer{Server: webResult.Server}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, lbResult.Action))
+
+	_, _, err = client.PrimaryIP.Create(ctx, hcloud.PrimaryIPCreateOpts{
+		Name: "ip", Type: hcloud.PrimaryIPTypeIPv4, Location: "fsn1", AssigneeType: "server", Labels: labels,
+	})
+	require.NoError(t, err)
+}
+
+func TestBuildGraph(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	setupProject(t, client)
+
+	graph, err := BuildGraph(context.Background(), client, "env=test")
+	require.NoError(t, err)
+
+	selected := []string{}
+	for _, resource := range graph.Resources {
+		if resource.Selected {
+			selected = append(selected, resource.String())
+		}
+	}
+	assert.Len(t, selected, 7)
+
+	web := graph.Resources[0]
+	assert.Equal(t, "web", web.Name)
+
+	relations := []string{}
+	for _, relation := range graph.Relations {
+		if relation.From == web || relation.To == web {
+			relations = append(relations, string(relation.Type))
+		}
+	}
+	assert.ElementsMatch(t, []string{"attached_to", "target_of", "applied_to", "assigned_to", "assigned_to", "member_of", "placed_in"}, relations)
+
+	assert.Same(t, web, graph.Resource(ResourceTypeServer, web.ID))
+	assert.Nil(t, graph.Resource(ResourceTypeVolume, web.ID))
+	assert.False(t, web.Stopped)
+
+	_, err = BuildGraph(context.Background(), client, "")
+	require.EqualError(t, err, "label selector is required")
+}
+
+func TestExecute(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	setupProject(t, client)
+	ctx := context.Background()
+
+	graph, err := BuildGraph(ctx, client, "env=test")
+	require.NoError(t, err)
+	plan, err := NewPlan(graph)
+	require.NoError(t, err)
+	require.Len(t, plan.Skipped, 1)
+	assert.Equal(t, "protected", plan.Skipped[0].Name)
+
+	dryRun := []string{}
+	err = Execute(ctx, client, plan, ExecuteOpts{
+		DryRun:   true,
+		Progress: func(step *Step, _ error) { dryRun = append(dryRun, step.String()) },
+	})
+	require.NoError(t, err)
+	assert.Len(t, dryRun, len(plan.Steps()))
+
+	// Nothing was changed by the dry run
+	servers, err := client.Server.All(ctx)
+	require.NoError(t, err)
+	assert.Len(t, servers, 2)
+
+	var mu sync.Mutex
+	executed := 0
+	err = Execute(ctx, client, plan, ExecuteOpts{
+		Parallelism: 3,
+		Progress: func(step *Step, err error) {
+			assert.NoError(t, err, step.String())
+			mu.Lock()
+			defer mu.Unlock()
+			executed++
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len(plan.Steps()), executed)
+
+	servers, err = client.Server.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+	assert.Equal(t, "keep", servers[0].Name)
+	assert.Empty(t, servers[0].PrivateNet)
+	assert.Empty(t, servers[0].PublicNet.Firewalls)
+
+	volumes, err := client.Volume.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, volumes, 1)
+	assert.Equal(t, "protected", volumes[0].Name)
+
+	lbs, err := client.LoadBalancer.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, lbs, 1)
+	assert.Empty(t, lbs[0].Targets)
+
+	for _, check := range []func() (int, error){
+		func() (int, error) { o, err := client.Network.All(ctx); return len(o), err },
+		func() (int, error) { o, err := client.Firewall.All(ctx); return len(o), err },
+		func() (int, error) { o, err := client.PlacementGroup.All(ctx); return len(o), err },
+	} {
+		count, err := check()
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	}
+
+	primaryIPs, err := client.PrimaryIP.AllWithOpts(ctx, hcloud.PrimaryIPListOpts{ListOpts: hcloud.ListOpts{LabelSelector: "env=test"}})
+	require.NoError(t, err)
+	assert.Empty(t, primaryIPs)
+}
+
+func TestExecuteStopsOnError(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	plan := &Plan{Phases: [][]*Step{
+		{{Type: StepTypeDetachVolume, Resource: &Resource{Type: ResourceTypeVolume, ID: 42}}},
+		{{Type: StepTypeDelete, Resource: &Resource{Type: ResourceTypeVolume, ID: 42}}},
+	}}
+
+	executed := 0
+	err := Execute(ctx, client, plan, ExecuteOpts{Progress: func(*Step, error) { executed++ }})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "detach_volume volume 42: ")
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeNotFound))
+	assert.Equal(t, 1, executed)
+}
@@ -0,0 +1,356 @@
+// Package teardownutil discovers the resources of a project and their relations, and
+// deletes them in a safe order.
+//
+// A teardown is done in three steps: [BuildGraph] discovers the resources matching a
+// label selector, [NewPlan] orders the operations required to delete them, and
+// [Execute] runs the plan:
+//
+//	graph, err := teardownutil.BuildGraph(ctx, client, "env=staging")
+//	plan, err := teardownutil.NewPlan(graph)
+//	err = teardownutil.Execute(ctx, client, plan, teardownutil.ExecuteOpts{})
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+package teardownutil
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// ResourceType is the type of a [Resource].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type ResourceType string
+
+// The resource types are listed in the order used to sort the resources.
+const (
+	ResourceTypeServer         ResourceType = "server"
+	ResourceTypeVolume         ResourceType = "volume"
+	ResourceTypeLoadBalancer   ResourceType = "load_balancer"
+	ResourceTypeFirewall       ResourceType = "firewall"
+	ResourceTypePrimaryIP      ResourceType = "primary_ip"
+	ResourceTypeNetwork        ResourceType = "network"
+	ResourceTypePlacementGroup ResourceType = "placement_group"
+)
+
+var resourceTypeOrder = []ResourceType{
+	ResourceTypeServer,
+	ResourceTypeVolume,
+	ResourceTypeLoadBalancer,
+	ResourceTypeFirewall,
+	ResourceTypePrimaryIP,
+	ResourceTypeNetwork,
+	ResourceTypePlacementGroup,
+}
+
+// Resource is a node of a [Graph].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Resource struct {
+	Type ResourceType
+	ID   int64
+	// Name is empty for the related resources that were not selected.
+	Name string
+	// Selected reports whether the resource matched the label selector. Only the
+	// selected resources are deleted, the related resources are kept.
+	Selected bool
+	// Protected reports whether the resource is protected against deletion.
+	Protected bool
+	// Stopped reports whether a server is stopped. It is only known for the selected
+	// servers, and for the kept servers a selected primary IP is assigned to.
+	Stopped bool
+	// LabelSelectors holds the label selectors a firewall is applied to.
+	LabelSelectors []string
+}
+
+func (r *Resource) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%s %d", r.Type, r.ID)
+	}
+	return fmt.Sprintf("%s %s (%d)", r.Type, r.Name, r.ID)
+}
+
+func compareResources(a, b *Resource) int {
+	return cmp.Or(
+		cmp.Compare(slices.Index(resourceTypeOrder, a.Type), slices.Index(resourceTypeOrder, b.Type)),
+		cmp.Compare(a.ID, b.ID),
+	)
+}
+
+// RelationType is the type of a [Relation].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type RelationType string
+
+const (
+	// RelationTypeAttachedTo relates a volume to the server it is attached to.
+	RelationTypeAttachedTo RelationType = "attached_to"
+	// RelationTypeTargetOf relates a server to a load balancer targeting it.
+	RelationTypeTargetOf RelationType = "target_of"
+	// RelationTypeAppliedTo relates a firewall to a server it is applied to.
+	RelationTypeAppliedTo RelationType = "applied_to"
+	// RelationTypeAssignedTo relates a primary IP to the server it is assigned to.
+	RelationTypeAssignedTo RelationType = "assigned_to"
+	// RelationTypeMemberOf relates a server or a load balancer to a network it is
+	// attached to.
+	RelationTypeMemberOf RelationType = "member_of"
+	// RelationTypePlacedIn relates a server to its placement group.
+	RelationTypePlacedIn RelationType = "placed_in"
+)
+
+// Relation is an edge of a [Graph].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Relation struct {
+	Type RelationType
+	From *Resource
+	To   *Resource
+}
+
+func (r *Relation) String() string {
+	return fmt.Sprintf("%s %s %s", r.From, r.Type, r.To)
+}
+
+type resourceKey struct {
+	resourceType ResourceType
+	id           int64
+}
+
+type relationKey struct {
+	relationType RelationType
+	from, to     resourceKey
+}
+
+// Graph holds the resources selected for a teardown, the resources related to them,
+// and their relations.
+//
+// A Graph must be created using the [BuildGraph] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Graph struct {
+	// Resources are sorted by type and ID.
+	Resources []*Resource
+	Relations []*Relation
+
+	resources map[resourceKey]*Resource
+	relations map[relationKey]*Relation
+}
+
+func newGraph() *Graph {
+	return &Graph{
+		resources: make(map[resourceKey]*Resource),
+		relations: make(map[relationKey]*Relation),
+	}
+}
+
+// Resource returns the resource with the given type and ID, or nil if the resource is
+// not part of the graph.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (g *Graph) Resource(resourceType ResourceType, id int64) *Resource {
+	return g.resources[resourceKey{resourceType, id}]
+}
+
+// resource returns the resource with the given type and ID, and adds it to the graph
+// if it does not exist.
+func (g *Graph) resource(resourceType ResourceType, id int64) *Resource {
+	key := resourceKey{resourceType, id}
+	if resource, ok := g.resources[key]; ok {
+		return resource
+	}
+
+	resource := &Resource{Type: resourceType, ID: id}
+	g.resources[key] = resource
+	g.Resources = append(g.Resources, resource)
+	return resource
+}
+
+func (g *Graph) selectResource(resourceType ResourceType, id int64, name string, protected bool) *Resource {
+	resource := g.resource(resourceType, id)
+	resource.Name = name
+	resource.Selected = true
+	resource.Protected = protected
+	return resource
+}
+
+func (g *Graph) relate(relationType RelationType, from, to *Resource) {
+	key := relationKey{relationType, resourceKey{from.Type, from.ID}, resourceKey{to.Type, to.ID}}
+	if _, ok := g.relations[key]; ok {
+		return
+	}
+
+	relation := &Relation{Type: relationType, From: from, To: to}
+	g.relations[key] = relation
+	g.Relations = append(g.Relations, relation)
+}
+
+func (g *Graph) sort() {
+	slices.SortFunc(g.Resources, compareResources)
+	slices.SortFunc(g.Relations, func(a, b *Relation) int {
+		return cmp.Or(
+			compareResources(a.From, b.From),
+			compareResources(a.To, b.To),
+			cmp.Compare(a.Type, b.Type),
+		)
+	})
+}
+
+// BuildGraph lists the servers, volumes, load balancers, firewalls, primary IPs,
+// networks and placement groups matching the label selector, and discovers their
+// relations. The resources related to the selected resources are part of the graph,
+// even if they do not match the label selector.
+//
+// The label selector is required, to never select all the resources of the project.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func BuildGraph(ctx context.Context, client *hcloud.Client, labelSelector string) (*Graph, error) {
+	if labelSelector == "" {
+		return nil, errors.New("label selector is required")
+	}
+
+	g := newGraph()
+	listOpts := hcloud.ListOpts{LabelSelector: labelSelector}
+
+	servers, err := client.Server.AllWithOpts(ctx, hcloud.ServerListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list servers: %w", err)
+	}
+	volumes, err := client.Volume.AllWithOpts(ctx, hcloud.VolumeListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list volumes: %w", err)
+	}
+	loadBalancers, err := client.LoadBalancer.AllWithOpts(ctx, hcloud.LoadBalancerListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list load balancers: %w", err)
+	}
+	firewalls, err := client.Firewall.AllWithOpts(ctx, hcloud.FirewallListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list firewalls: %w", err)
+	}
+	primaryIPs, err := client.PrimaryIP.AllWithOpts(ctx, hcloud.PrimaryIPListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list primary ips: %w", err)
+	}
+	networks, err := client.Network.AllWithOpts(ctx, hcloud.NetworkListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list networks: %w", err)
+	}
+	placementGroups, err := client.PlacementGroup.AllWithOpts(ctx, hcloud.PlacementGroupListOpts{ListOpts: listOpts})
+	if err != nil {
+		return nil, fmt.Errorf("could not list placement groups: %w", err)
+	}
+
+	// Select all the resources first, so the relations reference the selected resources.
+	for _, o := range servers {
+		server := g.selectResource(ResourceTypeServer, o.ID, o.Name, o.Protection.Delete)
+		server.Stopped = o.Status == hcloud.ServerStatusOff
+	}
+	for _, o := range volumes {
+		g.selectResource(ResourceTypeVolume, o.ID, o.Name, o.Protection.Delete)
+	}
+	for _, o := range loadBalancers {
+		g.selectResource(ResourceTypeLoadBalancer, o.ID, o.Name, o.Protection.Delete)
+	}
+	for _, o := range firewalls {
+		g.selectResource(ResourceTypeFirewall, o.ID, o.Name, false)
+	}
+	for _, o := range primaryIPs {
+		g.selectResource(ResourceTypePrimaryIP, o.ID, o.Name, o.Protection.Delete)
+	}
+	for _, o := range networks {
+		g.selectResource(ResourceTypeNetwork, o.ID, o.Name, o.Protection.Delete)
+	}
+	for _, o := range placementGroups {
+		g.selectResource(ResourceTypePlacementGroup, o.ID, o.Name, false)
+	}
+
+	for _, o := range servers {
+		server := g.resource(ResourceTypeServer, o.ID)
+		for _, volume := range o.Volumes {
+			g.relate(RelationTypeAttachedTo, g.resource(ResourceTypeVolume, volume.ID), server)
+		}
+		for _, lb := range o.LoadBalancers {
+			g.relate(RelationTypeTargetOf, server, g.resource(ResourceTypeLoadBalancer, lb.ID))
+		}
+		for _, privateNet := range o.PrivateNet {
+			g.relate(RelationTypeMemberOf, server, g.resource(ResourceTypeNetwork, privateNet.Network.ID))
+		}
+		for _, id := range []int64{o.PublicNet.IPv4.ID, o.PublicNet.IPv6.ID} {
+			if id != 0 {
+				g.relate(RelationTypeAssignedTo, g.resource(ResourceTypePrimaryIP, id), server)
+			}
+		}
+		if o.PlacementGroup != nil {
+			g.relate(RelationTypePlacedIn, server, g.resource(ResourceTypePlacementGroup, o.PlacementGroup.ID))
+		}
+	}
+	for _, o := range volumes {
+		if o.Server != nil {
+			g.relate(RelationTypeAttachedTo, g.resource(ResourceTypeVolume, o.ID), g.resource(ResourceTypeServer, o.Server.ID))
+		}
+	}
+	for _, o := range loadBalancers {
+		lb := g.resource(ResourceTypeLoadBalancer, o.ID)
+		for _, target := range o.Targets {
+			if target.Type == hcloud.LoadBalancerTargetTypeServer && target.Server != nil {
+				g.relate(RelationTypeTargetOf, g.resource(ResourceTypeServer, target.Server.Server.ID), lb)
+			}
+		}
+		for _, privateNet := range o.PrivateNet {
+			g.relate(RelationTypeMemberOf, lb, g.resource(ResourceTypeNetwork, privateNet.Network.ID))
+		}
+	}
+	for _, o := range firewalls {
+		firewall := g.resource(ResourceTypeFirewall, o.ID)
+		for _, resource := range o.AppliedTo {
+			switch resource.Type {
+			case hcloud.FirewallResourceTypeServer:
+				g.relate(RelationTypeAppliedTo, firewall, g.resource(ResourceTypeServer, resource.Server.ID))
+			case hcloud.FirewallResourceTypeLabelSelector:
+				firewall.LabelSelectors = append(firewall.LabelSelectors, resource.LabelSelector.Selector)
+			}
+		}
+	}
+	for _, o := range primaryIPs {
+		if o.AssigneeType == "server" && o.AssigneeID != 0 {
+			g.relate(RelationTypeAssignedTo, g.resource(ResourceTypePrimaryIP, o.ID), g.resource(ResourceTypeServer, o.AssigneeID))
+		}
+	}
+	for _, o := range networks {
+		network := g.resource(ResourceTypeNetwork, o.ID)
+		for _, server := range o.Servers {
+			g.relate(RelationTypeMemberOf, g.resource(ResourceTypeServer, server.ID), network)
+		}
+		for _, lb := range o.LoadBalancers {
+			g.relate(RelationTypeMemberOf, g.resource(ResourceTypeLoadBalancer, lb.ID), network)
+		}
+	}
+	for _, o := range placementGroups {
+		placementGroup := g.resource(ResourceTypePlacementGroup, o.ID)
+		for _, id := range o.Servers {
+			g.relate(RelationTypePlacedIn, g.resource(ResourceTypeServer, id), placementGroup)
+		}
+	}
+
+	// Primary IPs can only be unassigned from stopped servers, fetch the status of the
+	// kept servers the selected primary IPs are assigned to.
+	for _, relation := range g.Relations {
+		primaryIP, server := relation.From, relation.To
+		if relation.Type != RelationTypeAssignedTo || !primaryIP.Selected || server.Selected {
+			continue
+		}
+		o, _, err := client.Server.GetByID(ctx, server.ID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get server %d: %w", server.ID, err)
+		}
+		server.Stopped = o == nil || o.Status == hcloud.ServerStatusOff
+	}
+
+	g.sort()
+	return g, nil
+}
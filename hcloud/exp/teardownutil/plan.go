@@ -0,0 +1,261 @@
+package teardownutil
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// StepType is the type of a [Step].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type StepType string
+
+// The step types are listed in the order used to sort the steps of a phase.
+const (
+	// StepTypeDetachVolume detaches the volume Resource from the Related server.
+	StepTypeDetachVolume StepType = "detach_volume"
+	// StepTypeRemoveTarget removes the Related server from the targets of the load
+	// balancer Resource.
+	StepTypeRemoveTarget StepType = "remove_target"
+	// StepTypeRemoveFirewall removes the firewall Resource from the Related server, or
+	// from the LabelSelector.
+	StepTypeRemoveFirewall StepType = "remove_firewall"
+	// StepTypeUnassignPrimaryIP unassigns the primary IP Resource from the Related
+	// server. The server must be stopped.
+	StepTypeUnassignPrimaryIP StepType = "unassign_primary_ip"
+	// StepTypeDetachNetwork detaches the server or load balancer Resource from the
+	// Related network.
+	StepTypeDetachNetwork StepType = "detach_network"
+	// StepTypeRemoveFromPlacementGroup removes the server Resource from the Related
+	// placement group.
+	StepTypeRemoveFromPlacementGroup StepType = "remove_from_placement_group"
+	// StepTypeDelete deletes the Resource.
+	StepTypeDelete StepType = "delete"
+)
+
+var stepTypeOrder = []StepType{
+	StepTypeDetachVolume,
+	StepTypeRemoveTarget,
+	StepTypeRemoveFirewall,
+	StepTypeUnassignPrimaryIP,
+	StepTypeDetachNetwork,
+	StepTypeRemoveFromPlacementGroup,
+	StepTypeDelete,
+}
+
+// Step is an operation of a [Plan].
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Step struct {
+	Type          StepType
+	Resource      *Resource
+	Related       *Resource
+	LabelSelector string
+	// DependsOn holds the steps that must be completed before this step.
+	DependsOn []*Step
+}
+
+func (s *Step) String() string {
+	switch {
+	case s.Related != nil:
+		return fmt.Sprintf("%s %s (%s)", s.Type, s.Resource, s.Related)
+	case s.LabelSelector != "":
+		return fmt.Sprintf("%s %s (label selector %s)", s.Type, s.Resource, s.LabelSelector)
+	default:
+		return fmt.Sprintf("%s %s", s.Type, s.Resource)
+	}
+}
+
+func compareSteps(a, b *Step) int {
+	related := func(s *Step) int64 {
+		if s.Related == nil {
+			return 0
+		}
+		return s.Related.ID
+	}
+	return cmp.Or(
+		cmp.Compare(slices.Index(stepTypeOrder, a.Type), slices.Index(stepTypeOrder, b.Type)),
+		compareResources(a.Resource, b.Resource),
+		cmp.Compare(related(a), related(b)),
+		cmp.Compare(a.LabelSelector, b.LabelSelector),
+	)
+}
+
+// Plan is an ordered list of the steps tearing down the selected resources of a
+// [Graph].
+//
+// A Plan must be created using the [NewPlan] function.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+type Plan struct {
+	// Phases are executed sequentially, the steps of a phase do not depend on each
+	// other and may be executed concurrently.
+	Phases [][]*Step
+	// Skipped holds the selected resources that are not deleted: the resources
+	// protected against deletion, and the primary IPs assigned to a kept server that is
+	// not stopped, as they can not be unassigned from it.
+	Skipped []*Resource
+}
+
+// Steps returns the steps of all phases, in order.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func (p *Plan) Steps() []*Step {
+	return slices.Concat(p.Phases...)
+}
+
+// NewPlan computes the steps tearing down the selected resources of the graph.
+//
+// Protected resources are not deleted, and are listed in [Plan.Skipped]. The related
+// resources that were not selected are kept, the selected resources are detached
+// from them before being deleted. Kept servers are never stopped, the primary IPs
+// assigned to a kept server that is not stopped are listed in [Plan.Skipped] as well.
+//
+// Experimental: `exp` package is experimental, breaking changes may occur within minor releases.
+func NewPlan(graph *Graph) (*Plan, error) {
+	plan := &Plan{}
+
+	steps := []*Step{}
+	deleteSteps := make(map[*Resource]*Step)
+	addStep := func(step *Step) *Step {
+		steps = append(steps, step)
+		return step
+	}
+
+	// Primary IPs can only be unassigned from stopped servers.
+	unassignable := make(map[*Resource]bool)
+	for _, relation := range graph.Relations {
+		primaryIP, server := relation.From, relation.To
+		kept := !server.Selected || server.Protected
+		if relation.Type == RelationTypeAssignedTo && kept && !server.Stopped {
+			unassignable[primaryIP] = true
+		}
+	}
+
+	for _, resource := range graph.Resources {
+		switch {
+		case !resource.Selected:
+		case resource.Protected || unassignable[resource]:
+			plan.Skipped = append(plan.Skipped, resource)
+		default:
+			deleteSteps[resource] = addStep(&Step{Type: StepTypeDelete, Resource: resource})
+		}
+	}
+
+	// dependsOn orders step after the given steps, nil steps are ignored.
+	dependsOn := func(step *Step, dependencies ...*Step) {
+		if step == nil {
+			return
+		}
+		for _, dependency := range dependencies {
+			if dependency != nil {
+				step.DependsOn = append(step.DependsOn, dependency)
+			}
+		}
+	}
+
+	// detach orders the deletion of the container after the deletion of its member. If
+	// the member is kept, it is detached from the container first.
+	detach := func(stepType StepType, container, member *Resource, resource, related *Resource) {
+		containerDelete := deleteSteps[container]
+		if containerDelete == nil {
+			return
+		}
+		if memberDelete := deleteSteps[member]; memberDelete != nil {
+			dependsOn(containerDelete, memberDelete)
+			return
+		}
+		dependsOn(containerDelete, addStep(&Step{Type: stepType, Resource: resource, Related: related}))
+	}
+
+	for _, relation := range graph.Relations {
+		switch relation.Type {
+		case RelationTypeAttachedTo:
+			// Volumes are detached before being deleted, even if the server is deleted.
+			volume, server := relation.From, relation.To
+			if volumeDelete := deleteSteps[volume]; volumeDelete != nil {
+				step := addStep(&Step{Type: StepTypeDetachVolume, Resource: volume, Related: server})
+				dependsOn(volumeDelete, step)
+				dependsOn(deleteSteps[server], step)
+			}
+		case RelationTypeTargetOf:
+			server, lb := relation.From, relation.To
+			if deleteSteps[server] != nil && deleteSteps[lb] == nil {
+				dependsOn(deleteSteps[server], addStep(&Step{Type: StepTypeRemoveTarget, Resource: lb, Related: server}))
+			}
+		case RelationTypeAppliedTo:
+			firewall, server := relation.From, relation.To
+			detach(StepTypeRemoveFirewall, firewall, server, firewall, server)
+		case RelationTypeAssignedTo:
+			primaryIP, server := relation.From, relation.To
+			detach(StepTypeUnassignPrimaryIP, primaryIP, server, primaryIP, server)
+		case RelationTypeMemberOf:
+			member, network := relation.From, relation.To
+			detach(StepTypeDetachNetwork, network, member, member, network)
+		case RelationTypePlacedIn:
+			server, placementGroup := relation.From, relation.To
+			detach(StepTypeRemoveFromPlacementGroup, placementGroup, server, server, placementGroup)
+		}
+	}
+
+	for _, resource := range graph.Resources {
+		if firewallDelete := deleteSteps[resource]; firewallDelete != nil {
+			for _, labelSelector := range resource.LabelSelectors {
+				dependsOn(firewallDelete, addStep(&Step{Type: StepTypeRemoveFirewall, Resource: resource, LabelSelector: labelSelector}))
+			}
+		}
+	}
+
+	phases, err := phases(steps)
+	if err != nil {
+		return nil, err
+	}
+	plan.Phases = phases
+	return plan, nil
+}
+
+// phases groups the steps by their depth in the dependency graph.
+func phases(steps []*Step) ([][]*Step, error) {
+	const visiting = -1
+
+	depths := make(map[*Step]int, len(steps))
+	var depth func(step *Step) (int, error)
+	depth = func(step *Step) (int, error) {
+		if d, ok := depths[step]; ok {
+			if d == visiting {
+				return 0, fmt.Errorf("dependency cycle on step: %s", step)
+			}
+			return d, nil
+		}
+
+		depths[step] = visiting
+		result := 0
+		for _, dependency := range step.DependsOn {
+			d, err := depth(dependency)
+			if err != nil {
+				return 0, err
+			}
+			result = max(result, d+1)
+		}
+		depths[step] = result
+		return result, nil
+	}
+
+	result := [][]*Step{}
+	for _, step := range steps {
+		d, err := depth(step)
+		if err != nil {
+			return nil, err
+		}
+		for len(result) <= d {
+			result = append(result, []*Step{})
+		}
+		result[d] = append(result[d], step)
+	}
+
+	for _, phase := range result {
+		slices.SortFunc(phase, compareSteps)
+	}
+	return result, nil
+}
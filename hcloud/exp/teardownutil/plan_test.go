@@ -0,0 +1,119 @@
+package teardownutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stepStrings(phases [][]*Step) [][]string {
+	result := make([][]string, 0, len(phases))
+	for _, phase := range phases {
+		steps := make([]string, 0, len(phase))
+		for _, step := range phase {
+			steps = append(steps, step.String())
+		}
+		result = append(result, steps)
+	}
+	return result
+}
+
+func TestNewPlan(t *testing.T) {
+	g := newGraph()
+	server := g.selectResource(ResourceTypeServer, 1, "server", false)
+	keptServer := g.resource(ResourceTypeServer, 2)
+	protectedServer := g.selectResource(ResourceTypeServer, 3, "protected", true)
+	volume := g.selectResource(ResourceTypeVolume, 4, "volume", false)
+	lb := g.resource(ResourceTypeLoadBalancer, 5)
+	firewall := g.selectResource(ResourceTypeFirewall, 6, "firewall", false)
+	firewall.LabelSelectors = []string{"env=test"}
+	primaryIP := g.selectResource(ResourceTypePrimaryIP, 7, "ip", false)
+	keptPrimaryIP := g.resource(ResourceTypePrimaryIP, 8)
+	network := g.selectResource(ResourceTypeNetwork, 9, "network", false)
+	placementGroup := g.selectResource(ResourceTypePlacementGroup, 10, "pg", false)
+
+	g.relate(RelationTypeAttachedTo, volume, server)
+	g.relate(RelationTypeTargetOf, server, lb)
+	g.relate(RelationTypeTargetOf, protectedServer, lb)
+	g.relate(RelationTypeAppliedTo, firewall, server)
+	g.relate(RelationTypeAppliedTo, firewall, keptServer)
+	g.relate(RelationTypeAssignedTo, primaryIP, server)
+	g.relate(RelationTypeAssignedTo, keptPrimaryIP, server)
+	g.relate(RelationTypeMemberOf, server, network)
+	g.relate(RelationTypeMemberOf, keptServer, network)
+	g.relate(RelationTypeMemberOf, protectedServer, network)
+	g.relate(RelationTypePlacedIn, keptServer, placementGroup)
+	g.sort()
+
+	plan, err := NewPlan(g)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*Resource{protectedServer}, plan.Skipped)
+	assert.Equal(t, [][]string{
+		{
+			"detach_volume volume volume (4) (server server (1))",
+			"remove_target load_balancer 5 (server server (1))",
+			"remove_firewall firewall firewall (6) (label selector env=test)",
+			"remove_firewall firewall firewall (6) (server 2)",
+			"detach_network server 2 (network network (9))",
+			"detach_network server protected (3) (network network (9))",
+			"remove_from_placement_group server 2 (placement_group pg (10))",
+		},
+		{
+			"delete server server (1)",
+			"delete volume volume (4)",
+			"delete placement_group pg (10)",
+		},
+		{
+			"delete firewall firewall (6)",
+			"delete primary_ip ip (7)",
+			"delete network network (9)",
+		},
+	}, stepStrings(plan.Phases))
+	assert.Len(t, plan.Steps(), 13)
+}
+
+func TestNewPlanPrimaryIPOfKeptServer(t *testing.T) {
+	g := newGraph()
+	runningServer := g.resource(ResourceTypeServer, 1)
+	stoppedServer := g.resource(ResourceTypeServer, 2)
+	stoppedServer.Stopped = true
+	protectedServer := g.selectResource(ResourceTypeServer, 3, "protected", true)
+	runningIP := g.selectResource(ResourceTypePrimaryIP, 4, "running", false)
+	stoppedIP := g.selectResource(ResourceTypePrimaryIP, 5, "stopped", false)
+	protectedIP := g.selectResource(ResourceTypePrimaryIP, 6, "protected", false)
+
+	g.relate(RelationTypeAssignedTo, runningIP, runningServer)
+	g.relate(RelationTypeAssignedTo, stoppedIP, stoppedServer)
+	g.relate(RelationTypeAssignedTo, protectedIP, protectedServer)
+	g.sort()
+
+	plan, err := NewPlan(g)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*Resource{protectedServer, runningIP, protectedIP}, plan.Skipped)
+	assert.Equal(t, [][]string{
+		{"unassign_primary_ip primary_ip stopped (5) (server 2)"},
+		{"delete primary_ip stopped (5)"},
+	}, stepStrings(plan.Phases))
+}
+
+func TestNewPlanNothingSelected(t *testing.T) {
+	g := newGraph()
+	g.resource(ResourceTypeServer, 1)
+
+	plan, err := NewPlan(g)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Steps())
+	assert.Empty(t, plan.Skipped)
+}
+
+func TestPhasesCycle(t *testing.T) {
+	a := &Step{Type: StepTypeDelete, Resource: &Resource{Type: ResourceTypeServer, ID: 1}}
+	b := &Step{Type: StepTypeDelete, Resource: &Resource{Type: ResourceTypeNetwork, ID: 2}, DependsOn: []*Step{a}}
+	a.DependsOn = []*Step{b}
+
+	_, err := phases([]*Step{a, b})
+	require.EqualError(t, err, "dependency cycle on step: delete server 1")
+}
@@ -0,0 +1,263 @@
+package zoneutil
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+// RRSetUpdate describes the changes required to converge an existing
+// [hcloud.ZoneRRSet] to the desired state.
+type RRSetUpdate struct {
+	Current *hcloud.ZoneRRSet
+	Desired *hcloud.ZoneRRSet
+
+	// Records is set when the records must be replaced.
+	Records bool
+	// TTL is set when the TTL must be changed.
+	TTL bool
+}
+
+// SyncPlan is the list of changes required to converge the RRSets of a Zone to the
+// desired RRSets.
+type SyncPlan struct {
+	Create []*hcloud.ZoneRRSet
+	Update []RRSetUpdate
+	Delete []*hcloud.ZoneRRSet
+
+	// Protected lists the RRSets that must be changed or deleted, but are protected
+	// against changes. They are left untouched.
+	Protected []*hcloud.ZoneRRSet
+}
+
+// Empty reports whether the RRSets are already in the desired state.
+func (p SyncPlan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// String returns a human readable report of the plan, one change per line.
+func (p SyncPlan) String() string {
+	var b strings.Builder
+	for _, rrset := range p.Create {
+		fmt.Fprintf(&b, "create %s/%s (%d records)\n", rrset.Name, rrset.Type, len(rrset.Records))
+	}
+	for _, update := range p.Update {
+		line := fmt.Sprintf("update %s/%s", update.Current.Name, update.Current.Type)
+		if update.Records {
+			line += fmt.Sprintf(" records %d -> %d", len(update.Current.Records), len(update.Desired.Records))
+		}
+		if update.TTL {
+			line += fmt.Sprintf(" ttl %s -> %s", formatTTL(update.Current.TTL), formatTTL(update.Desired.TTL))
+		}
+		b.WriteString(line + "\n")
+	}
+	for _, rrset := range p.Delete {
+		fmt.Fprintf(&b, "delete %s/%s\n", rrset.Name, rrset.Type)
+	}
+	for _, rrset := range p.Protected {
+		fmt.Fprintf(&b, "skip %s/%s (protected)\n", rrset.Name, rrset.Type)
+	}
+	return b.String()
+}
+
+// DiffRRSets computes the [SyncPlan] to converge the current RRSets to the desired
+// RRSets. The zone TTL is used for the RRSets without TTL.
+//
+// The SOA RRSet is managed by the API and is always ignored. The order of the records
+// is not significant.
+func DiffRRSets(current, desired []*hcloud.ZoneRRSet, zoneTTL int) SyncPlan {
+	plan := SyncPlan{}
+
+	currentIndex := make(map[string]*hcloud.ZoneRRSet, len(current))
+	for _, rrset := range current {
+		if rrset.Type == hcloud.ZoneRRSetTypeSOA {
+			continue
+		}
+		currentIndex[rrsetKey(rrset)] = rrset
+	}
+
+	desiredIndex := make(map[string]struct{}, len(desired))
+	for _, rrset := range desired {
+		if rrset.Type == hcloud.ZoneRRSetTypeSOA {
+			continue
+		}
+		key := rrsetKey(rrset)
+		desiredIndex[key] = struct{}{}
+
+		existing, ok := currentIndex[key]
+		if !ok {
+			plan.Create = append(plan.Create, rrset)
+			continue
+		}
+
+		update := RRSetUpdate{
+			Current: existing,
+			Desired: rrset,
+			Records: !equalRecords(existing.Records, rrset.Records),
+			TTL:     effectiveTTL(existing.TTL, zoneTTL) != effectiveTTL(rrset.TTL, zoneTTL),
+		}
+		if !update.Records && !update.TTL {
+			continue
+		}
+		if existing.Protection.Change {
+			plan.Protected = append(plan.Protected, existing)
+			continue
+		}
+		plan.Update = append(plan.Update, update)
+	}
+
+	for _, rrset := range current {
+		if rrset.Type == hcloud.ZoneRRSetTypeSOA {
+			continue
+		}
+		if _, ok := desiredIndex[rrsetKey(rrset)]; ok {
+			continue
+		}
+		if rrset.Protection.Change {
+			plan.Protected = append(plan.Protected, rrset)
+			continue
+		}
+		plan.Delete = append(plan.Delete, rrset)
+	}
+
+	sortRRSets(plan.Create)
+	sortRRSets(plan.Delete)
+	sortRRSets(plan.Protected)
+	slices.SortFunc(plan.Update, func(a, b RRSetUpdate) int { return compareRRSets(a.Current, b.Current) })
+
+	return plan
+}
+
+// SyncOpts specifies options for [Sync].
+type SyncOpts struct {
+	// DryRun only computes the plan, without applying it.
+	DryRun bool
+}
+
+// Sync converges the RRSets of the zone to the desired RRSets, for example parsed
+// using [ParseZonefile]. The RRSets are created, updated and deleted using the
+// minimal set of [hcloud.ZoneClient.CreateRRSet], [hcloud.ZoneClient.SetRRSetRecords],
+// [hcloud.ZoneClient.ChangeRRSetTTL] and [hcloud.ZoneClient.DeleteRRSet] calls, and
+// the resulting actions are awaited.
+//
+// Protected RRSets are never modified, see [SyncPlan.Protected]. With
+// [SyncOpts.DryRun], the plan is returned without applying it.
+func Sync(ctx context.Context, client *hcloud.Client, zone *hcloud.Zone, desired []*hcloud.ZoneRRSet, opts SyncOpts) (SyncPlan, error) {
+	idOrName := zone.Name
+	if zone.ID != 0 {
+		idOrName = strconv.FormatInt(zone.ID, 10)
+	}
+
+	zone, _, err := client.Zone.Get(ctx, idOrName)
+	if err != nil {
+		return SyncPlan{}, err
+	}
+	if zone == nil {
+		return SyncPlan{}, fmt.Errorf("zone not found: %s", idOrName)
+	}
+
+	current, err := client.Zone.AllRRSets(ctx, zone)
+	if err != nil {
+		return SyncPlan{}, err
+	}
+
+	plan := DiffRRSets(current, desired, zone.TTL)
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	for _, rrset := range plan.Create {
+		result, _, err := client.Zone.CreateRRSet(ctx, zone, hcloud.ZoneRRSetCreateOpts{
+			Name:    rrset.Name,
+			Type:    rrset.Type,
+			TTL:     rrset.TTL,
+			Labels:  rrset.Labels,
+			Records: rrset.Records,
+		})
+		if err != nil {
+			return plan, fmt.Errorf("could not create rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+		}
+		if err := client.Action.WaitFor(ctx, result.Action); err != nil {
+			return plan, fmt.Errorf("could not create rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+		}
+	}
+
+	for _, update := range plan.Update {
+		rrset := update.Current
+		if update.Records {
+			action, _, err := client.Zone.SetRRSetRecords(ctx, rrset, hcloud.ZoneRRSetSetRecordsOpts{Records: update.Desired.Records})
+			if err != nil {
+				return plan, fmt.Errorf("could not set records of rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+			}
+			if err := client.Action.WaitFor(ctx, action); err != nil {
+				return plan, fmt.Errorf("could not set records of rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+			}
+		}
+		if update.TTL {
+			action, _, err := client.Zone.ChangeRRSetTTL(ctx, rrset, hcloud.ZoneRRSetChangeTTLOpts{TTL: update.Desired.TTL})
+			if err != nil {
+				return plan, fmt.Errorf("could not change ttl of rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+			}
+			if err := client.Action.WaitFor(ctx, action); err != nil {
+				return plan, fmt.Errorf("could not change ttl of rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+			}
+		}
+	}
+
+	for _, rrset := range plan.Delete {
+		result, _, err := client.Zone.DeleteRRSet(ctx, rrset)
+		if err != nil {
+			return plan, fmt.Errorf("could not delete rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+		}
+		if err := client.Action.WaitFor(ctx, result.Action); err != nil {
+			return plan, fmt.Errorf("could not delete rrset %s/%s: %w", rrset.Name, rrset.Type, err)
+		}
+	}
+
+	return plan, nil
+}
+
+func rrsetKey(rrset *hcloud.ZoneRRSet) string {
+	return rrset.Name + "/" + string(rrset.Type)
+}
+
+func compareRRSets(a, b *hcloud.ZoneRRSet) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Type, b.Type))
+}
+
+func sortRRSets(rrsets []*hcloud.ZoneRRSet) {
+	slices.SortFunc(rrsets, compareRRSets)
+}
+
+func effectiveTTL(ttl *int, zoneTTL int) int {
+	if ttl == nil {
+		return zoneTTL
+	}
+	return *ttl
+}
+
+func formatTTL(ttl *int) string {
+	if ttl == nil {
+		return "default"
+	}
+	return strconv.Itoa(*ttl)
+}
+
+// equalRecords compares the records regardless of their order.
+func equalRecords(a, b []hcloud.ZoneRRSetRecord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	compare := func(x, y hcloud.ZoneRRSetRecord) int {
+		return cmp.Or(cmp.Compare(x.Value, y.Value), cmp.Compare(x.Comment, y.Comment))
+	}
+	return slices.Equal(
+		slices.SortedFunc(slices.Values(a), compare),
+		slices.SortedFunc(slices.Values(b), compare),
+	)
+}
@@ -0,0 +1,113 @@
+package zoneutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakeapi"
+)
+
+func TestDiffRRSets(t *testing.T) {
+	current := []*hcloud.ZoneRRSet{
+		{Name: "@", Type: hcloud.ZoneRRSetTypeSOA, Records: []hcloud.ZoneRRSetRecord{{Value: "soa"}}},
+		{Name: "www", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.35"}, {Value: "201.42.91.36"}}},
+		{Name: "api", Type: hcloud.ZoneRRSetTypeA, TTL: hcloud.Ptr(60), Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.40"}}},
+		{Name: "old", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.50"}}},
+		{Name: "locked", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.60"}}, Protection: hcloud.ZoneRRSetProtection{Change: true}},
+		{Name: "ttl", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.70"}}},
+	}
+	desired := []*hcloud.ZoneRRSet{
+		{Name: "@", Type: hcloud.ZoneRRSetTypeSOA, Records: []hcloud.ZoneRRSetRecord{{Value: "other"}}},
+		{Name: "www", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.36"}, {Value: "201.42.91.35"}}},
+		{Name: "api", Type: hcloud.ZoneRRSetTypeA, TTL: hcloud.Ptr(120), Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.41"}}},
+		{Name: "new", Type: hcloud.ZoneRRSetTypeAAAA, Records: []hcloud.ZoneRRSetRecord{{Value: "2001:db8::1"}}},
+		{Name: "ttl", Type: hcloud.ZoneRRSetTypeA, TTL: hcloud.Ptr(3600), Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.70"}}},
+	}
+
+	plan := DiffRRSets(current, desired, 3600)
+	assert.False(t, plan.Empty())
+
+	require.Len(t, plan.Create, 1)
+	assert.Equal(t, "new", plan.Create[0].Name)
+
+	require.Len(t, plan.Update, 1)
+	assert.Equal(t, "api", plan.Update[0].Current.Name)
+	assert.True(t, plan.Update[0].Records)
+	assert.True(t, plan.Update[0].TTL)
+
+	require.Len(t, plan.Delete, 1)
+	assert.Equal(t, "old", plan.Delete[0].Name)
+
+	require.Len(t, plan.Protected, 1)
+	assert.Equal(t, "locked", plan.Protected[0].Name)
+
+	assert.Equal(t, `create new/AAAA (1 records)
+update api/A records 1 -> 1 ttl 60 -> 120
+delete old/A
+skip locked/A (protected)
+`, plan.String())
+}
+
+func TestSync(t *testing.T) {
+	client := fakeapi.NewClient(t)
+	ctx := context.Background()
+
+	result, _, err := client.Zone.Create(ctx, hcloud.ZoneCreateOpts{
+		Name: "example.com",
+		Mode: hcloud.ZoneModePrimary,
+		RRSets: []hcloud.ZoneCreateOptsRRSet{
+			{Name: "www", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.35"}}},
+			{Name: "old", Type: hcloud.ZoneRRSetTypeA, Records: []hcloud.ZoneRRSetRecord{{Value: "201.42.91.50"}}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.Action.WaitFor(ctx, result.Action))
+
+	nameservers, err := client.Zone.AllRRSetsWithOpts(ctx, result.Zone, hcloud.ZoneRRSetListOpts{Type: []hcloud.ZoneRRSetType{hcloud.ZoneRRSetTypeNS}})
+	require.NoError(t, err)
+	require.Len(t, nameservers, 1)
+
+	zonefile := "@ NS " + nameservers[0].Records[0].Value + "\n"
+	for _, record := range nameservers[0].Records[1:] {
+		zonefile += "  NS " + record.Value + "\n"
+	}
+	zonefile += `
+www	A	201.42.91.36
+new	60	TXT	hello world
+`
+	desired, err := ParseZonefile(zonefile, "example.com")
+	require.NoError(t, err)
+
+	plan, err := Sync(ctx, client, &hcloud.Zone{Name: "example.com"}, desired, SyncOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, plan.Create, 1)
+	assert.Len(t, plan.Update, 1)
+	assert.Len(t, plan.Delete, 1)
+
+	rrset, _, err := client.Zone.GetRRSetByNameAndType(ctx, result.Zone, "old", hcloud.ZoneRRSetTypeA)
+	require.NoError(t, err)
+	assert.NotNil(t, rrset)
+
+	_, err = Sync(ctx, client, result.Zone, desired, SyncOpts{})
+	require.NoError(t, err)
+
+	rrsets, err := client.Zone.AllRRSets(ctx, result.Zone)
+	require.NoError(t, err)
+	names := []string{}
+	for _, rrset := range rrsets {
+		names = append(names, rrset.Name+"/"+string(rrset.Type))
+	}
+	assert.ElementsMatch(t, []string{"@/SOA", "@/NS", "www/A", "new/TXT"}, names)
+
+	rrset, _, err = client.Zone.GetRRSetByNameAndType(ctx, result.Zone, "www", hcloud.ZoneRRSetTypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []hcloud.ZoneRRSetRecord{{Value: "201.42.91.36"}}, rrset.Records)
+
+	plan, err = Sync(ctx, client, result.Zone, desired, SyncOpts{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+}
@@ -0,0 +1,309 @@
+package zoneutil
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+var supportedRRSetTypes = []hcloud.ZoneRRSetType{
+	hcloud.ZoneRRSetTypeA,
+	hcloud.ZoneRRSetTypeAAAA,
+	hcloud.ZoneRRSetTypeCAA,
+	hcloud.ZoneRRSetTypeCNAME,
+	hcloud.ZoneRRSetTypeDS,
+	hcloud.ZoneRRSetTypeHINFO,
+	hcloud.ZoneRRSetTypeHTTPS,
+	hcloud.ZoneRRSetTypeMX,
+	hcloud.ZoneRRSetTypeNS,
+	hcloud.ZoneRRSetTypePTR,
+	hcloud.ZoneRRSetTypeRP,
+	hcloud.ZoneRRSetTypeSOA,
+	hcloud.ZoneRRSetTypeSRV,
+	hcloud.ZoneRRSetTypeSVCB,
+	hcloud.ZoneRRSetTypeTLSA,
+	hcloud.ZoneRRSetTypeTXT,
+}
+
+// ParseZonefile parses a [RFC 1035] zone file for the given zone name into a list of
+// [hcloud.ZoneRRSet], sorted by name and type.
+//
+// The $ORIGIN and $TTL directives, multi-line records using parentheses, relative and
+// absolute owner names, as well as the TTL and class fields are supported. The $INCLUDE
+// directive is not supported. The names of the RRSets are relative to the zone, using
+// "@" for the apex of the zone. The record data is not rewritten, except for TXT
+// records which are quoted using [FormatTXTRecord] if needed.
+//
+// The TTL of an RRSet is only set if the records have an explicit TTL, or if a $TTL
+// directive precedes them. All records of an RRSet must have the same TTL. A comment at
+// the end of a record is used as the comment of the record.
+//
+// [RFC 1035]: https://www.rfc-editor.org/rfc/rfc1035#section-5
+func ParseZonefile(zonefile string, zoneName string) ([]*hcloud.ZoneRRSet, error) {
+	entries, err := scanZonefile(zonefile)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneFQDN := strings.ToLower(strings.TrimSuffix(zoneName, ".")) + "."
+	origin := zoneFQDN
+	owner := ""
+	var defaultTTL *int
+
+	index := make(map[string]*hcloud.ZoneRRSet)
+	result := []*hcloud.ZoneRRSet{}
+
+	for _, entry := range entries {
+		tokens := entry.tokens
+
+		if strings.HasPrefix(tokens[0], "$") {
+			switch strings.ToUpper(tokens[0]) {
+			case "$ORIGIN":
+				if len(tokens) != 2 {
+					return nil, fmt.Errorf("line %d: invalid $ORIGIN directive", entry.line)
+				}
+				origin = absoluteName(tokens[1], origin)
+			case "$TTL":
+				if len(tokens) != 2 {
+					return nil, fmt.Errorf("line %d: invalid $TTL directive", entry.line)
+				}
+				ttl, ok := parseTTL(tokens[1])
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid TTL: %s", entry.line, tokens[1])
+				}
+				defaultTTL = &ttl
+			default:
+				return nil, fmt.Errorf("line %d: unsupported directive: %s", entry.line, tokens[0])
+			}
+			continue
+		}
+
+		if !entry.blankOwner {
+			owner = absoluteName(tokens[0], origin)
+			tokens = tokens[1:]
+		} else if owner == "" {
+			return nil, fmt.Errorf("line %d: missing owner name", entry.line)
+		}
+
+		ttl := defaultTTL
+		for range 2 {
+			if len(tokens) == 0 {
+				break
+			}
+			if value, ok := parseTTL(tokens[0]); ok {
+				ttl = &value
+				tokens = tokens[1:]
+			} else if strings.EqualFold(tokens[0], "IN") {
+				tokens = tokens[1:]
+			}
+		}
+
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("line %d: missing record type or data", entry.line)
+		}
+
+		rrsetType := hcloud.ZoneRRSetType(strings.ToUpper(tokens[0]))
+		if !slices.Contains(supportedRRSetTypes, rrsetType) {
+			return nil, fmt.Errorf("line %d: unsupported record type: %s", entry.line, tokens[0])
+		}
+
+		var rrsetName string
+		switch {
+		case owner == zoneFQDN:
+			rrsetName = "@"
+		case strings.HasSuffix(owner, "."+zoneFQDN):
+			rrsetName = strings.TrimSuffix(owner, "."+zoneFQDN)
+		default:
+			return nil, fmt.Errorf("line %d: name is outside of the zone: %s", entry.line, owner)
+		}
+
+		value := strings.Join(tokens[1:], " ")
+		if rrsetType == hcloud.ZoneRRSetTypeTXT && !IsTXTRecordQuoted(value) {
+			value = FormatTXTRecord(value)
+		}
+
+		key := rrsetName + "/" + string(rrsetType)
+		rrset, ok := index[key]
+		if !ok {
+			rrset = &hcloud.ZoneRRSet{Name: rrsetName, Type: rrsetType, TTL: ttl}
+			index[key] = rrset
+			result = append(result, rrset)
+		} else if !equalTTL(rrset.TTL, ttl) {
+			return nil, fmt.Errorf("line %d: records of %s have different TTLs", entry.line, key)
+		}
+
+		rrset.Records = append(rrset.Records, hcloud.ZoneRRSetRecord{Value: value, Comment: entry.comment})
+	}
+
+	sortRRSets(result)
+
+	return result, nil
+}
+
+// zonefileEntry is a single logical line of a zone file.
+type zonefileEntry struct {
+	line       int
+	blankOwner bool
+	tokens     []string
+	comment    string
+}
+
+// scanZonefile splits the zone file in entries, joining the lines enclosed in
+// parentheses, and removing the comments. The comment of an entry is its first
+// comment, the following ones usually describe the fields enclosed in parentheses.
+func scanZonefile(zonefile string) ([]zonefileEntry, error) {
+	var entries []zonefileEntry
+
+	line := 1
+	entry := zonefileEntry{line: line}
+	var token strings.Builder
+	var quoted, escapeNext bool
+	lineStart := true
+	depth := 0
+
+	endToken := func() {
+		if token.Len() > 0 {
+			entry.tokens = append(entry.tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	runes := []rune(zonefile)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		if escapeNext {
+			token.WriteRune(c)
+			escapeNext = false
+			continue
+		}
+
+		if quoted {
+			switch c {
+			case '\\':
+				escapeNext = true
+			case '"':
+				quoted = false
+			case '\n':
+				return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+			}
+			token.WriteRune(c)
+			continue
+		}
+
+		switch {
+		case c == '\n':
+			endToken()
+			line++
+			if depth == 0 {
+				if len(entry.tokens) > 0 {
+					entries = append(entries, entry)
+				}
+				entry = zonefileEntry{line: line}
+				lineStart = true
+				continue
+			}
+		case c == ';':
+			endToken()
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			if comment := strings.TrimSpace(string(runes[i+1 : end])); comment != "" && entry.comment == "" {
+				entry.comment = comment
+			}
+			i = end - 1
+		case c == '(':
+			endToken()
+			depth++
+		case c == ')':
+			endToken()
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("line %d: unexpected closing parenthesis", line)
+			}
+		case unicode.IsSpace(c):
+			if lineStart && depth == 0 && len(entry.tokens) == 0 && token.Len() == 0 {
+				entry.blankOwner = true
+			}
+			endToken()
+		case c == '\\':
+			token.WriteRune(c)
+			escapeNext = true
+		case c == '"':
+			token.WriteRune(c)
+			quoted = true
+		default:
+			token.WriteRune(c)
+		}
+		lineStart = false
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("line %d: unterminated parenthesis", line)
+	}
+	endToken()
+	if len(entry.tokens) > 0 {
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// absoluteName returns the fully qualified, lower cased, name.
+func absoluteName(name, origin string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return name
+	default:
+		return name + "." + origin
+	}
+}
+
+// parseTTL parses a TTL in seconds, or using the BIND time units (e.g. 1h30m).
+func parseTTL(value string) (int, bool) {
+	if value == "" || !unicode.IsDigit(rune(value[0])) {
+		return 0, false
+	}
+	if ttl, err := strconv.Atoi(value); err == nil {
+		return ttl, true
+	}
+
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+
+	total, number := 0, -1
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= '0' && c <= '9' {
+			number = max(number, 0)*10 + int(c-'0')
+			continue
+		}
+		unit, ok := units[byte(unicode.ToLower(rune(c)))]
+		if !ok || number < 0 {
+			return 0, false
+		}
+		total += number * unit
+		number = -1
+	}
+	if number >= 0 {
+		return 0, false
+	}
+	return total, true
+}
+
+func equalTTL(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,166 @@
+package zoneutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+)
+
+func TestParseZonefile(t *testing.T) {
+	zonefile := `
+$ORIGIN example.com.
+$TTL 3600
+@	IN	SOA	hydrogen.ns.hetzner.com. dns.hetzner.com. ( ; primary name server
+		2024010100 ; serial
+		86400      ; refresh
+		10800      ; retry
+		3600000    ; expire
+		3600 )     ; minimum
+@		IN	NS	hydrogen.ns.hetzner.com.
+		IN	NS	oxygen.ns.hetzner.com.
+
+www	300	IN	A	201.42.91.35 ; web server
+	300	IN	A	201.42.91.36
+WWW.example.com. 300 AAAA 2001:db8::1
+txt	1h	TXT	hello world
+long	TXT	( "v=DKIM1; k=rsa; "
+		  "p=MIIBIjANBgkqh" )
+
+$ORIGIN sub.example.com.
+mail	MX	10 mx.example.com.
+@	CNAME	www.example.com.
+`
+
+	rrsets, err := ParseZonefile(zonefile, "example.com")
+	require.NoError(t, err)
+
+	ttl := func(value int) *int { return &value }
+	assert.Equal(t, []*hcloud.ZoneRRSet{
+		{Name: "@", Type: hcloud.ZoneRRSetTypeNS, TTL: ttl(3600), Records: []hcloud.ZoneRRSetRecord{
+			{Value: "hydrogen.ns.hetzner.com."},
+			{Value: "oxygen.ns.hetzner.com."},
+		}},
+		{Name: "@", Type: hcloud.ZoneRRSetTypeSOA, TTL: ttl(3600), Records: []hcloud.ZoneRRSetRecord{
+			{Value: "hydrogen.ns.hetzner.com. dns.hetzner.com. 2024010100 86400 10800 3600000 3600", Comment: "primary name server"},
+		}},
+		{Name: "long", Type: hcloud.ZoneRRSetTypeTXT, TTL: ttl(3600), Records: []hcloud.ZoneRRSetRecord{
+			{Value: `"v=DKIM1; k=rsa; " "p=MIIBIjANBgkqh"`},
+		}},
+		{Name: "mail.sub", Type: hcloud.ZoneRRSetTypeMX, TTL: ttl(3600), Records: []hcloud.ZoneRRSetRecord{
+			{Value: "10 mx.example.com."},
+		}},
+		{Name: "sub", Type: hcloud.ZoneRRSetTypeCNAME, TTL: ttl(3600), Records: []hcloud.ZoneRRSetRecord{
+			{Value: "www.example.com."},
+		}},
+		{Name: "txt", Type: hcloud.ZoneRRSetTypeTXT, TTL: ttl(3600), Records: []hcloud.ZoneRRSetRecord{
+			{Value: `"hello world"`},
+		}},
+		{Name: "www", Type: hcloud.ZoneRRSetTypeA, TTL: ttl(300), Records: []hcloud.ZoneRRSetRecord{
+			{Value: "201.42.91.35", Comment: "web server"},
+			{Value: "201.42.91.36"},
+		}},
+		{Name: "www", Type: hcloud.ZoneRRSetTypeAAAA, TTL: ttl(300), Records: []hcloud.ZoneRRSetRecord{
+			{Value: "2001:db8::1"},
+		}},
+	}, rrsets)
+}
+
+func TestParseZonefileMultilineComment(t *testing.T) {
+	zonefile := `
+@	3600	SOA	hydrogen.ns.hetzner.com. dns.hetzner.com. (
+		2024010100 ; serial
+		86400      ; refresh
+		10800      ; retry
+		3600000    ; expire
+		3600 )     ; minimum
+`
+
+	rrsets, err := ParseZonefile(zonefile, "example.com")
+	require.NoError(t, err)
+	require.Len(t, rrsets, 1)
+	require.Len(t, rrsets[0].Records, 1)
+	assert.Equal(t, "serial", rrsets[0].Records[0].Comment)
+}
+
+func TestParseZonefileWithoutTTL(t *testing.T) {
+	rrsets, err := ParseZonefile("www A 201.42.91.35\n", "example.com.")
+	require.NoError(t, err)
+	require.Len(t, rrsets, 1)
+	assert.Nil(t, rrsets[0].TTL)
+}
+
+func TestParseZonefileErrors(t *testing.T) {
+	for _, tt := range []struct {
+		desc     string
+		zonefile string
+		err      string
+	}{
+		{
+			desc:     "include",
+			zonefile: "$INCLUDE other.zone",
+			err:      "line 1: unsupported directive: $INCLUDE",
+		},
+		{
+			desc:     "missing owner",
+			zonefile: "\tA 201.42.91.35",
+			err:      "line 1: missing owner name",
+		},
+		{
+			desc:     "unsupported type",
+			zonefile: "www LOC 52 22 23.000 N 4 53 32.000 E -2.00m",
+			err:      "line 1: unsupported record type: LOC",
+		},
+		{
+			desc:     "outside zone",
+			zonefile: "www.example.org. A 201.42.91.35",
+			err:      "line 1: name is outside of the zone: www.example.org.",
+		},
+		{
+			desc:     "different ttls",
+			zonefile: "www 60 A 201.42.91.35\nwww 120 A 201.42.91.36",
+			err:      "line 2: records of www/A have different TTLs",
+		},
+		{
+			desc:     "unterminated parenthesis",
+			zonefile: "www TXT ( \"hello\"\n",
+			err:      "line 2: unterminated parenthesis",
+		},
+		{
+			desc:     "unterminated quote",
+			zonefile: "www TXT \"hello\n",
+			err:      "line 1: unterminated quoted string",
+		},
+		{
+			desc:     "missing data",
+			zonefile: "www A",
+			err:      "line 1: missing record type or data",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ParseZonefile(tt.zonefile, "example.com")
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	for value, want := range map[string]int{
+		"300":   300,
+		"1h":    3600,
+		"1h30m": 5400,
+		"1W":    604800,
+		"2d1s":  172801,
+	} {
+		got, ok := parseTTL(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	for _, value := range []string{"", "IN", "h1", "1h30", "1x"} {
+		_, ok := parseTTL(value)
+		assert.False(t, ok, value)
+	}
+}
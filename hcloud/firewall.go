@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net"
 	"net/url"
 	"strconv"
@@ -185,6 +186,20 @@ func (c *FirewallClient) AllWithOpts(ctx context.Context, opts FirewallListOpts)
 	})
 }
 
+// Iter returns an iterator over all Firewalls for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *FirewallClient) Iter(ctx context.Context, opts FirewallListOpts) iter.Seq2[*Firewall, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*Firewall, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // FirewallCreateOpts specifies options for creating a new Firewall.
 type FirewallCreateOpts struct {
 	Name    string
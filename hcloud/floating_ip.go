@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net"
 	"net/url"
 	"strconv"
@@ -179,6 +180,20 @@ func (c *FloatingIPClient) AllWithOpts(ctx context.Context, opts FloatingIPListO
 	})
 }
 
+// Iter returns an iterator over all Floating IPs for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *FloatingIPClient) Iter(ctx context.Context, opts FloatingIPListOpts) iter.Seq2[*FloatingIP, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*FloatingIP, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // FloatingIPCreateOpts specifies options for creating a Floating IP.
 type FloatingIPCreateOpts struct {
 	Type         FloatingIPType
@@ -0,0 +1,68 @@
+// Package redact replaces the sensitive values of JSON documents, before they are
+// logged or written to disk.
+package redact
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Redacted replaces the redacted values.
+const Redacted = "REDACTED"
+
+// SensitiveFields are the JSON fields holding secrets in the API requests and
+// responses.
+var SensitiveFields = []string{
+	"token",
+	"password",      // StorageBox, StorageBoxSubaccount, ...
+	"root_password", // ServerCreateResult, ServerRebuildResult, ...
+	"private_key",   // Certificate
+	"tsig_key",      // Zone
+}
+
+// JSON replaces the values of the given fields in a JSON body, at any depth. Null
+// and empty values are kept. Non JSON bodies are returned unchanged.
+func JSON(body []byte, fields []string) []byte {
+	if len(body) == 0 || len(fields) == 0 {
+		return body
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	if !redactValue(value, fields) {
+		return body
+	}
+
+	result, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return result
+}
+
+// redactValue redacts the fields in place, and reports whether a field was redacted.
+func redactValue(value any, fields []string) bool {
+	redacted := false
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if slices.Contains(fields, key) {
+				if item != nil && item != "" {
+					v[key] = Redacted
+					redacted = true
+				}
+				continue
+			}
+			redacted = redactValue(item, fields) || redacted
+		}
+	case []any:
+		for _, item := range v {
+			redacted = redactValue(item, fields) || redacted
+		}
+	}
+
+	return redacted
+}
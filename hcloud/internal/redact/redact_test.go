@@ -0,0 +1,21 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	body := JSON([]byte(`{"server":{"id":1},"root_password":"secret","zone":{"tsig_key":"secret"},"subaccounts":[{"password":"secret"},{"password":null}]}`), SensitiveFields)
+	assert.JSONEq(t, `{"server":{"id":1},"root_password":"REDACTED","zone":{"tsig_key":"REDACTED"},"subaccounts":[{"password":"REDACTED"},{"password":null}]}`, string(body))
+
+	body = JSON([]byte(`{"server":{"id":1}}`), SensitiveFields)
+	assert.Equal(t, `{"server":{"id":1}}`, string(body))
+
+	body = JSON([]byte(`not json`), SensitiveFields)
+	assert.Equal(t, "not json", string(body))
+
+	body = JSON(nil, SensitiveFields)
+	assert.Empty(t, body)
+}
@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"time"
 
@@ -136,3 +137,17 @@ func (c *ISOClient) AllWithOpts(ctx context.Context, opts ISOListOpts) ([]*ISO,
 		return c.List(ctx, opts)
 	})
 }
+
+// Iter returns an iterator over all ISOs for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *ISOClient) Iter(ctx context.Context, opts ISOListOpts) iter.Seq2[*ISO, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*ISO, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net"
 	"net/url"
 	"slices"
@@ -338,6 +339,20 @@ func (c *LoadBalancerClient) AllWithOpts(ctx context.Context, opts LoadBalancerL
 	})
 }
 
+// Iter returns an iterator over all Load Balancers for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *LoadBalancerClient) Iter(ctx context.Context, opts LoadBalancerListOpts) iter.Seq2[*LoadBalancer, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*LoadBalancer, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // LoadBalancerUpdateOpts specifies options for updating a Load Balancer.
 type LoadBalancerUpdateOpts struct {
 	Name   string
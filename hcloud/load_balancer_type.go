@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 
@@ -113,3 +114,17 @@ func (c *LoadBalancerTypeClient) AllWithOpts(ctx context.Context, opts LoadBalan
 		return c.List(ctx, opts)
 	})
 }
+
+// Iter returns an iterator over all Load Balancer types for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *LoadBalancerTypeClient) Iter(ctx context.Context, opts LoadBalancerTypeListOpts) iter.Seq2[*LoadBalancerType, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*LoadBalancerType, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
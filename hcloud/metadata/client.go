@@ -80,32 +80,37 @@ func NewClient(options ...ClientOption) *Client {
 	return client
 }
 
-// get executes an HTTP request against the API.
+// get executes an HTTP request against the API, and returns the trimmed response body.
 func (c *Client) get(ctx context.Context, path string) (string, error) {
+	bodyBytes, err := c.getRaw(ctx, path, c.endpoint+path)
+	return string(bytes.TrimSpace(bodyBytes)), err
+}
+
+// getRaw executes an HTTP request against the URL, and returns the unmodified response
+// body.
+func (c *Client) getRaw(ctx context.Context, path, url string) ([]byte, error) {
 	ctx = ctxutil.SetOpPath(ctx, path)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+path, http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	body := string(bytes.TrimSpace(bodyBytes))
-
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return body, fmt.Errorf("response status was %d", resp.StatusCode)
+		return bodyBytes, fmt.Errorf("response status was %d", resp.StatusCode)
 	}
-	return body, nil
+	return bodyBytes, nil
 }
 
 // IsHcloudServer checks if the currently called server is a hcloud server by calling a metadata endpoint
@@ -0,0 +1,268 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrivateNetwork holds the details of a private network the server is attached to.
+type PrivateNetwork struct {
+	IP           net.IP
+	AliasIPs     []net.IP
+	InterfaceNum int
+	MACAddress   string
+	NetworkID    int64
+	NetworkName  string
+	Network      *net.IPNet
+	Subnet       *net.IPNet
+	Gateway      net.IP
+}
+
+type privateNetworkYAML struct {
+	IP           string   `yaml:"ip"`
+	AliasIPs     []string `yaml:"alias_ips"`
+	InterfaceNum int      `yaml:"interface_num"`
+	MACAddress   string   `yaml:"mac_address"`
+	NetworkID    int64    `yaml:"network_id"`
+	NetworkName  string   `yaml:"network_name"`
+	Network      string   `yaml:"network"`
+	Subnet       string   `yaml:"subnet"`
+	Gateway      string   `yaml:"gateway"`
+}
+
+// ParsedPrivateNetworks returns details about the private networks the server is
+// attached to. See [Client.PrivateNetworksWithContext] for the unparsed YAML.
+func (c *Client) ParsedPrivateNetworks(ctx context.Context) ([]PrivateNetwork, error) {
+	resp, err := c.PrivateNetworksWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateNetworks(resp)
+}
+
+func parsePrivateNetworks(data string) ([]PrivateNetwork, error) {
+	var raw []privateNetworkYAML
+	if err := yaml.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid private networks: %w", err)
+	}
+
+	result := make([]PrivateNetwork, 0, len(raw))
+	for _, item := range raw {
+		privateNetwork := PrivateNetwork{
+			IP:           net.ParseIP(item.IP),
+			AliasIPs:     make([]net.IP, 0, len(item.AliasIPs)),
+			InterfaceNum: item.InterfaceNum,
+			MACAddress:   item.MACAddress,
+			NetworkID:    item.NetworkID,
+			NetworkName:  item.NetworkName,
+			Gateway:      net.ParseIP(item.Gateway),
+		}
+		for _, aliasIP := range item.AliasIPs {
+			privateNetwork.AliasIPs = append(privateNetwork.AliasIPs, net.ParseIP(aliasIP))
+		}
+		if item.Network != "" {
+			_, ipNet, err := net.ParseCIDR(item.Network)
+			if err != nil {
+				return nil, fmt.Errorf("invalid private network %d: %w", item.NetworkID, err)
+			}
+			privateNetwork.Network = ipNet
+		}
+		if item.Subnet != "" {
+			_, ipNet, err := net.ParseCIDR(item.Subnet)
+			if err != nil {
+				return nil, fmt.Errorf("invalid private network %d: %w", item.NetworkID, err)
+			}
+			privateNetwork.Subnet = ipNet
+		}
+		result = append(result, privateNetwork)
+	}
+	return result, nil
+}
+
+// PublicKeys returns the public SSH keys configured for the server.
+func (c *Client) PublicKeys(ctx context.Context) ([]string, error) {
+	resp, err := c.get(ctx, "/public-keys")
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	if err := yaml.Unmarshal([]byte(resp), &keys); err != nil {
+		return nil, fmt.Errorf("invalid public keys: %w", err)
+	}
+	return keys, nil
+}
+
+// UserData returns the unmodified user data the server was created with.
+//
+// The user data is served next to the metadata endpoint, e.g.
+// http://169.254.169.254/hetzner/v1/userdata.
+func (c *Client) UserData(ctx context.Context) ([]byte, error) {
+	userDataURL, err := url.Parse(c.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	userDataURL.Path = path.Join("/", path.Dir(userDataURL.Path), "userdata")
+
+	return c.getRaw(ctx, "/userdata", userDataURL.String())
+}
+
+// document is the metadata served by the metadata endpoint itself.
+type document struct {
+	VendorData    string `yaml:"vendor_data"`
+	NetworkConfig struct {
+		Config []struct {
+			Subnets []struct {
+				Type    string `yaml:"type"`
+				Address string `yaml:"address"`
+				IPv6    bool   `yaml:"ipv6"`
+			} `yaml:"subnets"`
+		} `yaml:"config"`
+	} `yaml:"network-config"`
+}
+
+func (c *Client) document(ctx context.Context) (*document, error) {
+	resp, err := c.getRaw(ctx, "/", c.endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := yaml.Unmarshal(resp, &doc); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	return &doc, nil
+}
+
+// publicIPv6 returns the address of the first static IPv6 subnet of the network
+// configuration, or nil.
+func (doc *document) publicIPv6() (*net.IPNet, error) {
+	for _, config := range doc.NetworkConfig.Config {
+		for _, subnet := range config.Subnets {
+			if subnet.Type != "static" || !subnet.IPv6 {
+				continue
+			}
+			ip, ipNet, err := net.ParseCIDR(subnet.Address)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public IPv6: %w", err)
+			}
+			return &net.IPNet{IP: ip, Mask: ipNet.Mask}, nil
+		}
+	}
+	return nil, nil
+}
+
+// VendorData returns the vendor data of the server, used by cloud-init to configure
+// the server.
+func (c *Client) VendorData(ctx context.Context) (string, error) {
+	doc, err := c.document(ctx)
+	if err != nil {
+		return "", err
+	}
+	return doc.VendorData, nil
+}
+
+// PublicIPv6 returns the Public IPv6 address of the server that did the request to the
+// Metadata server, with the prefix length of its network. Returns nil if the server has
+// no Public IPv6.
+//
+// The address is read from the network configuration of the metadata.
+func (c *Client) PublicIPv6(ctx context.Context) (*net.IPNet, error) {
+	doc, err := c.document(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return doc.publicIPv6()
+}
+
+// InstanceMetadata is a snapshot of the metadata of the server that did the requests
+// to the Metadata server.
+type InstanceMetadata struct {
+	Hostname         string
+	InstanceID       int64
+	PublicIPv4       net.IP
+	PublicIPv6       *net.IPNet
+	Region           string
+	AvailabilityZone string
+	PublicKeys       []string
+	PrivateNetworks  []PrivateNetwork
+	UserData         []byte
+	VendorData       string
+}
+
+// InstanceMetadata fetches all the metadata of the server concurrently. An error is
+// returned if any of the requests failed.
+func (c *Client) InstanceMetadata(ctx context.Context) (*InstanceMetadata, error) {
+	result := &InstanceMetadata{}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	fetch := func(name string, fn func() error) {
+		wg.Go(func() {
+			if err := fn(); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		})
+	}
+
+	fetch("hostname", func() (err error) {
+		result.Hostname, err = c.HostnameWithContext(ctx)
+		return err
+	})
+	fetch("instance id", func() (err error) {
+		result.InstanceID, err = c.InstanceIDWithContext(ctx)
+		return err
+	})
+	fetch("public ipv4", func() (err error) {
+		result.PublicIPv4, err = c.PublicIPv4WithContext(ctx)
+		return err
+	})
+	fetch("region", func() (err error) {
+		result.Region, err = c.RegionWithContext(ctx)
+		return err
+	})
+	fetch("availability zone", func() (err error) {
+		result.AvailabilityZone, err = c.AvailabilityZoneWithContext(ctx)
+		return err
+	})
+	fetch("public keys", func() (err error) {
+		result.PublicKeys, err = c.PublicKeys(ctx)
+		return err
+	})
+	fetch("private networks", func() (err error) {
+		result.PrivateNetworks, err = c.ParsedPrivateNetworks(ctx)
+		return err
+	})
+	fetch("user data", func() (err error) {
+		result.UserData, err = c.UserData(ctx)
+		return err
+	})
+	fetch("metadata", func() error {
+		doc, err := c.document(ctx)
+		if err != nil {
+			return err
+		}
+		result.VendorData = doc.VendorData
+		result.PublicIPv6, err = doc.publicIPv6()
+		return err
+	})
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
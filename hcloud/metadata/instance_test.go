@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakemetadata"
+)
+
+var testMetadata = fakemetadata.Metadata{
+	Hostname:         "my-server",
+	InstanceID:       42,
+	PublicIPv4:       "203.0.113.1",
+	PublicIPv6:       "2001:db8::1/64",
+	Region:           "eu-central",
+	AvailabilityZone: "fsn1-dc14",
+	PublicKeys:       []string{"ssh-ed25519 AAAA user@host"},
+	PrivateNetworks: []fakemetadata.PrivateNetwork{
+		{
+			IP:           "10.0.0.2",
+			AliasIPs:     []string{"10.0.0.3", "10.0.0.4"},
+			InterfaceNum: 1,
+			MACAddress:   "86:00:00:2a:7d:e0",
+			NetworkID:    1234,
+			NetworkName:  "nw-test1",
+			Network:      "10.0.0.0/8",
+			Subnet:       "10.0.0.0/24",
+			Gateway:      "10.0.0.1",
+		},
+	},
+	UserData:   "#cloud-config\npackages: [curl]\n",
+	VendorData: "#cloud-config\n",
+}
+
+func TestParsePrivateNetworks(t *testing.T) {
+	networks, err := parsePrivateNetworks(`- ip: 10.0.0.2
+  alias_ips: [10.0.0.3, 10.0.0.4]
+  interface_num: 1
+  mac_address: 86:00:00:2a:7d:e0
+  network_id: 1234
+  network_name: nw-test1
+  network: 10.0.0.0/8
+  subnet: 10.0.0.0/24
+  gateway: 10.0.0.1
+- ip: 192.168.0.2
+  alias_ips: []
+  interface_num: 2
+  mac_address: 86:00:00:2a:7d:e1
+  network_id: 4321
+  network_name: nw-test2
+  network: 192.168.0.0/16
+  subnet: 192.168.0.0/24
+  gateway: 192.168.0.1
+`)
+	require.NoError(t, err)
+	require.Len(t, networks, 2)
+
+	assert.Equal(t, PrivateNetwork{
+		IP:           net.ParseIP("10.0.0.2"),
+		AliasIPs:     []net.IP{net.ParseIP("10.0.0.3"), net.ParseIP("10.0.0.4")},
+		InterfaceNum: 1,
+		MACAddress:   "86:00:00:2a:7d:e0",
+		NetworkID:    1234,
+		NetworkName:  "nw-test1",
+		Network:      &net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+		Subnet:       &net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(24, 32)},
+		Gateway:      net.ParseIP("10.0.0.1"),
+	}, networks[0])
+	assert.Empty(t, networks[1].AliasIPs)
+	assert.Equal(t, 2, networks[1].InterfaceNum)
+
+	networks, err = parsePrivateNetworks("[]")
+	require.NoError(t, err)
+	assert.Empty(t, networks)
+
+	_, err = parsePrivateNetworks("- ip: 10.0.0.2\n  network_id: 1\n  subnet: invalid\n")
+	require.EqualError(t, err, "invalid private network 1: invalid CIDR address: invalid")
+}
+
+func TestClient_Accessors(t *testing.T) {
+	server := fakemetadata.NewServer(t, testMetadata)
+	client := NewClient(WithEndpoint(server.Endpoint()))
+	ctx := context.Background()
+
+	networks, err := client.ParsedPrivateNetworks(ctx)
+	require.NoError(t, err)
+	require.Len(t, networks, 1)
+	assert.Equal(t, int64(1234), networks[0].NetworkID)
+	assert.Equal(t, "10.0.0.0/24", networks[0].Subnet.String())
+
+	keys, err := client.PublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ssh-ed25519 AAAA user@host"}, keys)
+
+	userData, err := client.UserData(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "#cloud-config\npackages: [curl]\n", string(userData))
+
+	vendorData, err := client.VendorData(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "#cloud-config\n", vendorData)
+
+	ipv6, err := client.PublicIPv6(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8::1/64", ipv6.String())
+
+	server.Update(func(metadata *fakemetadata.Metadata) {
+		metadata.PublicIPv6 = ""
+		metadata.PublicKeys = nil
+	})
+
+	ipv6, err = client.PublicIPv6(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, ipv6)
+
+	keys, err = client.PublicKeys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestClient_InstanceMetadata(t *testing.T) {
+	server := fakemetadata.NewServer(t, testMetadata)
+	client := NewClient(WithEndpoint(server.Endpoint()))
+
+	instance, err := client.InstanceMetadata(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-server", instance.Hostname)
+	assert.Equal(t, int64(42), instance.InstanceID)
+	assert.Equal(t, "203.0.113.1", instance.PublicIPv4.String())
+	assert.Equal(t, "2001:db8::1/64", instance.PublicIPv6.String())
+	assert.Equal(t, "eu-central", instance.Region)
+	assert.Equal(t, "fsn1-dc14", instance.AvailabilityZone)
+	assert.Equal(t, []string{"ssh-ed25519 AAAA user@host"}, instance.PublicKeys)
+	require.Len(t, instance.PrivateNetworks, 1)
+	assert.Equal(t, "10.0.0.3", instance.PrivateNetworks[0].AliasIPs[0].String())
+	assert.Equal(t, "#cloud-config\npackages: [curl]\n", string(instance.UserData))
+	assert.Equal(t, "#cloud-config\n", instance.VendorData)
+}
+
+func TestClient_InstanceMetadata_Error(t *testing.T) {
+	server := fakemetadata.NewServer(t, testMetadata)
+	server.Close()
+	client := NewClient(WithEndpoint(server.Endpoint()))
+
+	_, err := client.InstanceMetadata(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hostname: ")
+	assert.Contains(t, err.Error(), "private networks: ")
+}
@@ -0,0 +1,267 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ChangeType is the type of a [ChangeEvent].
+type ChangeType string
+
+const (
+	// ChangeTypeHostname is emitted when the hostname of the server changed.
+	ChangeTypeHostname ChangeType = "hostname"
+	// ChangeTypePublicIPv4 is emitted when the Public IPv4 of the server changed.
+	ChangeTypePublicIPv4 ChangeType = "public_ipv4"
+	// ChangeTypePrivateNetworkAttached is emitted when the server was attached to a
+	// private network.
+	ChangeTypePrivateNetworkAttached ChangeType = "private_network_attached"
+	// ChangeTypePrivateNetworkDetached is emitted when the server was detached from a
+	// private network.
+	ChangeTypePrivateNetworkDetached ChangeType = "private_network_detached"
+	// ChangeTypeAliasIPs is emitted when the alias IPs of the server in a private
+	// network changed.
+	ChangeTypeAliasIPs ChangeType = "alias_ips"
+)
+
+// WatchSnapshot holds the metadata compared by a [Watcher].
+type WatchSnapshot struct {
+	Hostname        string
+	PublicIPv4      net.IP
+	PrivateNetworks []PrivateNetwork
+}
+
+// ChangeEvent describes a change of the metadata detected by a [Watcher].
+type ChangeEvent struct {
+	Type ChangeType
+	// Previous and Current are the snapshots the change was detected between.
+	Previous *WatchSnapshot
+	Current  *WatchSnapshot
+	// PrivateNetwork is the private network attachment that changed, set for the
+	// private network and alias IPs changes. For a detached private network, it holds
+	// the last known attachment.
+	PrivateNetwork *PrivateNetwork
+	// AddedAliasIPs and RemovedAliasIPs are set for the alias IPs changes.
+	AddedAliasIPs   []net.IP
+	RemovedAliasIPs []net.IP
+}
+
+// watchMaxBackoff is the maximum duration between two polls while the Metadata
+// server is unreachable, unless the watch interval is longer.
+const watchMaxBackoff = time.Minute
+
+// Watcher polls the Metadata server and emits the changes of the metadata, see
+// [Client.Watch].
+type Watcher struct {
+	client   *Client
+	interval time.Duration
+	events   chan ChangeEvent
+
+	mu       sync.Mutex
+	healthy  bool
+	err      error
+	snapshot *WatchSnapshot
+}
+
+// Watch polls the Metadata server every interval, compares the successive snapshots
+// of the metadata (hostname, Public IPv4, private networks and alias IPs) and emits
+// a [ChangeEvent] for every detected change. The first snapshot is the baseline of
+// the comparison and emits no events.
+//
+// While the Metadata server is unreachable, the polls are delayed with an exponential
+// backoff, and the [Watcher] reports it as unhealthy.
+//
+// The events channel is closed once the context is done. Events must be received
+// promptly, as the polling is blocked until an event is received. The interval
+// defaults to 10 seconds.
+func (c *Client) Watch(ctx context.Context, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	w := &Watcher{
+		client:   c,
+		interval: interval,
+		events:   make(chan ChangeEvent),
+	}
+	go w.run(ctx)
+	return w
+}
+
+// Events returns the channel receiving the detected changes.
+func (w *Watcher) Events() <-chan ChangeEvent {
+	return w.events
+}
+
+// Healthy reports whether the last poll of the Metadata server succeeded.
+func (w *Watcher) Healthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.healthy
+}
+
+// Err returns the error of the last poll of the Metadata server, or nil if it
+// succeeded.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// Snapshot returns the last snapshot of the metadata, or nil if no poll succeeded yet.
+func (w *Watcher) Snapshot() *WatchSnapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.snapshot
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	defer close(w.events)
+
+	failures := 0
+	for {
+		current, err := w.client.watchSnapshot(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.mu.Lock()
+		previous := w.snapshot
+		w.healthy, w.err = err == nil, err
+		if err == nil {
+			w.snapshot = current
+		}
+		w.mu.Unlock()
+
+		delay := w.interval
+		if err != nil {
+			failures++
+			delay = w.backoff(failures)
+		} else {
+			failures = 0
+			if previous != nil {
+				for _, event := range diffSnapshots(previous, current) {
+					select {
+					case w.events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns the exponential delay after the given number of consecutive
+// failures, capped to [watchMaxBackoff] or the interval.
+func (w *Watcher) backoff(failures int) time.Duration {
+	maxBackoff := max(w.interval, watchMaxBackoff)
+
+	delay := w.interval
+	for range failures {
+		delay *= 2
+		if delay <= 0 || delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// watchSnapshot fetches the metadata compared by a [Watcher] concurrently.
+func (c *Client) watchSnapshot(ctx context.Context) (*WatchSnapshot, error) {
+	result := &WatchSnapshot{}
+
+	var (
+		wg                               sync.WaitGroup
+		hostnameErr, ipv4Err, networkErr error
+	)
+	wg.Go(func() {
+		result.Hostname, hostnameErr = c.HostnameWithContext(ctx)
+	})
+	wg.Go(func() {
+		result.PublicIPv4, ipv4Err = c.PublicIPv4WithContext(ctx)
+	})
+	wg.Go(func() {
+		result.PrivateNetworks, networkErr = c.ParsedPrivateNetworks(ctx)
+	})
+	wg.Wait()
+
+	if err := errors.Join(hostnameErr, ipv4Err, networkErr); err != nil {
+		return nil, fmt.Errorf("could not fetch metadata: %w", err)
+	}
+	return result, nil
+}
+
+// diffSnapshots returns the changes between two snapshots.
+func diffSnapshots(previous, current *WatchSnapshot) []ChangeEvent {
+	events := make([]ChangeEvent, 0)
+	newEvent := func(changeType ChangeType) ChangeEvent {
+		return ChangeEvent{Type: changeType, Previous: previous, Current: current}
+	}
+
+	if previous.Hostname != current.Hostname {
+		events = append(events, newEvent(ChangeTypeHostname))
+	}
+	if !previous.PublicIPv4.Equal(current.PublicIPv4) {
+		events = append(events, newEvent(ChangeTypePublicIPv4))
+	}
+
+	for i := range previous.PrivateNetworks {
+		old := &previous.PrivateNetworks[i]
+		idx := slices.IndexFunc(current.PrivateNetworks, func(n PrivateNetwork) bool { return n.NetworkID == old.NetworkID })
+		if idx < 0 || !current.PrivateNetworks[idx].IP.Equal(old.IP) {
+			event := newEvent(ChangeTypePrivateNetworkDetached)
+			event.PrivateNetwork = old
+			events = append(events, event)
+		}
+	}
+
+	for i := range current.PrivateNetworks {
+		privateNetwork := &current.PrivateNetworks[i]
+		idx := slices.IndexFunc(previous.PrivateNetworks, func(n PrivateNetwork) bool { return n.NetworkID == privateNetwork.NetworkID })
+		if idx < 0 || !previous.PrivateNetworks[idx].IP.Equal(privateNetwork.IP) {
+			event := newEvent(ChangeTypePrivateNetworkAttached)
+			event.PrivateNetwork = privateNetwork
+			events = append(events, event)
+			continue
+		}
+
+		oldAliasIPs := previous.PrivateNetworks[idx].AliasIPs
+		added := diffIPs(privateNetwork.AliasIPs, oldAliasIPs)
+		removed := diffIPs(oldAliasIPs, privateNetwork.AliasIPs)
+		if len(added) > 0 || len(removed) > 0 {
+			event := newEvent(ChangeTypeAliasIPs)
+			event.PrivateNetwork = privateNetwork
+			event.AddedAliasIPs = added
+			event.RemovedAliasIPs = removed
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// diffIPs returns the IPs of a that are not in b.
+func diffIPs(a, b []net.IP) []net.IP {
+	var result []net.IP
+	for _, ip := range a {
+		if !slices.ContainsFunc(b, ip.Equal) {
+			result = append(result, ip)
+		}
+	}
+	return result
+}
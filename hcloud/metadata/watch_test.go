@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/fakemetadata"
+)
+
+func receiveEvent(t *testing.T, w *Watcher) ChangeEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-w.Events():
+		require.True(t, ok, "events channel closed")
+		return event
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no event received")
+		return ChangeEvent{}
+	}
+}
+
+func TestClient_Watch(t *testing.T) {
+	server := fakemetadata.NewServer(t, testMetadata)
+	client := NewClient(WithEndpoint(server.Endpoint()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := client.Watch(ctx, time.Millisecond)
+	require.Eventually(t, func() bool { return w.Snapshot() != nil }, 5*time.Second, time.Millisecond)
+	assert.True(t, w.Healthy())
+	require.NoError(t, w.Err())
+	assert.Equal(t, "my-server", w.Snapshot().Hostname)
+
+	server.Update(func(metadata *fakemetadata.Metadata) {
+		metadata.PrivateNetworks = []fakemetadata.PrivateNetwork{metadata.PrivateNetworks[0]}
+		metadata.PrivateNetworks[0].AliasIPs = []string{"10.0.0.3", "10.0.0.5"}
+	})
+
+	event := receiveEvent(t, w)
+	assert.Equal(t, ChangeTypeAliasIPs, event.Type)
+	assert.Equal(t, int64(1234), event.PrivateNetwork.NetworkID)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.5")}, event.AddedAliasIPs)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.4")}, event.RemovedAliasIPs)
+
+	server.Update(func(metadata *fakemetadata.Metadata) {
+		metadata.Hostname = "renamed"
+		metadata.PrivateNetworks = append(metadata.PrivateNetworks, fakemetadata.PrivateNetwork{
+			IP:        "192.168.0.2",
+			NetworkID: 4321,
+			Network:   "192.168.0.0/16",
+			Subnet:    "192.168.0.0/24",
+		})
+	})
+
+	event = receiveEvent(t, w)
+	assert.Equal(t, ChangeTypeHostname, event.Type)
+	assert.Equal(t, "my-server", event.Previous.Hostname)
+	assert.Equal(t, "renamed", event.Current.Hostname)
+
+	event = receiveEvent(t, w)
+	assert.Equal(t, ChangeTypePrivateNetworkAttached, event.Type)
+	assert.Equal(t, int64(4321), event.PrivateNetwork.NetworkID)
+
+	server.SetUnavailable(true)
+	require.Eventually(t, func() bool { return !w.Healthy() }, 5*time.Second, time.Millisecond)
+	require.EqualError(t, w.Err(), "could not fetch metadata: response status was 503\nresponse status was 503\nresponse status was 503")
+	assert.Equal(t, "renamed", w.Snapshot().Hostname)
+
+	server.Update(func(metadata *fakemetadata.Metadata) {
+		metadata.PublicIPv4 = "203.0.113.2"
+	})
+	server.SetUnavailable(false)
+
+	event = receiveEvent(t, w)
+	assert.Equal(t, ChangeTypePublicIPv4, event.Type)
+	assert.Equal(t, "203.0.113.2", event.Current.PublicIPv4.String())
+	assert.True(t, w.Healthy())
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-w.Events()
+		return !ok
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	previous := &WatchSnapshot{
+		Hostname:   "my-server",
+		PublicIPv4: net.ParseIP("203.0.113.1"),
+		PrivateNetworks: []PrivateNetwork{
+			{NetworkID: 1, IP: net.ParseIP("10.0.0.2")},
+			{NetworkID: 2, IP: net.ParseIP("10.1.0.2")},
+		},
+	}
+
+	assert.Empty(t, diffSnapshots(previous, previous))
+
+	current := &WatchSnapshot{
+		Hostname:   "my-server",
+		PublicIPv4: net.ParseIP("203.0.113.1"),
+		PrivateNetworks: []PrivateNetwork{
+			{NetworkID: 1, IP: net.ParseIP("10.0.0.3")},
+		},
+	}
+
+	events := diffSnapshots(previous, current)
+	require.Len(t, events, 3)
+	assert.Equal(t, ChangeTypePrivateNetworkDetached, events[0].Type)
+	assert.Equal(t, "10.0.0.2", events[0].PrivateNetwork.IP.String())
+	assert.Equal(t, ChangeTypePrivateNetworkDetached, events[1].Type)
+	assert.Equal(t, int64(2), events[1].PrivateNetwork.NetworkID)
+	assert.Equal(t, ChangeTypePrivateNetworkAttached, events[2].Type)
+	assert.Equal(t, "10.0.0.3", events[2].PrivateNetwork.IP.String())
+}
+
+func TestWatcherBackoff(t *testing.T) {
+	w := &Watcher{interval: time.Second}
+	assert.Equal(t, 2*time.Second, w.backoff(1))
+	assert.Equal(t, 8*time.Second, w.backoff(3))
+	assert.Equal(t, time.Minute, w.backoff(10))
+	assert.Equal(t, time.Minute, w.backoff(1000))
+
+	w = &Watcher{interval: 5 * time.Minute}
+	assert.Equal(t, 5*time.Minute, w.backoff(1))
+}
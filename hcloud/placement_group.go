@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"time"
 
@@ -120,6 +121,20 @@ func (c *PlacementGroupClient) AllWithOpts(ctx context.Context, opts PlacementGr
 	})
 }
 
+// Iter returns an iterator over all PlacementGroups for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *PlacementGroupClient) Iter(ctx context.Context, opts PlacementGroupListOpts) iter.Seq2[*PlacementGroup, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*PlacementGroup, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // PlacementGroupCreateOpts specifies options for creating a new PlacementGroup.
 type PlacementGroupCreateOpts struct {
 	Name   string
@@ -0,0 +1,133 @@
+package hcloud
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles the requests sent to the API based on the rate limit headers
+// observed in the previous responses.
+//
+// The API allows a number of requests per project (RateLimit-Limit), and refills the
+// remaining requests (RateLimit-Remaining) over time until they are back at the limit
+// at the RateLimit-Reset time. The RateLimiter implements a token bucket that mirrors
+// this budget: every request consumes a token, and the bucket is refilled at the rate
+// derived from the last observed headers. When the budget is exhausted, requests are
+// blocked until a new token is available, at the latest until RateLimit-Reset.
+//
+// Until the first response is received, requests are not throttled.
+//
+// A RateLimiter is safe for concurrent use, and may be shared by multiple [Client]
+// using the same token, as the rate limit applies to the project of the token. Sharing
+// a RateLimiter between clients using different projects is not supported.
+//
+// A RateLimiter must be created using the [NewRateLimiter] function.
+type RateLimiter struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	known   bool
+	limit   float64
+	tokens  float64
+	rate    float64 // tokens per second
+	reset   time.Time
+	updated time.Time
+}
+
+// NewRateLimiter creates a new [RateLimiter].
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{now: time.Now}
+}
+
+// Wait blocks until a request may be sent to the API, or the context is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		delay := l.reserve()
+		l.mu.Unlock()
+
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Ratelimit returns the current state of the [RateLimiter], with the remaining
+// requests estimated from the last observed headers.
+func (l *RateLimiter) Ratelimit() Ratelimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.known {
+		return Ratelimit{}
+	}
+	l.refill(l.now())
+
+	return Ratelimit{
+		Limit:     int(l.limit),
+		Remaining: int(l.tokens),
+		Reset:     l.reset,
+	}
+}
+
+// reserve consumes a token if one is available, otherwise it returns the duration to
+// wait before trying again.
+func (l *RateLimiter) reserve() time.Duration {
+	if !l.known {
+		return 0
+	}
+
+	now := l.now()
+	l.refill(now)
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	untilReset := l.reset.Sub(now)
+	if l.rate <= 0 {
+		return untilReset
+	}
+
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	return min(delay, untilReset)
+}
+
+// refill adds the tokens accumulated since the last update.
+func (l *RateLimiter) refill(now time.Time) {
+	if !now.Before(l.reset) {
+		l.tokens = l.limit
+	} else if elapsed := now.Sub(l.updated); elapsed > 0 {
+		l.tokens = min(l.limit, l.tokens+elapsed.Seconds()*l.rate)
+	}
+	l.updated = now
+}
+
+// update sets the budget from the rate limit headers of a response.
+func (l *RateLimiter) update(ratelimit Ratelimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	l.known = true
+	l.limit = float64(ratelimit.Limit)
+	l.tokens = float64(ratelimit.Remaining)
+	l.reset = ratelimit.Reset
+	l.updated = now
+
+	l.rate = 0
+	if untilReset := ratelimit.Reset.Sub(now); untilReset > 0 && ratelimit.Remaining < ratelimit.Limit {
+		l.rate = float64(ratelimit.Limit-ratelimit.Remaining) / untilReset.Seconds()
+	}
+}
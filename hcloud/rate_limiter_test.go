@@ -0,0 +1,92 @@
+package hcloud
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// Requests are not throttled until the budget is known.
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+
+	// 10 requests are refilled in 10 seconds, 1 request per second.
+	limiter.update(Ratelimit{Limit: 20, Remaining: 10, Reset: now.Add(10 * time.Second)})
+
+	for range 10 {
+		assert.Equal(t, time.Duration(0), limiter.reserve())
+	}
+	assert.Equal(t, time.Second, limiter.reserve())
+	assert.Equal(t, 0, limiter.Ratelimit().Remaining)
+
+	now = now.Add(2500 * time.Millisecond)
+	assert.Equal(t, 2, limiter.Ratelimit().Remaining)
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, 500*time.Millisecond, limiter.reserve())
+
+	// The budget is full once the reset time is reached.
+	now = now.Add(time.Hour)
+	assert.Equal(t, 20, limiter.Ratelimit().Remaining)
+}
+
+func TestRateLimiterReserveUntilReset(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// No refill rate can be derived, requests are blocked until the reset time.
+	limiter.update(Ratelimit{Limit: 0, Remaining: 0, Reset: now.Add(3 * time.Second)})
+	assert.Equal(t, 3*time.Second, limiter.reserve())
+
+	now = now.Add(3 * time.Second)
+	limiter.update(Ratelimit{Limit: 1, Remaining: 1, Reset: now})
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+}
+
+func TestRateLimiterSharedClients(t *testing.T) {
+	var remaining atomic.Int64
+	remaining.Store(2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		value := max(0, remaining.Add(-1))
+		w.Header().Set("RateLimit-Limit", "3600")
+		w.Header().Set("RateLimit-Remaining", strconv.FormatInt(value, 10))
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"server": {"id": 1}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	limiter := NewRateLimiter()
+	client1 := NewClient(WithEndpoint(server.URL), WithRateLimiter(limiter), WithRetryOpts(RetryOpts{MaxRetries: 0}))
+	client2 := NewClient(WithEndpoint(server.URL), WithRateLimiter(limiter), WithRetryOpts(RetryOpts{MaxRetries: 0}))
+
+	ctx := context.Background()
+
+	_, _, err := client1.Server.GetByID(ctx, 1)
+	require.NoError(t, err)
+	_, _, err = client2.Server.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	// The budget observed by the second client is exhausted for both clients.
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = client1.Server.GetByID(ctx, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(0), remaining.Load())
+}
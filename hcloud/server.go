@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net"
 	"net/url"
 	"slices"
@@ -300,6 +301,20 @@ func (c *ServerClient) AllWithOpts(ctx context.Context, opts ServerListOpts) ([]
 	})
 }
 
+// Iter returns an iterator over all servers for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *ServerClient) Iter(ctx context.Context, opts ServerListOpts) iter.Seq2[*Server, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*Server, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // ServerCreateOpts specifies options for creating a new server.
 type ServerCreateOpts struct {
 	Name             string
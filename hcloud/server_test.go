@@ -535,6 +535,59 @@ func TestServersAllWithOpts(t *testing.T) {
 	}
 }
 
+func TestServersIter(t *testing.T) {
+	RunMockedTestCases(t,
+		[]MockedTestCase{
+			{
+				Name: "succeed",
+				WantRequests: []mockutil.Request{
+					{Method: "GET", Path: "/servers?label_selector=key%3Dvalue&page=1&per_page=2",
+						Status: 200,
+						JSONRaw: `{
+							"servers": [{ "id": 1 }, { "id": 2 }],
+							"meta": { "pagination": { "page": 1, "next_page": 2 }}
+						}`,
+					},
+					{Method: "GET", Path: "/servers?label_selector=key%3Dvalue&page=2&per_page=2",
+						Status: 200,
+						JSONRaw: `{
+							"servers": [{ "id": 3 }],
+							"meta": { "pagination": { "page": 2 }}
+						}`,
+					},
+				},
+				Run: func(env testEnv) {
+					ids := []int64{}
+					for server, err := range env.Client.Server.Iter(context.Background(), ServerListOpts{ListOpts: ListOpts{LabelSelector: "key=value", PerPage: 2}}) {
+						require.NoError(t, err)
+						ids = append(ids, server.ID)
+					}
+					assert.Equal(t, []int64{1, 2, 3}, ids)
+				},
+			},
+			{
+				Name: "stop early",
+				WantRequests: []mockutil.Request{
+					{Method: "GET", Path: "/servers?page=1&per_page=2",
+						Status: 200,
+						JSONRaw: `{
+							"servers": [{ "id": 1 }, { "id": 2 }],
+							"meta": { "pagination": { "page": 1, "next_page": 2 }}
+						}`,
+					},
+				},
+				Run: func(env testEnv) {
+					for server, err := range env.Client.Server.Iter(context.Background(), ServerListOpts{ListOpts: ListOpts{PerPage: 2}}) {
+						require.NoError(t, err)
+						assert.Equal(t, int64(1), server.ID)
+						break
+					}
+				},
+			},
+		},
+	)
+}
+
 func TestServersCreateWithSSHKeys(t *testing.T) {
 	env := newTestEnv()
 	defer env.Teardown()
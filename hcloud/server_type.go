@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 
@@ -154,3 +155,17 @@ func (c *ServerTypeClient) AllWithOpts(ctx context.Context, opts ServerTypeListO
 		return c.List(ctx, opts)
 	})
 }
+
+// Iter returns an iterator over all server types for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *ServerTypeClient) Iter(ctx context.Context, opts ServerTypeListOpts) iter.Seq2[*ServerType, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*ServerType, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
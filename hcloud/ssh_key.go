@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"time"
 
@@ -119,6 +120,20 @@ func (c *SSHKeyClient) AllWithOpts(ctx context.Context, opts SSHKeyListOpts) ([]
 	})
 }
 
+// Iter returns an iterator over all SSH keys with the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *SSHKeyClient) Iter(ctx context.Context, opts SSHKeyListOpts) iter.Seq2[*SSHKey, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*SSHKey, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // SSHKeyCreateOpts specifies parameters for creating a SSH key.
 type SSHKeyCreateOpts struct {
 	Name      string
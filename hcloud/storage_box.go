@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 	"time"
@@ -192,6 +193,22 @@ func (c *StorageBoxClient) AllWithOpts(ctx context.Context, opts StorageBoxListO
 	})
 }
 
+// Iter returns an iterator over all [StorageBox] with the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+//
+// See https://docs.hetzner.cloud/reference/hetzner#storage-boxes-list-storage-boxes
+func (c *StorageBoxClient) Iter(ctx context.Context, opts StorageBoxListOpts) iter.Seq2[*StorageBox, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*StorageBox, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // StorageBoxCreateOpts specifies parameters for creating a [StorageBox].
 type StorageBoxCreateOpts struct {
 	Name           string
@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 
 	"github.com/hetznercloud/hcloud-go/v2/hcloud/exp/ctxutil"
@@ -89,6 +90,22 @@ func (c *StorageBoxTypeClient) AllWithOpts(ctx context.Context, opts StorageBoxT
 	})
 }
 
+// Iter returns an iterator over all storage box types for the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+//
+// See https://docs.hetzner.cloud/reference/hetzner#storage-box-types-list-storage-box-types
+func (c *StorageBoxTypeClient) Iter(ctx context.Context, opts StorageBoxTypeListOpts) iter.Seq2[*StorageBoxType, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*StorageBoxType, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // GetByID returns a specific Storage Box Type by ID.
 //
 // See https://docs.hetzner.cloud/reference/hetzner#storage-box-types-get-a-storage-box-type
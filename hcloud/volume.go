@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 	"time"
@@ -147,6 +148,20 @@ func (c *VolumeClient) AllWithOpts(ctx context.Context, opts VolumeListOpts) ([]
 	})
 }
 
+// Iter returns an iterator over all volumes with the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+func (c *VolumeClient) Iter(ctx context.Context, opts VolumeListOpts) iter.Seq2[*Volume, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*Volume, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // VolumeCreateOpts specifies parameters for creating a volume.
 type VolumeCreateOpts struct {
 	Name      string
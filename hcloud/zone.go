@@ -3,6 +3,7 @@ package hcloud
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 	"time"
@@ -217,6 +218,22 @@ func (c *ZoneClient) AllWithOpts(ctx context.Context, opts ZoneListOpts) ([]*Zon
 	})
 }
 
+// Iter returns an iterator over all [Zone] with the given options.
+//
+// The pages are fetched on demand while iterating, the iteration stops after
+// yielding an error.
+//
+// See https://docs.hetzner.cloud/reference/cloud#zones-list-zones
+func (c *ZoneClient) Iter(ctx context.Context, opts ZoneListOpts) iter.Seq2[*Zone, error] {
+	if opts.ListOpts.PerPage == 0 {
+		opts.ListOpts.PerPage = 50
+	}
+	return seqPages(func(page int) ([]*Zone, *Response, error) {
+		opts.Page = page
+		return c.List(ctx, opts)
+	})
+}
+
 // ZoneCreateOpts defines options for creating a [Zone].
 type ZoneCreateOpts struct {
 	Name   string
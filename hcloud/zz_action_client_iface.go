@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IActionClient ...
@@ -23,6 +24,13 @@ type IActionClient interface {
 	//
 	// It is required to set [ActionListOpts.ID]. Any other fields set in the opts are ignored.
 	AllWithOpts(ctx context.Context, opts ActionListOpts) ([]*Action, error)
+	// Iter returns an iterator over all actions for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	//
+	// It is required to set [ActionListOpts.ID]. Any other fields set in the opts are ignored.
+	Iter(ctx context.Context, opts ActionListOpts) iter.Seq2[*Action, error]
 	// WatchOverallProgress watches several actions' progress until they complete
 	// with success or error. This watching happens in a goroutine and updates are
 	// provided through the two returned channels:
@@ -65,11 +73,16 @@ type IActionClient interface {
 	//
 	// Deprecated: WatchProgress is deprecated, use [WaitForFunc] instead.
 	WatchProgress(ctx context.Context, action *Action) (<-chan int, <-chan error)
+	// Watcher returns the [ActionWatcher] shared by all users of the [Client].
+	Watcher() *ActionWatcher
 	// WaitForFunc waits until all actions are completed by polling the API at the interval
 	// defined by [WithPollOpts]. An action is considered as complete when its status is
 	// either [ActionStatusSuccess] or [ActionStatusError].
 	//
 	// The handleUpdate callback is called every time an action is updated.
+	//
+	// Every call runs its own polling loop, see [ActionClient.Watcher] to share a single
+	// polling loop between concurrent callers.
 	WaitForFunc(ctx context.Context, handleUpdate func(update *Action) error, actions ...*Action) error
 	// WaitFor waits until all actions succeed by polling the API at the interval defined by
 	// [WithPollOpts]. An action is considered as succeeded when its status is either
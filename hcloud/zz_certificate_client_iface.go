@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // ICertificateClient ...
@@ -24,6 +25,11 @@ type ICertificateClient interface {
 	All(ctx context.Context) ([]*Certificate, error)
 	// AllWithOpts returns all Certificates for the given options.
 	AllWithOpts(ctx context.Context, opts CertificateListOpts) ([]*Certificate, error)
+	// Iter returns an iterator over all Certificates for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts CertificateListOpts) iter.Seq2[*Certificate, error]
 	// Create creates a new uploaded certificate.
 	//
 	// Create returns an error for certificates of any other type. Use
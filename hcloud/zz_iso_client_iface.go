@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IISOClient ...
@@ -23,4 +24,9 @@ type IISOClient interface {
 	All(ctx context.Context) ([]*ISO, error)
 	// AllWithOpts returns all ISOs for the given options.
 	AllWithOpts(ctx context.Context, opts ISOListOpts) ([]*ISO, error)
+	// Iter returns an iterator over all ISOs for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts ISOListOpts) iter.Seq2[*ISO, error]
 }
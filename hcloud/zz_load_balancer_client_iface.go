@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 	"net"
 )
 
@@ -25,6 +26,11 @@ type ILoadBalancerClient interface {
 	All(ctx context.Context) ([]*LoadBalancer, error)
 	// AllWithOpts returns all Load Balancers for the given options.
 	AllWithOpts(ctx context.Context, opts LoadBalancerListOpts) ([]*LoadBalancer, error)
+	// Iter returns an iterator over all Load Balancers for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts LoadBalancerListOpts) iter.Seq2[*LoadBalancer, error]
 	// Update updates a Load Balancer.
 	Update(ctx context.Context, loadBalancer *LoadBalancer, opts LoadBalancerUpdateOpts) (*LoadBalancer, *Response, error)
 	// Create creates a new Load Balancer.
@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // ILoadBalancerTypeClient ...
@@ -24,4 +25,9 @@ type ILoadBalancerTypeClient interface {
 	All(ctx context.Context) ([]*LoadBalancerType, error)
 	// AllWithOpts returns all Load Balancer types for the given options.
 	AllWithOpts(ctx context.Context, opts LoadBalancerTypeListOpts) ([]*LoadBalancerType, error)
+	// Iter returns an iterator over all Load Balancer types for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts LoadBalancerTypeListOpts) iter.Seq2[*LoadBalancerType, error]
 }
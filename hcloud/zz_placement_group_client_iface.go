@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IPlacementGroupClient ...
@@ -24,6 +25,11 @@ type IPlacementGroupClient interface {
 	All(ctx context.Context) ([]*PlacementGroup, error)
 	// AllWithOpts returns all PlacementGroups for the given options.
 	AllWithOpts(ctx context.Context, opts PlacementGroupListOpts) ([]*PlacementGroup, error)
+	// Iter returns an iterator over all PlacementGroups for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts PlacementGroupListOpts) iter.Seq2[*PlacementGroup, error]
 	// Create creates a new PlacementGroup.
 	Create(ctx context.Context, opts PlacementGroupCreateOpts) (PlacementGroupCreateResult, *Response, error)
 	// Update updates a PlacementGroup.
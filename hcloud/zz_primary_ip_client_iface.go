@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IPrimaryIPClient ...
@@ -26,6 +27,11 @@ type IPrimaryIPClient interface {
 	All(ctx context.Context) ([]*PrimaryIP, error)
 	// AllWithOpts returns all Primary IPs for the given options.
 	AllWithOpts(ctx context.Context, opts PrimaryIPListOpts) ([]*PrimaryIP, error)
+	// Iter returns an iterator over all Primary IPs for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts PrimaryIPListOpts) iter.Seq2[*PrimaryIP, error]
 	// Create creates a Primary IP.
 	Create(ctx context.Context, opts PrimaryIPCreateOpts) (*PrimaryIPCreateResult, *Response, error)
 	// Delete deletes a Primary IP.
@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IResourceActionClient ...
@@ -17,6 +18,11 @@ type IResourceActionClient[R actionSupporter] interface {
 	List(ctx context.Context, opts ActionListOpts) ([]*Action, *Response, error)
 	// All returns all actions for the given options.
 	All(ctx context.Context, opts ActionListOpts) ([]*Action, error)
+	// Iter returns an iterator over all actions for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts ActionListOpts) iter.Seq2[*Action, error]
 	// ListFor returns a paginated list of actions for the given Resource.
 	//
 	// Please note that filters specified in opts are not taken into account
@@ -24,4 +30,9 @@ type IResourceActionClient[R actionSupporter] interface {
 	ListFor(ctx context.Context, resource R, opts ActionListOpts) ([]*Action, *Response, error)
 	// AllFor returns all actions for the given Resource.
 	AllFor(ctx context.Context, resource R, opts ActionListOpts) ([]*Action, error)
+	// IterFor returns an iterator over all actions for the given Resource.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	IterFor(ctx context.Context, resource R, opts ActionListOpts) iter.Seq2[*Action, error]
 }
@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IServerClient ...
@@ -24,6 +25,11 @@ type IServerClient interface {
 	All(ctx context.Context) ([]*Server, error)
 	// AllWithOpts returns all servers for the given options.
 	AllWithOpts(ctx context.Context, opts ServerListOpts) ([]*Server, error)
+	// Iter returns an iterator over all servers for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts ServerListOpts) iter.Seq2[*Server, error]
 	// Create creates a new server.
 	Create(ctx context.Context, opts ServerCreateOpts) (ServerCreateResult, *Response, error)
 	// Delete deletes a server.
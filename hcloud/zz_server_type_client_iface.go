@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IServerTypeClient ...
@@ -24,4 +25,9 @@ type IServerTypeClient interface {
 	All(ctx context.Context) ([]*ServerType, error)
 	// AllWithOpts returns all server types for the given options.
 	AllWithOpts(ctx context.Context, opts ServerTypeListOpts) ([]*ServerType, error)
+	// Iter returns an iterator over all server types for the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts ServerTypeListOpts) iter.Seq2[*ServerType, error]
 }
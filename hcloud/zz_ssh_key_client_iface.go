@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // ISSHKeyClient ...
@@ -26,6 +27,11 @@ type ISSHKeyClient interface {
 	All(ctx context.Context) ([]*SSHKey, error)
 	// AllWithOpts returns all SSH keys with the given options.
 	AllWithOpts(ctx context.Context, opts SSHKeyListOpts) ([]*SSHKey, error)
+	// Iter returns an iterator over all SSH keys with the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	Iter(ctx context.Context, opts SSHKeyListOpts) iter.Seq2[*SSHKey, error]
 	// Create creates a new SSH key with the given options.
 	Create(ctx context.Context, opts SSHKeyCreateOpts) (*SSHKey, *Response, error)
 	// Delete deletes a SSH key.
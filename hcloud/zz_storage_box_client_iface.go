@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IStorageBoxClient ...
@@ -37,6 +38,13 @@ type IStorageBoxClient interface {
 	//
 	// See https://docs.hetzner.cloud/reference/hetzner#storage-boxes-list-storage-boxes
 	AllWithOpts(ctx context.Context, opts StorageBoxListOpts) ([]*StorageBox, error)
+	// Iter returns an iterator over all [StorageBox] with the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	//
+	// See https://docs.hetzner.cloud/reference/hetzner#storage-boxes-list-storage-boxes
+	Iter(ctx context.Context, opts StorageBoxListOpts) iter.Seq2[*StorageBox, error]
 	// Create creates a new [StorageBox] with the given options.
 	//
 	// To provide SSH keys, populate the PublicKey field for each [SSHKey]
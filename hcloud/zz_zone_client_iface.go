@@ -4,6 +4,7 @@ package hcloud
 
 import (
 	"context"
+	"iter"
 )
 
 // IZoneClient ...
@@ -32,6 +33,13 @@ type IZoneClient interface {
 	//
 	// See https://docs.hetzner.cloud/reference/cloud#zones-list-zones
 	AllWithOpts(ctx context.Context, opts ZoneListOpts) ([]*Zone, error)
+	// Iter returns an iterator over all [Zone] with the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	//
+	// See https://docs.hetzner.cloud/reference/cloud#zones-list-zones
+	Iter(ctx context.Context, opts ZoneListOpts) iter.Seq2[*Zone, error]
 	// Create creates a new [Zone] from the given options.
 	//
 	// See https://docs.hetzner.cloud/reference/cloud#zones-create-a-zone
@@ -80,6 +88,13 @@ type IZoneClient interface {
 	//
 	// See https://docs.hetzner.cloud/reference/cloud#zone-rrsets-list-rrsets
 	AllRRSetsWithOpts(ctx context.Context, zone *Zone, opts ZoneRRSetListOpts) ([]*ZoneRRSet, error)
+	// IterRRSets returns an iterator over all [ZoneRRSet] with the given options.
+	//
+	// The pages are fetched on demand while iterating, the iteration stops after
+	// yielding an error.
+	//
+	// See https://docs.hetzner.cloud/reference/cloud#zone-rrsets-list-rrsets
+	IterRRSets(ctx context.Context, zone *Zone, opts ZoneRRSetListOpts) iter.Seq2[*ZoneRRSet, error]
 	// AllRRSets returns a list of all [ZoneRRSet].
 	//
 	// See https://docs.hetzner.cloud/reference/cloud#zone-rrsets-list-rrsets